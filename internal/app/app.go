@@ -3,6 +3,8 @@ package app
 import (
 	"github.com/AndreyNiki/grpc-highloader/internal/gui"
 	"github.com/AndreyNiki/grpc-highloader/internal/loader"
+	"github.com/AndreyNiki/grpc-highloader/internal/otel"
+	"github.com/AndreyNiki/grpc-highloader/internal/prometheus"
 	"github.com/AndreyNiki/grpc-highloader/internal/proto"
 )
 
@@ -22,8 +24,12 @@ func NewApp() *App {
 // Run start application.
 func (app *App) Run() {
 	requesterFactory := proto.NewRequesterFactory()
-	loaderFactory := loader.NewLoaderFactory(requesterFactory)
+	telemetry := otel.NewExporter()
+	loaderFactory := loader.NewLoaderFactory(requesterFactory).WithTracer(telemetry)
 	parser := proto.NewProtoParser()
-	ui := gui.NewGUI(width, height, loaderFactory, parser)
+	watcher := proto.NewProtoWatcher(parser)
+	validator := proto.NewRequestValidator(parser)
+	exporter := prometheus.NewExporter()
+	ui := gui.NewGUI(width, height, loaderFactory, parser, watcher, validator, exporter, telemetry)
 	ui.Run()
 }
@@ -0,0 +1,94 @@
+package capture
+
+import (
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/AndreyNiki/grpc-highloader/internal/entity"
+)
+
+// Record captured request attempt, one line of JSONL file.
+type Record struct {
+	Time    time.Time `json:"time"`
+	Method  string    `json:"method"`
+	Outcome string    `json:"outcome"`
+	Code    string    `json:"code"`
+	Message string    `json:"message,omitempty"`
+	// LatencyMs latency of response in milliseconds, zero if request was not sent.
+	LatencyMs float64             `json:"latency_ms"`
+	Request   json.RawMessage     `json:"request,omitempty"`
+	Response  json.RawMessage     `json:"response,omitempty"`
+	Header    map[string][]string `json:"header,omitempty"`
+	Trailer   map[string][]string `json:"trailer,omitempty"`
+}
+
+// Sink write sampled request attempts to file in JSONL format. Nil Sink captures nothing.
+type Sink struct {
+	sampleRate  float64
+	firstErrors int64
+	errors      atomic.Int64
+	// mu guards writes of records, so lines of concurrent requests are not mixed.
+	mu   sync.Mutex
+	file *os.File
+	enc  *json.Encoder
+}
+
+// NewSink create a new Sink, nil is returned for nil params.
+func NewSink(params *entity.CaptureParams) (*Sink, error) {
+	if params == nil {
+		return nil, nil
+	}
+	if params.Path == "" {
+		return nil, errors.New("path of capture file is empty")
+	}
+	if params.SampleRate < 0 || params.SampleRate > 1 {
+		return nil, errors.New("sample rate of capture must be from 0 to 1")
+	}
+
+	file, err := os.OpenFile(params.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Sink{
+		sampleRate:  params.SampleRate,
+		firstErrors: int64(max(params.FirstErrors, 0)),
+		file:        file,
+		enc:         json.NewEncoder(file),
+	}, nil
+}
+
+// Sample report whether attempt should be captured, the first failed attempts are always captured.
+func (s *Sink) Sample(failed bool) bool {
+	if s == nil {
+		return false
+	}
+	if failed && s.errors.Load() < s.firstErrors && s.errors.Add(1) <= s.firstErrors {
+		return true
+	}
+
+	return s.sampleRate > 0 && rand.Float64() < s.sampleRate
+}
+
+// Write record as line of file.
+func (s *Sink) Write(r *Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.enc.Encode(r)
+}
+
+// Close file of sink.
+func (s *Sink) Close() error {
+	if s == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
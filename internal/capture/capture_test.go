@@ -0,0 +1,83 @@
+package capture
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/AndreyNiki/grpc-highloader/internal/entity"
+)
+
+func TestNewSink(t *testing.T) {
+	t.Run("Test nil params", func(t *testing.T) {
+		s, err := NewSink(nil)
+		assert.NoError(t, err)
+		assert.Nil(t, s)
+	})
+
+	t.Run("Test invalid params", func(t *testing.T) {
+		_, err := NewSink(&entity.CaptureParams{})
+		assert.Error(t, err)
+		_, err = NewSink(&entity.CaptureParams{Path: filepath.Join(t.TempDir(), "capture.jsonl"), SampleRate: 2})
+		assert.Error(t, err)
+	})
+}
+
+func TestSink_Sample(t *testing.T) {
+	t.Run("Test nil sink", func(t *testing.T) {
+		var s *Sink
+		assert.False(t, s.Sample(true), "nil sink captures nothing")
+	})
+
+	t.Run("Test first errors", func(t *testing.T) {
+		s, err := NewSink(&entity.CaptureParams{Path: filepath.Join(t.TempDir(), "capture.jsonl"), FirstErrors: 2})
+		require.NoError(t, err)
+		defer s.Close()
+		assert.False(t, s.Sample(false))
+		assert.True(t, s.Sample(true))
+		assert.True(t, s.Sample(true))
+		assert.False(t, s.Sample(true), "only the first errors are captured without sample rate")
+	})
+
+	t.Run("Test sample rate", func(t *testing.T) {
+		s, err := NewSink(&entity.CaptureParams{Path: filepath.Join(t.TempDir(), "capture.jsonl"), SampleRate: 1})
+		require.NoError(t, err)
+		defer s.Close()
+		assert.True(t, s.Sample(false))
+		assert.True(t, s.Sample(true))
+	})
+}
+
+func TestSink_Write(t *testing.T) {
+	t.Run("Test records as JSON lines", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "capture.jsonl")
+		s, err := NewSink(&entity.CaptureParams{Path: path})
+		require.NoError(t, err)
+		require.NoError(t, s.Write(&Record{Method: "example.v1.UserService.Get", Code: "OK",
+			Request: json.RawMessage(`{"id":"1"}`)}))
+		require.NoError(t, s.Write(&Record{Method: "example.v1.UserService.Get", Code: "NotFound",
+			Header: map[string][]string{"x-request-id": {"2"}}}))
+		require.NoError(t, s.Close())
+
+		f, err := os.Open(path)
+		require.NoError(t, err)
+		defer f.Close()
+		var records []Record
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			var r Record
+			require.NoError(t, json.Unmarshal(scanner.Bytes(), &r))
+			records = append(records, r)
+		}
+		require.Len(t, records, 2)
+		assert.JSONEq(t, `{"id":"1"}`, string(records[0].Request))
+		assert.Nil(t, records[0].Response)
+		assert.Equal(t, "NotFound", records[1].Code)
+		assert.Equal(t, []string{"2"}, records[1].Header["x-request-id"])
+	})
+}
@@ -0,0 +1,184 @@
+package export
+
+import (
+	"cmp"
+	"encoding/csv"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+)
+
+// latencyColumns names of columns of latency stats.
+var latencyColumns = []string{"count", "min_ms", "mean_ms", "p50_ms", "p90_ms", "p95_ms", "p99_ms", "p999_ms", "max_ms"}
+
+// sizeColumns names of columns of size stats.
+var sizeColumns = []string{"count", "min", "mean", "p50", "p90", "p99", "max"}
+
+// writeCSV write report as tables separated by empty line: summary with key and value columns,
+// time series with column per status code and top errors if there are errors.
+func writeCSV(w io.Writer, report *Report) error {
+	cw := csv.NewWriter(w)
+	s := report.Summary
+
+	rows := [][]string{
+		{"key", "value"},
+		{"host", s.Config.Host},
+		{"service", s.Config.Service},
+		{"method", s.Config.Method},
+		{"transport", s.Config.Transport},
+		{"rps", strconv.Itoa(s.Config.RPS)},
+		{"request_deadline_ms", formatFloat(s.Config.RequestDeadlineMs)},
+		{"message", s.Config.Message},
+		{"started", s.Started.Format(time.RFC3339Nano)},
+		{"finished", s.Finished.Format(time.RFC3339Nano)},
+		{"duration_s", formatFloat(s.DurationSeconds)},
+		{"requests", strconv.FormatInt(s.Requests, 10)},
+	}
+	for _, name := range slices.Sorted(maps.Keys(s.Outcomes)) {
+		rows = append(rows, []string{"outcome_" + name, strconv.FormatInt(s.Outcomes[name], 10)})
+	}
+	for _, name := range sortStatuses(s.Statuses) {
+		rows = append(rows, []string{"status_" + name, strconv.FormatInt(s.Statuses[name], 10)})
+	}
+	rows = append(rows,
+		[]string{"sent_bytes", strconv.FormatInt(s.Payload.SentBytes, 10)},
+		[]string{"received_bytes", strconv.FormatInt(s.Payload.ReceivedBytes, 10)},
+		[]string{"wire_sent_bytes", strconv.FormatInt(s.Payload.WireSentBytes, 10)},
+		[]string{"wire_received_bytes", strconv.FormatInt(s.Payload.WireReceivedBytes, 10)},
+		[]string{"sent_mb_s", formatFloat(s.Payload.SentMBps)},
+		[]string{"received_mb_s", formatFloat(s.Payload.ReceivedMBps)},
+	)
+	if s.Assertions != nil {
+		rows = append(rows, []string{"assertions_passed", strconv.FormatInt(s.Assertions.Passed, 10)})
+		for _, f := range s.Assertions.Failed {
+			rows = append(rows, []string{"assertion_failed " + f.Assertion, strconv.FormatInt(f.Count, 10)})
+		}
+	}
+	rows = appendSizeRows(rows, "request_size_", s.Payload.RequestSize)
+	rows = appendSizeRows(rows, "response_size_", s.Payload.ResponseSize)
+	rows = appendLatencyRows(rows, "latency_", s.Latency)
+	for _, name := range sortStatuses(s.LatencyByStatus) {
+		rows = appendLatencyRows(rows, "latency_"+name+"_", s.LatencyByStatus[name])
+	}
+	rows = append(rows, nil)
+
+	outcomes := map[string]struct{}{}
+	statuses := map[string]struct{}{}
+	for _, p := range report.Series {
+		for name := range p.Outcomes {
+			outcomes[name] = struct{}{}
+		}
+		for name := range p.Statuses {
+			statuses[name] = struct{}{}
+		}
+	}
+	outcomeColumns := slices.Sorted(maps.Keys(outcomes))
+	statusColumns := sortStatuses(statuses)
+	header := []string{"time", "elapsed_s", "rps", "target_rps", "in_flight", "sent_bytes", "received_bytes"}
+	for _, name := range outcomeColumns {
+		header = append(header, "outcome_"+name)
+	}
+	for _, name := range statusColumns {
+		header = append(header, "status_"+name)
+	}
+	for _, column := range latencyColumns {
+		header = append(header, "latency_"+column)
+	}
+	rows = append(rows, header)
+	for _, p := range report.Series {
+		row := []string{
+			p.Time.Format(time.RFC3339Nano),
+			formatFloat(p.ElapsedSeconds),
+			strconv.FormatInt(p.RPS, 10),
+			strconv.FormatInt(p.TargetRPS, 10),
+			strconv.FormatInt(p.InFlight, 10),
+			strconv.FormatInt(p.SentBytes, 10),
+			strconv.FormatInt(p.ReceivedBytes, 10),
+		}
+		for _, name := range outcomeColumns {
+			row = append(row, strconv.FormatInt(p.Outcomes[name], 10))
+		}
+		for _, name := range statusColumns {
+			row = append(row, strconv.FormatInt(p.Statuses[name], 10))
+		}
+		rows = append(rows, append(row, latencyValues(p.Latency)...))
+	}
+
+	if len(s.TopErrors) != 0 {
+		rows = append(rows, nil, []string{"count", "code", "message", "detail"})
+		for _, e := range s.TopErrors {
+			rows = append(rows, []string{strconv.FormatInt(e.Count, 10), e.Code, e.Message, e.Detail})
+		}
+	}
+
+	if err := cw.WriteAll(rows); err != nil {
+		return err
+	}
+
+	return cw.Error()
+}
+
+// appendLatencyRows append rows of latency stats with prefix of keys.
+func appendLatencyRows(rows [][]string, prefix string, l Latency) [][]string {
+	for i, value := range latencyValues(l) {
+		rows = append(rows, []string{prefix + latencyColumns[i], value})
+	}
+
+	return rows
+}
+
+// appendSizeRows append rows of size stats with prefix of keys.
+func appendSizeRows(rows [][]string, prefix string, s Size) [][]string {
+	for i, value := range []int64{s.Count, s.Min, s.Mean, s.P50, s.P90, s.P99, s.Max} {
+		rows = append(rows, []string{prefix + sizeColumns[i], strconv.FormatInt(value, 10)})
+	}
+
+	return rows
+}
+
+// latencyValues return values of latency stats in order of latencyColumns.
+func latencyValues(l Latency) []string {
+	return []string{
+		strconv.FormatInt(l.Count, 10),
+		formatFloat(l.MinMs),
+		formatFloat(l.MeanMs),
+		formatFloat(l.P50Ms),
+		formatFloat(l.P90Ms),
+		formatFloat(l.P95Ms),
+		formatFloat(l.P99Ms),
+		formatFloat(l.P999Ms),
+		formatFloat(l.MaxMs),
+	}
+}
+
+// sortStatuses return names of status codes in order of codes, unknown names are the last.
+func sortStatuses[V any](statuses map[string]V) []string {
+	order := func(name string) int {
+		for code := codes.OK; code <= codes.Unauthenticated; code++ {
+			if code.String() == name {
+				return int(code)
+			}
+		}
+		return int(codes.Unauthenticated) + 1
+	}
+
+	names := make([]string, 0, len(statuses))
+	for name := range statuses {
+		names = append(names, name)
+	}
+	slices.SortFunc(names, func(a, b string) int {
+		return cmp.Or(cmp.Compare(order(a), order(b)), strings.Compare(a, b))
+	})
+
+	return names
+}
+
+// formatFloat format float without exponent.
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
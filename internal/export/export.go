@@ -0,0 +1,327 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+
+	"github.com/AndreyNiki/grpc-highloader/internal/entity"
+	"github.com/AndreyNiki/grpc-highloader/internal/metrics"
+)
+
+const (
+	// maxTopErrors count of exported errors.
+	maxTopErrors = 50
+	bytesInMB    = 1e6
+)
+
+// Format of export file.
+type Format int
+
+// Available values for Format.
+const (
+	FormatJSON Format = iota
+	FormatCSV
+)
+
+// Report summary and time series of load.
+type Report struct {
+	Summary Summary `json:"summary"`
+	Series  []Point `json:"series"`
+}
+
+// Summary totals of load.
+type Summary struct {
+	Config          Config             `json:"config"`
+	Started         time.Time          `json:"started"`
+	Finished        time.Time          `json:"finished"`
+	DurationSeconds float64            `json:"duration_s"`
+	Requests        int64              `json:"requests"`
+	Outcomes        map[string]int64   `json:"outcomes"`
+	Statuses        map[string]int64   `json:"statuses"`
+	Latency         Latency            `json:"latency"`
+	LatencyByStatus map[string]Latency `json:"latency_by_status"`
+	Payload         Payload            `json:"payload"`
+	TopErrors       []Error            `json:"top_errors"`
+	// Assertions results of assertions, nil if request has no assertions.
+	Assertions *Assertions `json:"assertions,omitempty"`
+}
+
+// Assertions results of checks of responses, failed assertions are sorted by count.
+type Assertions struct {
+	Passed  int64              `json:"passed"`
+	Failed  []AssertionFailure `json:"failed"`
+	Samples []AssertionSample  `json:"samples"`
+}
+
+// AssertionFailure count of responses failed assertion.
+type AssertionFailure struct {
+	Assertion string `json:"assertion"`
+	Count     int64  `json:"count"`
+}
+
+// AssertionSample sample of failed assertion with actual value of response.
+type AssertionSample struct {
+	Time      time.Time `json:"time"`
+	Assertion string    `json:"assertion"`
+	Message   string    `json:"message"`
+}
+
+// Payload totals and sizes of messages in bytes, throughput is average of load on wire.
+type Payload struct {
+	SentBytes         int64   `json:"sent_bytes"`
+	ReceivedBytes     int64   `json:"received_bytes"`
+	WireSentBytes     int64   `json:"wire_sent_bytes"`
+	WireReceivedBytes int64   `json:"wire_received_bytes"`
+	SentMBps          float64 `json:"sent_mb_s"`
+	ReceivedMBps      float64 `json:"received_mb_s"`
+	RequestSize       Size    `json:"request_size"`
+	ResponseSize      Size    `json:"response_size"`
+}
+
+// Size stats of sizes of messages in bytes.
+type Size struct {
+	Count int64 `json:"count"`
+	Min   int64 `json:"min"`
+	Mean  int64 `json:"mean"`
+	P50   int64 `json:"p50"`
+	P90   int64 `json:"p90"`
+	P99   int64 `json:"p99"`
+	Max   int64 `json:"max"`
+}
+
+// Error count of failed requests with the same code, normalized message and details.
+type Error struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Detail  string `json:"detail,omitempty"`
+	Count   int64  `json:"count"`
+}
+
+// Config params of load, credentials and metadata are not exported.
+type Config struct {
+	Host              string  `json:"host"`
+	Service           string  `json:"service"`
+	Method            string  `json:"method"`
+	Transport         string  `json:"transport"`
+	RPS               int     `json:"rps"`
+	RequestDeadlineMs float64 `json:"request_deadline_ms,omitempty"`
+	Message           string  `json:"message"`
+}
+
+// Latency stats of latency in milliseconds.
+type Latency struct {
+	Count  int64   `json:"count"`
+	MinMs  float64 `json:"min_ms"`
+	MeanMs float64 `json:"mean_ms"`
+	P50Ms  float64 `json:"p50_ms"`
+	P90Ms  float64 `json:"p90_ms"`
+	P95Ms  float64 `json:"p95_ms"`
+	P99Ms  float64 `json:"p99_ms"`
+	P999Ms float64 `json:"p999_ms"`
+	MaxMs  float64 `json:"max_ms"`
+}
+
+// Point metrics of one second of load.
+type Point struct {
+	Time           time.Time        `json:"time"`
+	ElapsedSeconds float64          `json:"elapsed_s"`
+	RPS            int64            `json:"rps"`
+	TargetRPS      int64            `json:"target_rps"`
+	InFlight       int64            `json:"in_flight"`
+	SentBytes      int64            `json:"sent_bytes"`
+	ReceivedBytes  int64            `json:"received_bytes"`
+	Outcomes       map[string]int64 `json:"outcomes"`
+	Statuses       map[string]int64 `json:"statuses"`
+	Latency        Latency          `json:"latency"`
+}
+
+// FormatFromPath return format of export by extension of file: ".json" or ".csv".
+func FormatFromPath(path string) (Format, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return FormatJSON, nil
+	case ".csv":
+		return FormatCSV, nil
+	default:
+		return 0, fmt.Errorf("unsupported format of metrics file %q, use .json or .csv", path)
+	}
+}
+
+// NewReport make report of load from metrics.
+func NewReport(req *entity.RequestParams, m *metrics.Metrics, started, finished time.Time) *Report {
+	config := Config{
+		Host:      req.Host,
+		Service:   req.Service,
+		Method:    req.Method,
+		Transport: req.Transport.String(),
+		RPS:       req.RPS,
+		Message:   req.Message,
+	}
+	if req.RequestDeadline != nil {
+		config.RequestDeadlineMs = milliseconds(*req.RequestDeadline)
+	}
+
+	latencyByStatus := map[string]Latency{}
+	for code, l := range m.Latency.ByCode() {
+		latencyByStatus[code.String()] = newLatency(l)
+	}
+	summary := Summary{
+		Config:          config,
+		Started:         started,
+		Finished:        finished,
+		DurationSeconds: finished.Sub(started).Seconds(),
+		Requests:        m.RequestCounter.Value.Load(),
+		Outcomes:        outcomeNames(m.Outcomes.Snapshot()),
+		Statuses:        statusNames(m.StatusCounts()),
+		Latency:         newLatency(m.Latency.Snapshot()),
+		LatencyByStatus: latencyByStatus,
+		Payload:         newPayload(m.Payload.Snapshot(), finished.Sub(started)),
+		TopErrors:       topErrors(m),
+	}
+	if req.Assertions != nil {
+		summary.Assertions = newAssertions(m.Assertions.Snapshot())
+	}
+
+	points := m.Series.Points()
+	series := make([]Point, 0, len(points))
+	for _, p := range points {
+		series = append(series, Point{
+			Time:           p.Time,
+			ElapsedSeconds: p.Elapsed.Seconds(),
+			RPS:            p.RPS,
+			TargetRPS:      p.TargetRPS,
+			InFlight:       p.InFlight,
+			SentBytes:      p.SentBytes,
+			ReceivedBytes:  p.ReceivedBytes,
+			Outcomes:       outcomeNames(p.Outcomes),
+			Statuses:       statusNames(p.Statuses),
+			Latency:        newLatency(p.Latency),
+		})
+	}
+
+	return &Report{Summary: summary, Series: series}
+}
+
+// WriteFile write report to file in format chosen by its extension.
+func WriteFile(path string, report *Report) error {
+	format, err := FormatFromPath(path)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	switch format {
+	case FormatCSV:
+		err = writeCSV(file, report)
+	default:
+		enc := json.NewEncoder(file)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(report)
+	}
+	if err != nil {
+		return fmt.Errorf("could not write metrics to %q: %w", path, err)
+	}
+
+	return file.Close()
+}
+
+// newLatency convert latency stats to milliseconds.
+func newLatency(l metrics.LatencyStat) Latency {
+	return Latency{
+		Count:  l.Count,
+		MinMs:  milliseconds(l.Min),
+		MeanMs: milliseconds(l.Mean),
+		P50Ms:  milliseconds(l.P50),
+		P90Ms:  milliseconds(l.P90),
+		P95Ms:  milliseconds(l.P95),
+		P99Ms:  milliseconds(l.P99),
+		P999Ms: milliseconds(l.P999),
+		MaxMs:  milliseconds(l.Max),
+	}
+}
+
+// statusNames return counts with names of status codes, zero counts are skipped.
+func statusNames(counts map[codes.Code]int64) map[string]int64 {
+	names := make(map[string]int64, len(counts))
+	for code, count := range counts {
+		if count != 0 {
+			names[code.String()] = count
+		}
+	}
+
+	return names
+}
+
+// newPayload make payload stats, throughput is zero if duration is unknown.
+func newPayload(p metrics.PayloadStat, duration time.Duration) Payload {
+	payload := Payload{
+		SentBytes:         p.Sent,
+		ReceivedBytes:     p.Received,
+		WireSentBytes:     p.WireSent,
+		WireReceivedBytes: p.WireReceived,
+		RequestSize:       Size(p.SentSizes),
+		ResponseSize:      Size(p.ReceivedSizes),
+	}
+	if seconds := duration.Seconds(); seconds > 0 {
+		payload.SentMBps = float64(p.WireSent) / bytesInMB / seconds
+		payload.ReceivedMBps = float64(p.WireReceived) / bytesInMB / seconds
+	}
+
+	return payload
+}
+
+// topErrors return the most frequent errors.
+func topErrors(m *metrics.Metrics) []Error {
+	stats := m.TopErrors(maxTopErrors)
+	errs := make([]Error, 0, len(stats))
+	for _, e := range stats {
+		errs = append(errs, Error{Code: e.Code.String(), Message: e.Message, Detail: e.Detail, Count: e.Count})
+	}
+
+	return errs
+}
+
+// newAssertions make results of assertions.
+func newAssertions(a metrics.AssertionsStat) *Assertions {
+	assertions := &Assertions{
+		Passed:  a.Passed,
+		Failed:  make([]AssertionFailure, 0, len(a.Failed)),
+		Samples: make([]AssertionSample, 0, len(a.Samples)),
+	}
+	for _, f := range a.Failed {
+		assertions.Failed = append(assertions.Failed, AssertionFailure(f))
+	}
+	for _, s := range a.Samples {
+		assertions.Samples = append(assertions.Samples, AssertionSample(s))
+	}
+
+	return assertions
+}
+
+// outcomeNames return counts with names of outcomes, zero counts are skipped.
+func outcomeNames(counts map[metrics.Outcome]int64) map[string]int64 {
+	names := make(map[string]int64, len(counts))
+	for outcome, count := range counts {
+		if count != 0 {
+			names[outcome.String()] = count
+		}
+	}
+
+	return names
+}
+
+// milliseconds return duration in milliseconds.
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,149 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/AndreyNiki/grpc-highloader/internal/entity"
+	"github.com/AndreyNiki/grpc-highloader/internal/metrics"
+	"github.com/AndreyNiki/grpc-highloader/internal/utils/ptr"
+)
+
+// newTestReport make report of two seconds of load.
+func newTestReport() *Report {
+	m := metrics.InitMetrics()
+	m.SetRequestPerSecond(10)
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := range 3 {
+		m.IncrementRequestCount()
+		m.ObserveOutcome(metrics.OutcomeStatus, status.New(codes.OK, ""))
+		m.ObserveLatency(codes.OK, time.Duration(i+1)*time.Millisecond)
+		m.ObserveSent(100, 0)
+		m.ObserveReceived(1_000_000, 0)
+		m.ObserveAssertionPass()
+	}
+	m.Sample(start.Add(time.Second))
+	m.IncrementRequestCount()
+	m.ObserveOutcome(metrics.OutcomeStatus, status.New(codes.Unavailable, "no healthy upstream"))
+	m.ObserveLatency(codes.Unavailable, 50*time.Millisecond)
+	m.ObserveAssertionFailure("latency <= 100ms", "got 150ms")
+	m.Sample(start.Add(2 * time.Second))
+
+	req := &entity.RequestParams{
+		Host:            "localhost:50051",
+		Service:         "example.v1.UserService",
+		Method:          "Get",
+		Message:         `{"id": "1"}`,
+		RPS:             10,
+		RequestDeadline: ptr.ToPtr(time.Second),
+		Metadata:        map[string]string{"authorization": "secret"},
+		Transport:       entity.TransportConnectJSON,
+		Assertions:      &entity.Assertions{MaxLatency: 100 * time.Millisecond},
+	}
+	return NewReport(req, m, start, start.Add(2*time.Second))
+}
+
+func TestFormatFromPath(t *testing.T) {
+	t.Run("Test known extensions", func(t *testing.T) {
+		format, err := FormatFromPath("/tmp/metrics.JSON")
+		require.NoError(t, err)
+		assert.Equal(t, FormatJSON, format)
+		format, err = FormatFromPath("metrics.csv")
+		require.NoError(t, err)
+		assert.Equal(t, FormatCSV, format)
+	})
+
+	t.Run("Test unknown extension", func(t *testing.T) {
+		_, err := FormatFromPath("metrics.txt")
+		assert.Error(t, err)
+	})
+}
+
+func TestWriteFile(t *testing.T) {
+	t.Run("Test JSON", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "metrics.json")
+		require.NoError(t, WriteFile(path, newTestReport()))
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.NotContains(t, string(data), "secret")
+		var report Report
+		require.NoError(t, json.Unmarshal(data, &report))
+
+		s := report.Summary
+		assert.Equal(t, "connect-json", s.Config.Transport)
+		assert.Equal(t, float64(1000), s.Config.RequestDeadlineMs)
+		assert.Equal(t, float64(2), s.DurationSeconds)
+		assert.Equal(t, int64(4), s.Requests)
+		assert.Equal(t, map[string]int64{"status": 4}, s.Outcomes)
+		assert.Equal(t, map[string]int64{"OK": 3, "Unavailable": 1}, s.Statuses)
+		assert.Equal(t, int64(4), s.Latency.Count)
+		assert.Equal(t, float64(50), s.LatencyByStatus["Unavailable"].MaxMs)
+		assert.Equal(t, int64(3_000_000), s.Payload.ReceivedBytes)
+		assert.Equal(t, float64(1.5), s.Payload.ReceivedMBps)
+		assert.Equal(t, Size{Count: 3, Min: 100, Mean: 100, P50: 100, P90: 100, P99: 100, Max: 100}, s.Payload.RequestSize)
+		assert.Equal(t, []Error{{Code: "Unavailable", Message: "no healthy upstream", Count: 1}}, s.TopErrors)
+		require.NotNil(t, s.Assertions)
+		assert.Equal(t, int64(3), s.Assertions.Passed)
+		assert.Equal(t, []AssertionFailure{{Assertion: "latency <= 100ms", Count: 1}}, s.Assertions.Failed)
+		require.Len(t, s.Assertions.Samples, 1)
+		assert.Equal(t, "got 150ms", s.Assertions.Samples[0].Message)
+
+		require.Len(t, report.Series, 2)
+		assert.Equal(t, int64(3), report.Series[0].RPS)
+		assert.Equal(t, int64(10), report.Series[0].TargetRPS)
+		assert.Equal(t, map[string]int64{"Unavailable": 1}, report.Series[1].Statuses)
+		assert.Equal(t, map[string]int64{"status": 1}, report.Series[1].Outcomes)
+		assert.Equal(t, int64(300), report.Series[0].SentBytes)
+		assert.Equal(t, float64(1), report.Series[0].Latency.MinMs)
+	})
+
+	t.Run("Test CSV", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "metrics.csv")
+		require.NoError(t, WriteFile(path, newTestReport()))
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		tables := strings.Split(string(data), "\n\n")
+		require.Len(t, tables, 3)
+		summary, series, errs := tables[0], tables[1], tables[2]
+
+		rows, err := csv.NewReader(strings.NewReader(summary)).ReadAll()
+		require.NoError(t, err)
+		values := map[string]string{}
+		for _, row := range rows[1:] {
+			values[row[0]] = row[1]
+		}
+		assert.Equal(t, "4", values["requests"])
+		assert.Equal(t, "4", values["outcome_status"])
+		assert.Equal(t, "3", values["status_OK"])
+		assert.Equal(t, "50", values["latency_Unavailable_max_ms"])
+		assert.Equal(t, "1.5", values["received_mb_s"])
+		assert.Equal(t, "100", values["request_size_p99"])
+		assert.Equal(t, "3", values["assertions_passed"])
+		assert.Equal(t, "1", values["assertion_failed latency <= 100ms"])
+
+		rows, err = csv.NewReader(strings.NewReader(series)).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, rows, 3)
+		assert.Equal(t, []string{"time", "elapsed_s", "rps", "target_rps", "in_flight", "sent_bytes", "received_bytes",
+			"outcome_status", "status_OK", "status_Unavailable"}, rows[0][:10])
+		assert.Equal(t, []string{"3", "10", "0", "300", "3000000", "3", "3", "0"}, rows[1][2:10])
+		assert.Equal(t, []string{"1", "10", "0", "0", "0", "1", "0", "1"}, rows[2][2:10])
+
+		rows, err = csv.NewReader(strings.NewReader(errs)).ReadAll()
+		require.NoError(t, err)
+		assert.Equal(t, [][]string{{"count", "code", "message", "detail"}, {"1", "Unavailable", "no healthy upstream", ""}},
+			rows)
+	})
+}
@@ -0,0 +1,236 @@
+package cards
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+	"google.golang.org/grpc/codes"
+
+	"github.com/AndreyNiki/grpc-highloader/internal/entity"
+	"github.com/AndreyNiki/grpc-highloader/internal/gui/components/highloader/config"
+	"github.com/AndreyNiki/grpc-highloader/internal/gui/utils"
+	"github.com/AndreyNiki/grpc-highloader/internal/utils/ptr"
+)
+
+const (
+	labelAssertionsName      = "Response Assertions"
+	labelExpectedCodeName    = "Expected Status"
+	labelMaxLatencyName      = "Max Latency"
+	labelRequiredHeaders     = "Required Headers"
+	labelRequiredTrailers    = "Required Trailers"
+	labelFieldAssertionsName = "Fields"
+	labelFieldPathName       = "Path, e.g. users.0.id"
+	labelFieldValueName      = "Value, range as min..max"
+	placeholderMaxLatency    = "e.g. 200ms, if no set then no limit"
+	placeholderKeys          = "Separated by comma"
+	// codeAny option of expected status, only responses with OK status are checked.
+	codeAny        = "Any (check OK responses)"
+	rangeSeparator = ".."
+)
+
+// FieldAssertionRow row of form with assertion of response field.
+type FieldAssertionRow struct {
+	Path  *widget.Entry
+	Op    *widget.Select
+	Value *widget.Entry
+}
+
+// Assertions form with assertions of responses.
+type Assertions struct {
+	Content    fyne.CanvasObject
+	Code       *widget.Select
+	MaxLatency *utils.Entry
+	Headers    *utils.Entry
+	Trailers   *utils.Entry
+	Fields     []*FieldAssertionRow
+	fieldsBox  *fyne.Container
+}
+
+// NewAssertions create a new Assertions.
+func NewAssertions() *Assertions {
+	a := &Assertions{
+		Code:       widget.NewSelect(codeOptions(), nil),
+		MaxLatency: utils.NewEntry(labelMaxLatencyName, nil, ptr.ToPtr(placeholderMaxLatency)),
+		Headers:    utils.NewEntry(labelRequiredHeaders, nil, ptr.ToPtr(placeholderKeys)),
+		Trailers:   utils.NewEntry(labelRequiredTrailers, nil, ptr.ToPtr(placeholderKeys)),
+		fieldsBox:  container.NewVBox(),
+	}
+	a.Code.Selected = codeAny
+	a.MaxLatency.Value.Validator = utils.DurationValidation()
+
+	buttonAddField := widget.NewButton(buttonAddKeyValueName, func() {
+		a.AddField("", entity.AssertionOpEquals.String(), "")
+	})
+	a.Content = container.NewVBox(
+		widget.NewLabel(labelAssertionsName),
+		container.NewGridWithColumns(2, widget.NewLabel(labelExpectedCodeName), a.Code),
+		entryRow(a.MaxLatency), entryRow(a.Headers), entryRow(a.Trailers),
+		widget.NewLabel(labelFieldAssertionsName), a.fieldsBox, buttonAddField,
+	)
+	return a
+}
+
+// AddField add new row with assertion of response field.
+func (a *Assertions) AddField(path, op, value string) {
+	row := &FieldAssertionRow{
+		Path:  widget.NewEntry(),
+		Op:    widget.NewSelect(assertionOps(), nil),
+		Value: widget.NewEntry(),
+	}
+	row.Path.SetPlaceHolder(labelFieldPathName)
+	row.Path.SetText(path)
+	row.Op.Selected = op
+	row.Value.SetPlaceHolder(labelFieldValueName)
+	row.Value.SetText(value)
+	a.Fields = append(a.Fields, row)
+
+	buttonRemove := widget.NewButton(buttonRemoveKeyValueMetadata, nil)
+	buttonRemove.Importance = widget.DangerImportance
+	grid := container.NewGridWithColumns(4, row.Path, row.Op, row.Value, buttonRemove)
+	a.fieldsBox.Add(grid)
+	buttonRemove.OnTapped = func() {
+		a.Fields = slices.DeleteFunc(a.Fields, func(r *FieldAssertionRow) bool { return r == row })
+		a.fieldsBox.Remove(grid)
+	}
+}
+
+// Params return assertions from form, nil if no assertion is set.
+func (a *Assertions) Params() (*entity.Assertions, error) {
+	params := &entity.Assertions{
+		Headers:  splitKeys(a.Headers.Value.Text),
+		Trailers: splitKeys(a.Trailers.Value.Text),
+	}
+	if i := slices.Index(a.Code.Options, a.Code.Selected); i > 0 {
+		params.Code = ptr.ToPtr(codes.Code(i - 1))
+	}
+	if a.MaxLatency.Value.Text != "" {
+		v, err := time.ParseDuration(a.MaxLatency.Value.Text)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse %s: %w", strings.ToLower(a.MaxLatency.Label.Text), err)
+		}
+		params.MaxLatency = v
+	}
+	for _, row := range a.Fields {
+		if row.Path.Text == "" {
+			continue
+		}
+		f := entity.FieldAssertion{
+			Path:  row.Path.Text,
+			Op:    entity.AssertionOp(slices.Index(row.Op.Options, row.Op.Selected)),
+			Value: row.Value.Text,
+		}
+		if f.Op == entity.AssertionOpRange {
+			var err error
+			f.Min, f.Max, err = parseRange(row.Value.Text)
+			if err != nil {
+				return nil, fmt.Errorf("could not parse range of field %q: %w", f.Path, err)
+			}
+		}
+		params.Fields = append(params.Fields, f)
+	}
+
+	if params.Code == nil && params.MaxLatency == 0 && len(params.Headers) == 0 && len(params.Trailers) == 0 &&
+		len(params.Fields) == 0 {
+		return nil, nil
+	}
+	return params, nil
+}
+
+// Load set assertions from config in form.
+func (a *Assertions) Load(cfg config.Assertions) {
+	if slices.Contains(a.Code.Options, cfg.Code) {
+		a.Code.SetSelected(cfg.Code)
+	}
+	a.MaxLatency.Value.SetText(cfg.MaxLatency)
+	a.Headers.Value.SetText(strings.Join(cfg.Headers, ", "))
+	a.Trailers.Value.SetText(strings.Join(cfg.Trailers, ", "))
+	for _, f := range cfg.Fields {
+		a.AddField(f.Path, f.Op, f.Value)
+	}
+}
+
+// Save return assertions from form for config, nil if no assertion is set.
+func (a *Assertions) Save() *config.Assertions {
+	cfg := &config.Assertions{
+		MaxLatency: a.MaxLatency.Value.Text,
+		Headers:    splitKeys(a.Headers.Value.Text),
+		Trailers:   splitKeys(a.Trailers.Value.Text),
+	}
+	if a.Code.Selected != codeAny {
+		cfg.Code = a.Code.Selected
+	}
+	for _, row := range a.Fields {
+		if row.Path.Text != "" {
+			cfg.Fields = append(cfg.Fields, config.FieldAssertion{
+				Path: row.Path.Text, Op: row.Op.Selected, Value: row.Value.Text,
+			})
+		}
+	}
+
+	if cfg.Code == "" && cfg.MaxLatency == "" && len(cfg.Headers) == 0 && len(cfg.Trailers) == 0 &&
+		len(cfg.Fields) == 0 {
+		return nil
+	}
+	return cfg
+}
+
+// codeOptions options for select of expected status code.
+func codeOptions() []string {
+	options := []string{codeAny}
+	for code := codes.OK; code <= codes.Unauthenticated; code++ {
+		options = append(options, code.String())
+	}
+
+	return options
+}
+
+// assertionOps options for select of operation in order of entity.AssertionOp.
+func assertionOps() []string {
+	var ops []string
+	for op := entity.AssertionOpEquals; op <= entity.AssertionOpRange; op++ {
+		ops = append(ops, op.String())
+	}
+
+	return ops
+}
+
+// splitKeys split keys separated by comma, empty keys are skipped.
+func splitKeys(s string) []string {
+	var keys []string
+	for _, k := range strings.Split(s, ",") {
+		if k = strings.TrimSpace(k); k != "" {
+			keys = append(keys, k)
+		}
+	}
+
+	return keys
+}
+
+// parseRange parse range in format "min..max", bounds are optional.
+func parseRange(s string) (*float64, *float64, error) {
+	lower, upper, ok := strings.Cut(s, rangeSeparator)
+	if !ok {
+		return nil, nil, errors.New("range must be in format min..max")
+	}
+
+	var bounds [2]*float64
+	for i, b := range []string{lower, upper} {
+		if b = strings.TrimSpace(b); b == "" {
+			continue
+		}
+		v, err := strconv.ParseFloat(b, 64)
+		if err != nil {
+			return nil, nil, err
+		}
+		bounds[i] = &v
+	}
+
+	return bounds[0], bounds[1], nil
+}
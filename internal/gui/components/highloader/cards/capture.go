@@ -0,0 +1,98 @@
+package cards
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/AndreyNiki/grpc-highloader/internal/entity"
+	"github.com/AndreyNiki/grpc-highloader/internal/gui/components/highloader/config"
+	"github.com/AndreyNiki/grpc-highloader/internal/gui/utils"
+	"github.com/AndreyNiki/grpc-highloader/internal/utils/ptr"
+)
+
+const (
+	labelCaptureName       = "Response Capture"
+	labelCapturePathName   = "Capture Path"
+	labelSampleRateName    = "Sample Rate"
+	labelFirstErrorsName   = "First Errors"
+	placeholderCapturePath = "*.jsonl, if no set then no captured responses"
+	placeholderSampleRate  = "0..1, e.g. 0.001, default 0"
+	// firstErrorsDefault count of the first failed requests which are always captured.
+	firstErrorsDefault     = "100"
+	placeholderFirstErrors = "always captured failed requests, default " + firstErrorsDefault
+)
+
+// Capture form with capture of sampled requests and responses to file.
+type Capture struct {
+	Content     fyne.CanvasObject
+	Path        *utils.Entry
+	SampleRate  *utils.Entry
+	FirstErrors *utils.Entry
+}
+
+// NewCapture create a new Capture.
+func NewCapture() *Capture {
+	c := &Capture{
+		Path:        utils.NewEntry(labelCapturePathName, nil, ptr.ToPtr(placeholderCapturePath)),
+		SampleRate:  utils.NewEntry(labelSampleRateName, nil, ptr.ToPtr(placeholderSampleRate)),
+		FirstErrors: utils.NewEntry(labelFirstErrorsName, nil, ptr.ToPtr(placeholderFirstErrors)),
+	}
+	c.FirstErrors.Value.Validator = utils.NumberValidation()
+	c.Content = container.NewVBox(
+		widget.NewLabel(labelCaptureName),
+		entryRow(c.Path), entryRow(c.SampleRate), entryRow(c.FirstErrors),
+	)
+	return c
+}
+
+// Params return capture params from form, nil if path is not set.
+func (c *Capture) Params() (*entity.CaptureParams, error) {
+	if c.Path.Value.Text == "" {
+		return nil, nil
+	}
+
+	params := &entity.CaptureParams{Path: c.Path.Value.Text}
+	if c.SampleRate.Value.Text != "" {
+		rate, err := strconv.ParseFloat(c.SampleRate.Value.Text, 64)
+		if err != nil || rate < 0 || rate > 1 {
+			return nil, errors.New("sample rate must be a number from 0 to 1")
+		}
+		params.SampleRate = rate
+	}
+	firstErrors := c.FirstErrors.Value.Text
+	if firstErrors == "" {
+		firstErrors = firstErrorsDefault
+	}
+	n, err := strconv.Atoi(firstErrors)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse first errors: %w", err)
+	}
+	params.FirstErrors = n
+
+	return params, nil
+}
+
+// Load set capture from config in form.
+func (c *Capture) Load(cfg config.Capture) {
+	c.Path.Value.SetText(cfg.Path)
+	c.SampleRate.Value.SetText(cfg.SampleRate)
+	c.FirstErrors.Value.SetText(cfg.FirstErrors)
+}
+
+// Save return capture from form for config, nil if path is not set.
+func (c *Capture) Save() *config.Capture {
+	if c.Path.Value.Text == "" {
+		return nil
+	}
+
+	return &config.Capture{
+		Path:        c.Path.Value.Text,
+		SampleRate:  c.SampleRate.Value.Text,
+		FirstErrors: c.FirstErrors.Value.Text,
+	}
+}
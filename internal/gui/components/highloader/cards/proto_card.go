@@ -5,7 +5,9 @@ import (
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/widget"
 
+	"github.com/AndreyNiki/grpc-highloader/internal/entity"
 	"github.com/AndreyNiki/grpc-highloader/internal/gui/components/highloader/config"
+	guierrs "github.com/AndreyNiki/grpc-highloader/internal/gui/errors"
 	"github.com/AndreyNiki/grpc-highloader/internal/gui/utils"
 )
 
@@ -34,6 +36,9 @@ func (p *ProtoCardsHolder) Add(containerCards *ContainerCards, proto *config.Pro
 
 	id := p.Cards.Add(c)
 	c.buttonRemove.OnTapped = func() {
+		if c.stopWatch != nil {
+			c.stopWatch()
+		}
 		p.Cards.Remove(id)
 		c.parent.Remove(c.card)
 	}
@@ -46,7 +51,11 @@ type ProtoCard struct {
 	RequestCardHolder *RequestsCardsHolder
 	card              *widget.Card
 	parent            *fyne.Container
+	box               *fyne.Container
 	buttonRemove      *widget.Button
+	containerCards    *ContainerCards
+	stopWatch         func()
+	currentErr        *guierrs.GUIError
 }
 
 // newProtoCard create a new ProtoCard.
@@ -65,7 +74,12 @@ func newProtoCard(containerCards *ContainerCards, proto *config.Proto) *ProtoCar
 		Parent:        requestCardBox,
 		Proto:         containerCards.Proto,
 		Host:          containerCards.Host,
+		Connection:    containerCards.Connection,
 		LoaderFactory: containerCards.LoaderFactory,
+		Watcher:       containerCards.Watcher,
+		Validator:     containerCards.Validator,
+		Exporter:      containerCards.Exporter,
+		Telemetry:     containerCards.Telemetry,
 	}
 	buttonAddReq.OnTapped = func() {
 		requestCardHolder.Add(newContainer, nil)
@@ -76,11 +90,50 @@ func newProtoCard(containerCards *ContainerCards, proto *config.Proto) *ProtoCar
 			requestCardHolder.Add(newContainer, &r)
 		}
 	}
-	return &ProtoCard{
+	c := &ProtoCard{
 		FilePath:          containerCards.Proto.FilePath,
 		RequestCardHolder: requestCardHolder,
 		card:              card,
 		parent:            containerCards.Parent,
+		box:               cardBox,
 		buttonRemove:      buttonRemove,
+		containerCards:    newContainer,
+	}
+
+	if containerCards.Watcher != nil {
+		stop, err := containerCards.Watcher.Watch(containerCards.Proto, c.reload)
+		if err != nil {
+			c.showError(err)
+		}
+		c.stopWatch = stop
+	}
+	return c
+}
+
+// reload update request cards after proto was changed.
+func (c *ProtoCard) reload(parsedProto *entity.ParsedProto, err error) {
+	fyne.Do(func() {
+		if err != nil {
+			c.showError(err)
+			return
+		}
+		c.showError(nil)
+
+		c.containerCards.Proto = parsedProto
+		for _, r := range c.RequestCardHolder.Cards.Holder {
+			r.refreshProto(parsedProto)
+		}
+	})
+}
+
+// showError show error in card, the previous error is removed. Nil error only removes the previous error.
+func (c *ProtoCard) showError(err error) {
+	if c.currentErr != nil {
+		c.box.Remove(c.currentErr.Text)
+		c.currentErr = nil
+	}
+	if err != nil {
+		c.currentErr = guierrs.NewGUIError(err)
+		c.box.Add(c.currentErr.Text)
 	}
 }
@@ -2,9 +2,11 @@ package cards
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
 	"fyne.io/fyne/v2"
@@ -12,8 +14,8 @@ import (
 	"fyne.io/fyne/v2/widget"
 
 	"github.com/AndreyNiki/grpc-highloader/internal/entity"
+	"github.com/AndreyNiki/grpc-highloader/internal/export"
 	"github.com/AndreyNiki/grpc-highloader/internal/gui/components/highloader/config"
-	"github.com/AndreyNiki/grpc-highloader/internal/gui/components/highloader/mapper"
 	"github.com/AndreyNiki/grpc-highloader/internal/gui/interfaces"
 	"github.com/AndreyNiki/grpc-highloader/internal/gui/utils"
 	"github.com/AndreyNiki/grpc-highloader/internal/logger"
@@ -25,6 +27,8 @@ const (
 	buttonStartRequestName     = "Start"
 	buttonStopRequestName      = "Stop"
 	buttonRemoveRequestName    = "Remove"
+	buttonValidateName         = "Validate"
+	checkSendOneRequestName    = "Send one request"
 	buttonAddKeyValueName      = "+"
 	labelServicesName          = "Services"
 	labelMethodsName           = "Methods"
@@ -38,12 +42,30 @@ const (
 	labelAdditionalOptionsName = "Additional Options"
 	labelRequestCardName       = "Request"
 	labelRequestDeadlineName   = "Request Deadline"
+	labelTransportName         = "Transport"
+	protoChangedWarning        = "Proto was changed: %s"
+	validationPassedInfo       = "Validation passed"
 )
 
 const (
 	rpsDefault = "100"
 )
 
+// Transports options for select of transport.
+const (
+	transportGRPC         = "gRPC"
+	transportGRPCWeb      = "gRPC-Web"
+	transportGRPCWebText  = "gRPC-Web (text)"
+	transportConnectJSON  = "Connect (JSON)"
+	transportConnectProto = "Connect (proto)"
+	transportREST         = "REST (transcoded)"
+)
+
+// transports options for select of transport in order of entity.Transport.
+var transports = []string{
+	transportGRPC, transportGRPCWeb, transportGRPCWebText, transportConnectJSON, transportConnectProto, transportREST,
+}
+
 // RequestsCardsHolder struct for management request cards.
 type RequestsCardsHolder struct {
 	Cards *Cards[*RequestCard]
@@ -62,6 +84,7 @@ func (p *RequestsCardsHolder) Add(containerCards *ContainerCards, preloadRequest
 	c.parent.Add(c.card)
 
 	id := p.Cards.Add(c)
+	c.id = id
 	c.buttonRemove.OnTapped = func() {
 		p.Cards.Remove(id)
 		c.parent.Remove(c.card)
@@ -71,12 +94,20 @@ func (p *RequestsCardsHolder) Add(containerCards *ContainerCards, preloadRequest
 
 // RequestCard struct with info for request card.
 type RequestCard struct {
+	id            int
 	card          *widget.Card
 	parent        *fyne.Container
 	loaderFactory interfaces.LoaderFactory
+	validator     interfaces.Validator
+	exporter      interfaces.MetricsExporter
+	telemetry     interfaces.TelemetryExporter
 	buttonStart   *widget.Button
 	buttonStop    *widget.Button
 	buttonRemove  *widget.Button
+	infoLabel     *widget.Label
+	vsInfoLabel   *container.Scroll
+	protoWarning  *widget.Label
+	stats         *statistics
 	Form          *FormRequest
 }
 
@@ -85,10 +116,13 @@ func newRequestCard(containerCards *ContainerCards, preloadRequest *config.Reque
 	r := &RequestCard{
 		parent:        containerCards.Parent,
 		loaderFactory: containerCards.LoaderFactory,
+		validator:     containerCards.Validator,
+		exporter:      containerCards.Exporter,
+		telemetry:     containerCards.Telemetry,
 	}
 
 	le := utils.NewEntry("Debug Log Path", nil, ptr.ToPtr("If no set then no saved logs"))
-	me := utils.NewEntry("Metrics Path", nil, ptr.ToPtr("If no set then no saved metrics"))
+	me := utils.NewEntry("Metrics Path", nil, ptr.ToPtr("*.json or *.csv, if no set then no saved metrics"))
 	lb := container.NewVBox(le.Label, le.Value)
 	mb := container.NewVBox(me.Label, me.Value)
 
@@ -104,11 +138,15 @@ func newRequestCard(containerCards *ContainerCards, preloadRequest *config.Reque
 	gsa := container.NewGridWithColumns(3, sa.Entry.Label, sa.Entry.Value, sa.Select)
 	dr := utils.NewEntryTime(labelRequestDeadlineName, nil, nil, nil)
 	gdr := container.NewGridWithColumns(3, dr.Entry.Label, dr.Entry.Value, dr.Select)
+	transport := widget.NewSelect(transports, nil)
+	transport.SetSelected(transportGRPC)
+	gt := container.NewGridWithColumns(2, widget.NewLabel(labelTransportName), transport)
 
-	vBoxRS := container.NewVBox(widget.NewLabel(labelRequestSettingName), geWorkers, gsa, gdr)
+	vBoxRS := container.NewVBox(widget.NewLabel(labelRequestSettingName), geWorkers, gsa, gdr, gt)
 	hBoxRS := container.NewHBox(utils.NewLine(), vBoxRS)
 
-	rb := container.NewVBox(widget.NewLabel(labelMessageName), sm.MessageEntry)
+	lm := container.NewGridWithColumns(2, widget.NewLabel(labelMessageName), sm.ExampleKind)
+	rb := container.NewVBox(lm, sm.MessageEntry)
 	lmd := container.NewGridWithColumns(2, widget.NewLabel(labelMetadataKeyName),
 		widget.NewLabel(labelMetadataValueName))
 
@@ -119,7 +157,10 @@ func newRequestCard(containerCards *ContainerCards, preloadRequest *config.Reque
 	})
 	bmd := container.NewVBox(widget.NewLabel(labelMetadataName), lmd, smd, buttonAddMetadata)
 
-	ao := widget.NewAccordionItem(labelAdditionalOptionsName, widget.NewLabel("coming soon..."))
+	assertions := NewAssertions()
+	capture := NewCapture()
+	ao := widget.NewAccordionItem(labelAdditionalOptionsName,
+		container.NewVBox(assertions.Content, utils.NewLine(), capture.Content))
 
 	buttonRemove := widget.NewButton(buttonRemoveRequestName, nil)
 	buttonRemove.Importance = widget.DangerImportance
@@ -134,12 +175,16 @@ func newRequestCard(containerCards *ContainerCards, preloadRequest *config.Reque
 		RPS:             rps,
 		StopAfter:       sa,
 		DeadlineReq:     dr,
+		Transport:       transport,
 		ServicesMethods: sm,
 		TimeTrackerCh:   timeTrackerCh,
 		CancelCh:        cancelSignal,
 		ButtonRemove:    buttonRemove,
 		Host:            containerCards.Host,
+		Connection:      containerCards.Connection,
 		Metadata:        metadata,
+		Assertions:      assertions,
+		Capture:         capture,
 		Metrics:         mtrcs,
 		ParsedProto:     containerCards.Proto,
 	}
@@ -152,13 +197,21 @@ func newRequestCard(containerCards *ContainerCards, preloadRequest *config.Reque
 	layerMiddle := container.NewGridWithColumns(2, rb, bmd)
 	layerAdditional := widget.NewAccordion(ao)
 	layerController := r.makeControllerRequest(form)
+	stats := newStatistics(mtrcs)
+
+	protoWarning := widget.NewLabel("")
+	protoWarning.Importance = widget.WarningImportance
+	protoWarning.Wrapping = fyne.TextWrapWord
+	protoWarning.Hide()
 
 	mainBox := container.NewVBox(
+		protoWarning,
 		layerHeader, utils.NewLine(),
 		layerTop, utils.NewLine(),
 		layerMiddle, utils.NewLine(),
 		layerAdditional, utils.NewLine(),
-		layerController)
+		layerController, utils.NewLine(),
+		stats.box)
 
 	card := widget.NewCard("", labelRequestCardName, mainBox)
 	buttonRemove.OnTapped = func() {
@@ -168,6 +221,8 @@ func newRequestCard(containerCards *ContainerCards, preloadRequest *config.Reque
 
 	r.card = card
 	r.buttonRemove = buttonRemove
+	r.protoWarning = protoWarning
+	r.stats = stats
 	r.Form = form
 	return r
 }
@@ -189,12 +244,21 @@ func (r *RequestCard) setupFormFromPreloadRequest(fr *FormRequest, request *conf
 	if request.RequestDeadline.Duration != "" && request.RequestDeadline.Type != "" {
 		fr.DeadlineReq.FindAndSetOption(request.RequestDeadline.Duration, request.RequestDeadline.Type)
 	}
+	if slices.Contains(transports, request.Transport) {
+		fr.Transport.SetSelected(request.Transport)
+	}
 
 	if len(request.Metadata) != 0 {
 		for _, m := range request.Metadata {
 			fr.Metadata.AddKeyValue(m.Key, m.Value)
 		}
 	}
+	if request.Assertions != nil {
+		fr.Assertions.Load(*request.Assertions)
+	}
+	if request.Capture != nil {
+		fr.Capture.Load(*request.Capture)
+	}
 	fr.ServicesMethods.preset(request.Service, request.Method, request.Message)
 }
 
@@ -214,14 +278,15 @@ func (r *RequestCard) makeControllerRequest(fr *FormRequest) *fyne.Container {
 	vsInfoLabel := container.NewVScroll(infoLabel)
 	vsInfoLabel.Resize(fyne.NewSize(550, 30))
 	vsInfoLabel.Hide()
+	r.infoLabel = infoLabel
+	r.vsInfoLabel = vsInfoLabel
 
 	timeLabel := widget.NewLabel("")
 	buttonStart.OnTapped = func() {
 		vsInfoLabel.Hide()
 		err := r.startLoadingRequests(fr)
 		if err != nil {
-			vsInfoLabel.Show()
-			infoLabel.SetText(err.Error())
+			r.showError(err)
 			return
 		}
 		buttonStop.Enable()
@@ -233,26 +298,69 @@ func (r *RequestCard) makeControllerRequest(fr *FormRequest) *fyne.Container {
 	buttonStop.OnTapped = func() {
 		r.stopLoadingRequests(fr)
 	}
+
+	checkSend := widget.NewCheck(checkSendOneRequestName, nil)
+	buttonValidate := widget.NewButton(buttonValidateName, nil)
+	buttonValidate.OnTapped = func() {
+		vsInfoLabel.Hide()
+		req, err := r.makeRequestParams(fr)
+		if err != nil {
+			vsInfoLabel.Show()
+			infoLabel.SetText(err.Error())
+			return
+		}
+
+		buttonValidate.Disable()
+		go func() {
+			problems := r.validator.Validate(context.Background(), req, checkSend.Checked)
+			text := validationPassedInfo
+			if len(problems) != 0 {
+				text = formatProblems(problems)
+			}
+			fyne.Do(func() {
+				buttonValidate.Enable()
+				vsInfoLabel.Show()
+				infoLabel.SetText(text)
+			})
+		}()
+	}
+	if r.validator == nil {
+		buttonValidate.Disable()
+		checkSend.Disable()
+	}
+
 	vfEntryErrFn := func() {
 		buttonStart.Disable()
+		buttonValidate.Disable()
 	}
 	vfEntryPassFn := func() {
 		buttonStart.Enable()
+		if r.validator != nil {
+			buttonValidate.Enable()
+		}
 	}
 
 	vf := utils.NewValidationForm(vfEntryErrFn, vfEntryPassFn)
 	vf.AddValidationEntries(
 		&utils.ValidationEntry{Entry: fr.RPS.Value, Validator: utils.NumberValidation()},
 		&utils.ValidationEntry{Entry: fr.StopAfter.Entry.Value, Validator: utils.NumberValidation()},
-		&utils.ValidationEntry{Entry: fr.DeadlineReq.Entry.Value, Validator: utils.NumberValidation()})
+		&utils.ValidationEntry{Entry: fr.DeadlineReq.Entry.Value, Validator: utils.NumberValidation()},
+		&utils.ValidationEntry{Entry: fr.Assertions.MaxLatency.Value, Validator: utils.DurationValidation()},
+		&utils.ValidationEntry{Entry: fr.Capture.FirstErrors.Value, Validator: utils.NumberValidation()})
 	vf.SetOrRefreshValidate()
 
 	return container.NewHBox(
 		buttonStart, buttonStop,
-		fr.ButtonRemove, timeLabel,
+		fr.ButtonRemove, buttonValidate, checkSend, timeLabel,
 		container.NewWithoutLayout(vsInfoLabel))
 }
 
+// showError show error in info label of card.
+func (r *RequestCard) showError(err error) {
+	r.vsInfoLabel.Show()
+	r.infoLabel.SetText(err.Error())
+}
+
 // stopLoadingRequests stop requests.
 func (r *RequestCard) stopLoadingRequests(fr *FormRequest) {
 	if fr.Logger != nil {
@@ -278,13 +386,80 @@ func (r *RequestCard) startLoadingRequests(fr *FormRequest) error {
 		fr.Logger = log
 		ctx = logger.ContextWithLogger(ctx, log.GetLogger())
 	}
+	req, err := r.makeRequestParams(fr)
+	if err != nil {
+		return err
+	}
+
+	metricsPath := fr.MetricsPath.Value.Text
+	if metricsPath != "" {
+		if _, err := export.FormatFromPath(metricsPath); err != nil {
+			return err
+		}
+	}
+
+	loader, err := r.loaderFactory.NewLoader(req, fr.Metrics)
+	if err != nil {
+		return fmt.Errorf("could not create loader: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	r.stopRequestsManager(fr, cancel)
+	go r.stats.showStats(ctx)
+	go r.stats.showInfo(ctx)
+
+	labels := entity.MetricsLabels{
+		Proto:   req.Proto.FilePath,
+		Service: req.Service,
+		Method:  req.Method,
+		Host:    req.Host,
+		Card:    strconv.Itoa(r.id),
+	}
+	var unregisters []func()
+	if r.exporter != nil {
+		unregisters = append(unregisters, r.exporter.Register(labels, fr.Metrics))
+	}
+	if r.telemetry != nil {
+		unregisters = append(unregisters, r.telemetry.Register(labels, fr.Metrics))
+	}
+
+	started := time.Now()
+	go func() {
+		err := loader.Run(ctx)
+		// Requests in flight are awaited by Run, so connections and capture file are closed after the last response.
+		loader.Close()
+		for _, unregister := range unregisters {
+			unregister()
+		}
+		if metricsPath != "" {
+			report := export.NewReport(req, fr.Metrics, started, time.Now())
+			// Log file may be already closed by stop, so error is shown in card.
+			if err := export.WriteFile(metricsPath, report); err != nil {
+				fyne.Do(func() { r.showError(fmt.Errorf("could not export metrics: %w", err)) })
+			}
+		}
+		if err != nil {
+			r.stopLoadingRequests(fr)
+			return
+		}
+	}()
+
+	return nil
+}
+
+// makeRequestParams make params for request from Form.
+func (r *RequestCard) makeRequestParams(fr *FormRequest) (*entity.RequestParams, error) {
 	rps, err := strconv.Atoi(fr.RPS.GetValue())
 	if err != nil {
-		return fmt.Errorf("could not parse rps: %w", err)
+		return nil, fmt.Errorf("could not parse rps: %w", err)
 	}
 
+	service, ok := fr.ParsedProto.FindServiceByName(fr.ServicesMethods.Services.Selected)
+	if !ok {
+		return nil, fmt.Errorf("could not find service with name %s", fr.ServicesMethods.Services.Selected)
+	}
 	req := &entity.RequestParams{
-		Service:  fr.ParsedProto.Package + "." + fr.ServicesMethods.Services.Selected,
+		Service:  service.FullName,
 		Method:   fr.ServicesMethods.Methods.Selected,
 		Message:  fr.ServicesMethods.MessageEntry.Text,
 		Metadata: fr.Metadata.MapString(),
@@ -292,32 +467,36 @@ func (r *RequestCard) startLoadingRequests(fr *FormRequest) error {
 		Host:     fr.Host.Text,
 		Proto:    fr.ParsedProto,
 	}
-	method, ok := req.Proto.FindMethodByName(fr.ServicesMethods.Services.Selected, req.Method)
+	if i := slices.Index(transports, fr.Transport.Selected); i > 0 {
+		req.Transport = entity.Transport(i)
+	}
+	method, ok := req.Proto.FindMethodByName(service.FullName, req.Method)
 	if !ok {
-		return fmt.Errorf("could not find method with name %s", req.Method)
+		return nil, fmt.Errorf("could not find method with name %s", req.Method)
 	}
 	req.MethodType = method.Type
+	if fr.Connection != nil {
+		req.TLS = fr.Connection.TLSParams()
+		req.Auth = fr.Connection.AuthParams()
+		req.Dial, err = fr.Connection.DialParams()
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	if fr.DeadlineReq.GetValue() != 0 {
 		req.RequestDeadline = ptr.ToPtr(fr.DeadlineReq.GetValue())
 	}
-
-	loader, err := r.loaderFactory.NewLoader(req, fr.Metrics)
+	req.Assertions, err = fr.Assertions.Params()
 	if err != nil {
-		return fmt.Errorf("could not create loader: %w", err)
+		return nil, err
+	}
+	req.Capture, err = fr.Capture.Params()
+	if err != nil {
+		return nil, err
 	}
 
-	ctx, cancel := context.WithCancel(ctx)
-	r.stopRequestsManager(fr, cancel)
-
-	go func() {
-		if err := loader.Run(ctx); err != nil {
-			r.stopLoadingRequests(fr)
-			return
-		}
-	}()
-
-	return nil
+	return req, nil
 }
 
 func (r *RequestCard) stopRequestsManager(fr *FormRequest, cancel context.CancelFunc) {
@@ -340,35 +519,73 @@ func (r *RequestCard) stopRequestsManager(fr *FormRequest, cancel context.Cancel
 
 // makeServicesMethods make services and methods for GUI form.
 func (r *RequestCard) makeServicesMethods(parsedProto *entity.ParsedProto) *ServicesMethods {
-	mapperUI := mapper.NewMapper(parsedProto)
-	protoMapUI := mapperUI.MakeProtoMapUI(parsedProto)
-	messageEntry := widget.NewMultiLineEntry()
-
-	optionsServices := protoMapUI.GetServicesNames()
-	methods := widget.NewSelect(nil, func(value string) {
-		msg, ok := protoMapUI.GetMessageByMethodName(value)
-		if !ok {
-			messageEntry.SetText("Example message not found")
-			return
-		}
-		exampleMessage := mapperUI.MakeExampleMessage(msg)
-		j, err := json.MarshalIndent(exampleMessage, "", "  ")
-		if err != nil {
-			messageEntry.SetText("Error marshalling exampleMessage")
-			return
-		}
-		messageEntry.SetText(string(j))
+	sm := &ServicesMethods{
+		MessageEntry: widget.NewMultiLineEntry(),
+	}
+	sm.setProto(parsedProto)
+
+	sm.Methods = widget.NewSelect(nil, func(value string) {
+		sm.setExampleMessage(sm.Services.Selected, value)
+	})
+	sm.ExampleKind = widget.NewSelect(exampleKinds, func(string) {
+		sm.setExampleMessage(sm.Services.Selected, sm.Methods.Selected)
+	})
+	sm.ExampleKind.Selected = exampleKindValid
+
+	sm.Services = widget.NewSelect(sm.protoMapUI.GetServicesNames(), func(value string) {
+		sm.Methods.Options = sm.protoMapUI.GetMethodsNamesByService(value)
+		sm.Methods.SetSelectedIndex(0)
 	})
+	sm.Services.SetSelectedIndex(0)
+
+	return sm
+}
 
-	services := widget.NewSelect(optionsServices, func(value string) {
-		methods.Options = protoMapUI.GetMethodsNamesByService(value)
-		methods.SetSelectedIndex(0)
+// refreshProto update services and methods after proto was changed. The card is flagged if selected method
+// or message are not valid for the new proto.
+func (r *RequestCard) refreshProto(parsedProto *entity.ParsedProto) {
+	r.Form.ParsedProto = parsedProto
+	err := r.Form.ServicesMethods.refresh(parsedProto)
+	if err == nil {
+		err = r.validateMessage(r.Form)
+	}
+	if err != nil {
+		r.protoWarning.SetText(fmt.Sprintf(protoChangedWarning, err))
+		r.protoWarning.Show()
+		return
+	}
+	r.protoWarning.Hide()
+}
+
+// validateMessage check message of form for current proto, nil is returned if card has no validator.
+func (r *RequestCard) validateMessage(fr *FormRequest) error {
+	if r.validator == nil {
+		return nil
+	}
+	service, ok := fr.ParsedProto.FindServiceByName(fr.ServicesMethods.Services.Selected)
+	if !ok {
+		return fmt.Errorf("could not find service with name %s", fr.ServicesMethods.Services.Selected)
+	}
+
+	problems := r.validator.ValidateMessage(&entity.RequestParams{
+		Service: service.FullName,
+		Method:  fr.ServicesMethods.Methods.Selected,
+		Message: fr.ServicesMethods.MessageEntry.Text,
+		Proto:   fr.ParsedProto,
 	})
-	services.SetSelectedIndex(0)
+	if len(problems) == 0 {
+		return nil
+	}
 
-	return &ServicesMethods{
-		Services:     services,
-		Methods:      methods,
-		MessageEntry: messageEntry,
+	return errors.New(formatProblems(problems))
+}
+
+// formatProblems format problems of validation with one problem per line.
+func formatProblems(problems []entity.ValidationProblem) string {
+	lines := make([]string, 0, len(problems))
+	for _, p := range problems {
+		lines = append(lines, p.String())
 	}
+
+	return strings.Join(lines, "\n")
 }
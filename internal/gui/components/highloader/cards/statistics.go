@@ -3,7 +3,10 @@ package cards
 import (
 	"context"
 	"fmt"
+	"maps"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
 	"fyne.io/fyne/v2"
@@ -15,26 +18,47 @@ import (
 )
 
 const (
-	zeroValue                             = "0"
-	scrapeInterval                        = 20 * time.Millisecond
-	labelStatisticsReqs                   = "~Req/s"
-	labelStatisticsTotalReqs              = "Total Requests"
-	labelStatisticsReqsOK                 = "OK"
-	labelStatisticsReqsUnknown            = "Unknown"
-	labelStatisticsReqsCancelled          = "Cancelled"
-	labelStatisticsReqsInvalidArgument    = "InvalidArgument"
-	labelStatisticsReqsDeadlineExceeded   = "DeadlineExceeded"
-	labelStatisticsReqsNotFound           = "NotFound"
-	labelStatisticsReqsAlreadyExists      = "AlreadyExists"
-	labelStatisticsReqsPermissionDenied   = "PermissionDenied"
-	labelStatisticsReqsResourceExhausted  = "ResourceExhausted"
-	labelStatisticsReqsFailedPrecondition = "FailedPrecondition"
-	labelStatisticsReqsAborted            = "Aborted"
-	labelStatisticsOutOfRange             = "OutOfRange"
-	labelStatisticsUnimplemented          = "Unimplemented"
-	labelStatisticsUnavailable            = "Unavailable"
-	labelStatisticsDataLoss               = "DataLoss"
-	labelStatisticsUnauthenticated        = "Unauthenticated"
+	zeroValue                = "0"
+	scrapeInterval           = 20 * time.Millisecond
+	labelStatisticsReqs      = "Req/s"
+	formatStatisticsReqs     = "%d / %d"
+	labelStatisticsInFlight  = "In flight"
+	labelStatisticsTotalReqs = "Total Requests"
+	labelStatisticsTransport = "Transport errors"
+	labelStatisticsMarshal   = "Marshal errors"
+	labelStatisticsDeadline  = "Deadline exceeded on client"
+	// statusesPerRow count of status codes in one row.
+	statusesPerRow             = 6
+	labelStatisticsBackends    = "Backends"
+	formatStatisticsBackend    = "%s: %d requests, %d errors, avg %s"
+	labelStatisticsConnection  = "Connection"
+	formatStatisticsConnection = "Transports: %d, reconnects: %d, disconnects: %d\n" +
+		"GOAWAY received: %d, transient failures: %d, handshake errors: %d\n" +
+		"Avg connect: %s, avg TLS handshake: %s"
+	labelStatisticsLatency       = "Latency:"
+	labelStatisticsWindowLatency = "Last second:"
+	labelStatisticsLatencyByCode = "Latency by status"
+	formatStatisticsLatency      = "min %s, mean %s, p50 %s, p90 %s, p95 %s, p99 %s, p99.9 %s, max %s"
+	labelStatisticsTopErrors     = "Top errors"
+	formatStatisticsTopError     = "%8d  %-18s  %s"
+	// maxTopErrors count of shown errors.
+	maxTopErrors               = 10
+	labelStatisticsThroughput  = "Throughput:"
+	formatStatisticsThroughput = "sent %.2f MB/s, received %.2f MB/s"
+	labelStatisticsPayload     = "Payload"
+	formatStatisticsPayload    = "Sent: %d B, on wire %d B\nReceived: %d B, on wire %d B\n" +
+		"Request size: %s\nResponse size: %s"
+	formatStatisticsSize = "mean %d B, p50 %d B, p90 %d B, p99 %d B, max %d B"
+	// latencyPrecision precision of shown latency.
+	latencyPrecision = time.Microsecond
+	bytesInMB        = 1e6
+	// labelStatisticsAssertion label of failed assertions in row of outcomes.
+	labelStatisticsAssertion  = "Assertion failures"
+	labelStatisticsAssertions = "Assertions"
+	formatStatisticsPassed    = "Passed: %d"
+	formatStatisticsFailed    = "%8d  %s"
+	formatStatisticsSample    = "%s  %s: %s"
+	labelStatisticsSamples    = "Samples:"
 )
 
 // statistics struct with metrics.
@@ -48,6 +72,16 @@ type statistics struct {
 // infoStat stat for showing in GUI.
 type infoStat struct {
 	reqPerSecond *widget.Label
+	inFlight     *widget.Label
+	window       *widget.Label
+	throughput   *widget.Label
+	backends     *widget.Label
+	connection   *widget.Label
+	latency      *widget.Label
+	latencyCodes *widget.Label
+	topErrors    *widget.Label
+	payload      *widget.Label
+	assertions   *widget.Label
 }
 
 // metricStat metric for showing in GUI.
@@ -78,130 +112,75 @@ func (s *statistics) makeLabels() *fyne.Container {
 
 	valueReqsPerSecond := widget.NewLabel(zeroValue)
 	labelReqsPerSecond := container.NewHBox(valueReqsPerSecond, widget.NewLabel(labelStatisticsReqs))
+	valueInFlight := widget.NewLabel(zeroValue)
+	labelInFlight := container.NewHBox(widget.NewLabel(labelStatisticsInFlight), valueInFlight)
 	info := &infoStat{
 		reqPerSecond: valueReqsPerSecond,
+		inFlight:     valueInFlight,
 	}
 
-	mainLabel := container.NewHBox(labelTotalReqs, utils.NewLine(), labelReqsPerSecond)
+	mainLabel := container.NewHBox(labelTotalReqs, utils.NewLine(), labelReqsPerSecond, utils.NewLine(), labelInFlight)
 
-	valueOK := widget.NewLabel(zeroValue)
-	labelOK := container.NewHBox(widget.NewLabel(labelStatisticsReqsOK+":"), valueOK)
-	statsOK := &metricStat{
-		value:  valueOK,
-		metric: s.Metrics.ResponseStatusOKCounter,
-	}
-	valueUnknown := widget.NewLabel(zeroValue)
-	labelUnknown := container.NewHBox(widget.NewLabel(labelStatisticsReqsUnknown+":"), valueUnknown)
-	statsUnknown := &metricStat{
-		value:  valueUnknown,
-		metric: s.Metrics.ResponseStatusUnknownCounter,
-	}
-	valueCancelled := widget.NewLabel(zeroValue)
-	labelCancelled := container.NewHBox(widget.NewLabel(labelStatisticsReqsCancelled+":"), valueCancelled)
-	statsCancelled := &metricStat{
-		value:  valueCancelled,
-		metric: s.Metrics.ResponseStatusCancelledCounter,
-	}
-	valueInvalidArgument := widget.NewLabel(zeroValue)
-	labelInvalidArgument := container.NewHBox(widget.NewLabel(labelStatisticsReqsInvalidArgument+":"),
-		valueInvalidArgument)
-	statsInvalidArgument := &metricStat{
-		value:  valueInvalidArgument,
-		metric: s.Metrics.ResponseStatusInvalidArgumentCounter,
-	}
-	valueDeadlineExceeded := widget.NewLabel(zeroValue)
-	labelDeadlineExceeded := container.NewHBox(widget.NewLabel(labelStatisticsReqsDeadlineExceeded+":"),
-		valueDeadlineExceeded)
-	statsDeadlineExceeded := &metricStat{
-		value:  valueDeadlineExceeded,
-		metric: s.Metrics.ResponseStatusDeadlineExceededCounter,
-	}
-	valueNotFound := widget.NewLabel(zeroValue)
-	labelNotFound := container.NewHBox(widget.NewLabel(labelStatisticsReqsNotFound+":"), valueNotFound)
-	statsNotFound := &metricStat{
-		value:  valueNotFound,
-		metric: s.Metrics.ResponseStatusNotFoundCounter,
+	stats := []*metricStat{statsTotalReqs}
+	counters := s.Metrics.Statuses.Snapshot()
+	rows := container.NewVBox()
+	var row *fyne.Container
+	for i, code := range slices.Sorted(maps.Keys(counters)) {
+		if i%statusesPerRow == 0 {
+			row = container.NewHBox()
+			rows.Add(row)
+		}
+		value := widget.NewLabel(zeroValue)
+		row.Add(container.NewHBox(widget.NewLabel(code.String()+":"), value))
+		stats = append(stats, &metricStat{
+			value:  value,
+			metric: s.Metrics.Statuses.Get(code),
+		})
 	}
-	rowOne := container.NewHBox(labelOK, labelUnknown, labelCancelled, labelInvalidArgument, labelDeadlineExceeded,
-		labelNotFound)
 
-	valueAlreadyExists := widget.NewLabel(zeroValue)
-	labelAlreadyExists := container.NewHBox(widget.NewLabel(labelStatisticsReqsAlreadyExists+":"), valueAlreadyExists)
-	statsAlreadyExists := &metricStat{
-		value:  valueAlreadyExists,
-		metric: s.Metrics.ResponseStatusAlreadyExistsCounter,
-	}
-	valuePermissionDenied := widget.NewLabel(zeroValue)
-	labelPermissionDenied := container.NewHBox(widget.NewLabel(labelStatisticsReqsPermissionDenied+":"),
-		valuePermissionDenied)
-	statsPermissionDenied := &metricStat{
-		value:  valuePermissionDenied,
-		metric: s.Metrics.ResponseStatusPermissionDeniedCounter,
-	}
-	valueResourceExhausted := widget.NewLabel(zeroValue)
-	labelResourceExhausted := container.NewHBox(widget.NewLabel(labelStatisticsReqsResourceExhausted+":"),
-		valueResourceExhausted)
-	statsResourceExhausted := &metricStat{
-		value:  valueResourceExhausted,
-		metric: s.Metrics.ResponseStatusResourceExhaustedCounter,
-	}
-	valueFailedPrecondition := widget.NewLabel(zeroValue)
-	labelFailedPrecondition := container.NewHBox(widget.NewLabel(labelStatisticsReqsFailedPrecondition+":"),
-		valueFailedPrecondition)
-	statsFailedPrecondition := &metricStat{
-		value:  valueFailedPrecondition,
-		metric: s.Metrics.ResponseStatusFailedPreconditionCounter,
-	}
-	valueAborted := widget.NewLabel("0")
-	labelAborted := container.NewHBox(widget.NewLabel(labelStatisticsReqsAborted+":"), valueAborted)
-	statsAborted := &metricStat{
-		value:  valueAborted,
-		metric: s.Metrics.ResponseStatusAbortedCounter,
+	// Attempts without response status are shown separately, so all counts sum up to total requests.
+	outcomesRow := container.NewHBox()
+	for _, o := range []struct {
+		label   string
+		outcome metrics.Outcome
+	}{
+		{label: labelStatisticsTransport, outcome: metrics.OutcomeTransport},
+		{label: labelStatisticsMarshal, outcome: metrics.OutcomeMarshal},
+		{label: labelStatisticsDeadline, outcome: metrics.OutcomeDeadline},
+		{label: labelStatisticsAssertion, outcome: metrics.OutcomeAssertion},
+	} {
+		value := widget.NewLabel(zeroValue)
+		outcomesRow.Add(container.NewHBox(widget.NewLabel(o.label+":"), value))
+		stats = append(stats, &metricStat{
+			value:  value,
+			metric: s.Metrics.Outcomes.Get(o.outcome),
+		})
 	}
-	rowTwo := container.NewHBox(labelAlreadyExists, labelPermissionDenied, labelResourceExhausted,
-		labelFailedPrecondition, labelAborted)
+	rows.Add(outcomesRow)
 
-	valueOutOfRange := widget.NewLabel(zeroValue)
-	labelOutOfRange := container.NewHBox(widget.NewLabel(labelStatisticsOutOfRange+":"), valueOutOfRange)
-	statsOutOfRange := &metricStat{
-		value:  valueOutOfRange,
-		metric: s.Metrics.ResponseStatusOutOfRangeCounter,
-	}
-	valueUnimplemented := widget.NewLabel(zeroValue)
-	labelUnimplemented := container.NewHBox(widget.NewLabel(labelStatisticsUnimplemented+":"), valueUnimplemented)
-	statsUnimplemented := &metricStat{
-		value:  valueUnimplemented,
-		metric: s.Metrics.ResponseStatusUnimplementedCounter,
-	}
-	valueUnavailable := widget.NewLabel(zeroValue)
-	labelUnavailable := container.NewHBox(widget.NewLabel(labelStatisticsUnavailable+":"), valueUnavailable)
-	statsUnavailable := &metricStat{
-		value:  valueUnavailable,
-		metric: s.Metrics.ResponseStatusUnavailableCounter,
-	}
-	valueDataLoss := widget.NewLabel(zeroValue)
-	labelDataLoss := container.NewHBox(widget.NewLabel(labelStatisticsDataLoss+":"), valueDataLoss)
-	statsDataLoss := &metricStat{
-		value:  valueDataLoss,
-		metric: s.Metrics.ResponseStatusDataLossCounter,
-	}
-	valueUnauthenticated := widget.NewLabel(zeroValue)
-	labelUnauthenticated := container.NewHBox(widget.NewLabel(labelStatisticsUnauthenticated+":"),
-		valueUnauthenticated)
-	statsUnauthenticated := &metricStat{
-		value:  valueUnauthenticated,
-		metric: s.Metrics.ResponseStatusUnauthenticatedCounter,
-	}
-	rowThree := container.NewHBox(labelOutOfRange, labelUnimplemented,
-		labelUnavailable, labelDataLoss, labelUnauthenticated)
+	info.latency = widget.NewLabel("")
+	info.window = widget.NewLabel("")
+	info.throughput = widget.NewLabel("")
+	latencyLabel := container.NewVBox(
+		container.NewHBox(widget.NewLabel(labelStatisticsLatency), info.latency),
+		container.NewHBox(widget.NewLabel(labelStatisticsWindowLatency), info.window),
+		container.NewHBox(widget.NewLabel(labelStatisticsThroughput), info.throughput))
+	info.backends = widget.NewLabel("")
+	info.connection = widget.NewLabel("")
+	info.latencyCodes = widget.NewLabel("")
+	info.payload = widget.NewLabel("")
+	info.topErrors = widget.NewLabelWithStyle("", fyne.TextAlignLeading, fyne.TextStyle{Monospace: true})
+	info.assertions = widget.NewLabelWithStyle("", fyne.TextAlignLeading, fyne.TextStyle{Monospace: true})
+	backendsBox := widget.NewAccordion(widget.NewAccordionItem(labelStatisticsBackends, info.backends),
+		widget.NewAccordionItem(labelStatisticsConnection, info.connection),
+		widget.NewAccordionItem(labelStatisticsLatencyByCode, info.latencyCodes),
+		widget.NewAccordionItem(labelStatisticsPayload, info.payload),
+		widget.NewAccordionItem(labelStatisticsTopErrors, info.topErrors),
+		widget.NewAccordionItem(labelStatisticsAssertions, info.assertions))
 
-	stats := []*metricStat{statsTotalReqs, statsOK, statsUnknown, statsCancelled, statsInvalidArgument,
-		statsDeadlineExceeded, statsNotFound, statsAlreadyExists, statsPermissionDenied, statsResourceExhausted,
-		statsFailedPrecondition, statsAborted, statsOutOfRange, statsUnimplemented, statsUnavailable, statsDataLoss,
-		statsUnauthenticated}
 	s.stats = stats
 	s.info = info
-	box := container.NewVBox(mainLabel, utils.NewLine(), rowOne, rowTwo, rowThree)
+	box := container.NewVBox(mainLabel, utils.NewLine(), rows, latencyLabel, backendsBox)
 	return box
 }
 
@@ -227,9 +206,8 @@ func (s *statistics) showStats(ctx context.Context) {
 	}
 }
 
-// showStats show info in GUI.
+// showInfo show info of the last second of load in GUI.
 func (s *statistics) showInfo(ctx context.Context) {
-	startTime := time.Now()
 	ticker := time.NewTicker(1 * time.Second)
 	defer ticker.Stop()
 
@@ -238,17 +216,18 @@ func (s *statistics) showInfo(ctx context.Context) {
 		case <-ctx.Done():
 			return
 		case <-ticker.C:
-			elapsed := time.Since(startTime).Seconds()
-			if elapsed == 0 {
-				return
+			p, ok := s.Metrics.Series.Last()
+			if !ok {
+				continue
 			}
-			count := s.Metrics.RequestCounter.Value.Load()
-			value := float64(count) / elapsed
 			fyne.Do(func() {
-				s.info.reqPerSecond.SetText(fmt.Sprintf("%f", value))
-				s.info.reqPerSecond.Refresh()
+				s.info.reqPerSecond.SetText(fmt.Sprintf(formatStatisticsReqs, p.RPS, p.TargetRPS))
+				s.info.inFlight.SetText(strconv.FormatInt(p.InFlight, 10))
+				s.info.window.SetText(formatLatency(p.Latency))
+				s.info.throughput.SetText(fmt.Sprintf(formatStatisticsThroughput,
+					float64(p.SentBytes)/bytesInMB, float64(p.ReceivedBytes)/bytesInMB))
+				s.box.Refresh()
 			})
-			return
 		}
 	}
 }
@@ -258,6 +237,54 @@ func (s *statistics) setValues() {
 	for _, stat := range s.stats {
 		stat.value.SetText(strconv.FormatInt(stat.metric.Value.Load(), 10))
 	}
+
+	backends := s.Metrics.Backends.Snapshot()
+	lines := make([]string, 0, len(backends))
+	for _, b := range backends {
+		lines = append(lines, fmt.Sprintf(formatStatisticsBackend, b.Address, b.Requests, b.Errors, b.AvgLatency))
+	}
+	s.info.backends.SetText(strings.Join(lines, "\n"))
+
+	c := s.Metrics.Connection.Snapshot()
+	s.info.connection.SetText(fmt.Sprintf(formatStatisticsConnection, c.Transports, c.Reconnects, c.Disconnects,
+		c.GoAways, c.TransientFailures, c.HandshakeErrors, c.AvgConnectLatency, c.AvgHandshakeLatency))
+
+	s.info.latency.SetText(formatLatency(s.Metrics.Latency.Snapshot()))
+	byCode := s.Metrics.Latency.ByCode()
+	lines = make([]string, 0, len(byCode))
+	for _, code := range slices.Sorted(maps.Keys(byCode)) {
+		lines = append(lines, fmt.Sprintf("%s: %s", code, formatLatency(byCode[code])))
+	}
+	s.info.latencyCodes.SetText(strings.Join(lines, "\n"))
+
+	p := s.Metrics.Payload.Snapshot()
+	s.info.payload.SetText(fmt.Sprintf(formatStatisticsPayload, p.Sent, p.WireSent, p.Received, p.WireReceived,
+		formatSize(p.SentSizes), formatSize(p.ReceivedSizes)))
+
+	errs := s.Metrics.TopErrors(maxTopErrors)
+	lines = make([]string, 0, len(errs))
+	for _, e := range errs {
+		text := e.Message
+		if e.Detail != "" {
+			text += " (" + e.Detail + ")"
+		}
+		lines = append(lines, fmt.Sprintf(formatStatisticsTopError, e.Count, e.Code, text))
+	}
+	s.info.topErrors.SetText(strings.Join(lines, "\n"))
+
+	a := s.Metrics.Assertions.Snapshot()
+	lines = []string{fmt.Sprintf(formatStatisticsPassed, a.Passed)}
+	for _, f := range a.Failed {
+		lines = append(lines, fmt.Sprintf(formatStatisticsFailed, f.Count, f.Assertion))
+	}
+	if len(a.Samples) != 0 {
+		lines = append(lines, labelStatisticsSamples)
+	}
+	for _, sample := range a.Samples {
+		lines = append(lines, fmt.Sprintf(formatStatisticsSample, sample.Time.Format(time.TimeOnly), sample.Assertion,
+			sample.Message))
+	}
+	s.info.assertions.SetText(strings.Join(lines, "\n"))
 }
 
 // resetValues reset values in stats.
@@ -265,4 +292,27 @@ func (s *statistics) resetValues() {
 	for _, stat := range s.stats {
 		stat.value.SetText(zeroValue)
 	}
+	s.info.backends.SetText("")
+	s.info.connection.SetText("")
+	s.info.latency.SetText("")
+	s.info.latencyCodes.SetText("")
+	s.info.topErrors.SetText("")
+	s.info.payload.SetText("")
+	s.info.assertions.SetText("")
+	s.info.throughput.SetText("")
+	s.info.reqPerSecond.SetText(zeroValue)
+	s.info.inFlight.SetText(zeroValue)
+	s.info.window.SetText("")
+}
+
+// formatLatency format latency stats for GUI.
+func formatLatency(l metrics.LatencyStat) string {
+	return fmt.Sprintf(formatStatisticsLatency, l.Min.Round(latencyPrecision), l.Mean.Round(latencyPrecision),
+		l.P50.Round(latencyPrecision), l.P90.Round(latencyPrecision), l.P95.Round(latencyPrecision),
+		l.P99.Round(latencyPrecision), l.P999.Round(latencyPrecision), l.Max.Round(latencyPrecision))
+}
+
+// formatSize format size stats for GUI.
+func formatSize(s metrics.SizeStat) string {
+	return fmt.Sprintf(formatStatisticsSize, s.Mean, s.P50, s.P90, s.P99, s.Max)
 }
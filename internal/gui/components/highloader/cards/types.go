@@ -2,12 +2,14 @@ package cards
 
 import (
 	"encoding/json"
+	"fmt"
 	"slices"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/widget"
 
 	"github.com/AndreyNiki/grpc-highloader/internal/entity"
+	"github.com/AndreyNiki/grpc-highloader/internal/gui/components/highloader/mapper"
 	"github.com/AndreyNiki/grpc-highloader/internal/gui/interfaces"
 	"github.com/AndreyNiki/grpc-highloader/internal/gui/utils"
 	"github.com/AndreyNiki/grpc-highloader/internal/logger"
@@ -45,8 +47,13 @@ func (c *Cards[T]) Remove(id int) {
 type ContainerCards struct {
 	Parent        *fyne.Container
 	Host          *widget.Entry
+	Connection    *ConnectionSettings
 	Proto         *entity.ParsedProto
 	LoaderFactory interfaces.LoaderFactory
+	Watcher       interfaces.Watcher
+	Validator     interfaces.Validator
+	Exporter      interfaces.MetricsExporter
+	Telemetry     interfaces.TelemetryExporter
 }
 
 // FormRequest form with info from GUI.
@@ -57,21 +64,97 @@ type FormRequest struct {
 	RPS             *utils.Entry
 	StopAfter       *utils.EntryTime
 	DeadlineReq     *utils.EntryTime
+	Transport       *widget.Select
 	ServicesMethods *ServicesMethods
 	Metadata        *Metadata
+	Assertions      *Assertions
+	Capture         *Capture
 	TimeTrackerCh   chan struct{}
 	CancelCh        chan struct{}
 	ButtonRemove    *widget.Button
 	Host            *widget.Entry
+	Connection      *ConnectionSettings
 	Metrics         *metrics.Metrics
 	ParsedProto     *entity.ParsedProto
 }
 
+// Kinds of example message.
+const (
+	exampleKindValid   = "Valid example"
+	exampleKindInvalid = "Invalid example"
+	exampleKindRandom  = "Random template"
+)
+
+// exampleKinds options for select of example kind.
+var exampleKinds = []string{exampleKindValid, exampleKindInvalid, exampleKindRandom}
+
 // ServicesMethods struct with services and method. Also stored message for method.
 type ServicesMethods struct {
 	Services     *widget.Select
 	Methods      *widget.Select
+	ExampleKind  *widget.Select
 	MessageEntry *widget.Entry
+	mapper       *mapper.Mapper
+	protoMapUI   *mapper.ProtoMapUI
+}
+
+// setExampleMessage set example message of selected kind for method.
+func (s *ServicesMethods) setExampleMessage(service, method string) {
+	msg, ok := s.protoMapUI.GetMessageByMethodName(service, method)
+	if !ok {
+		s.MessageEntry.SetText("Example message not found")
+		return
+	}
+
+	var exampleMessage *mapper.ExampleMessage
+	switch s.ExampleKind.Selected {
+	case exampleKindRandom:
+		tmpl, err := s.mapper.MakeRandomMessageTemplate(msg)
+		if err != nil {
+			s.MessageEntry.SetText("Error making random template")
+			return
+		}
+		s.MessageEntry.SetText(tmpl)
+		return
+	case exampleKindInvalid:
+		exampleMessage = s.mapper.MakeInvalidExampleMessage(msg)
+	default:
+		exampleMessage = s.mapper.MakeExampleMessage(msg)
+	}
+
+	j, err := json.MarshalIndent(exampleMessage, "", "  ")
+	if err != nil {
+		s.MessageEntry.SetText("Error marshalling exampleMessage")
+		return
+	}
+	s.MessageEntry.SetText(string(j))
+}
+
+// setProto set proto for making services, methods and example messages.
+func (s *ServicesMethods) setProto(parsedProto *entity.ParsedProto) {
+	s.mapper = mapper.NewMapper(parsedProto)
+	s.protoMapUI = s.mapper.MakeProtoMapUI(parsedProto)
+}
+
+// refresh options of services and methods by changed proto, selected values and message are kept.
+// Returns error if selected service or method is not found in changed proto.
+func (s *ServicesMethods) refresh(parsedProto *entity.ParsedProto) error {
+	s.setProto(parsedProto)
+	service, method := s.Services.Selected, s.Methods.Selected
+
+	s.Services.Options = s.protoMapUI.GetServicesNames()
+	s.Methods.Options = s.protoMapUI.GetMethodsNamesByService(service)
+	s.Services.Refresh()
+	s.Methods.Refresh()
+
+	if !slices.Contains(s.Services.Options, service) {
+		return fmt.Errorf("service %q not found", service)
+	}
+	if _, ok := s.protoMapUI.GetMessageByMethodName(service, method); !ok {
+		return fmt.Errorf("method %q not found in service %q", method, service)
+	}
+
+	return nil
 }
 
 // preset values in form GUI.
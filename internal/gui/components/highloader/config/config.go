@@ -2,8 +2,70 @@ package config
 
 // PreloadConfig struct for presetting form from config.
 type PreloadConfig struct {
-	Host  string  `json:"host"`
-	Proto []Proto `json:"proto"`
+	Host  string         `json:"host"`
+	Hosts []HostSettings `json:"hosts,omitempty"`
+	Proto []Proto        `json:"proto"`
+	// Prometheus listen address of metrics listener.
+	Prometheus string     `json:"prometheus,omitempty"`
+	Telemetry  *Telemetry `json:"telemetry,omitempty"`
+}
+
+// Telemetry struct with settings of OpenTelemetry export.
+type Telemetry struct {
+	Endpoint    string `json:"endpoint"`
+	Protocol    string `json:"protocol"`
+	SampleRatio string `json:"sample_ratio,omitempty"`
+}
+
+// HostSettings struct with connection settings of one host.
+type HostSettings struct {
+	Host string `json:"host"`
+	TLS  TLS    `json:"tls"`
+	Auth Auth   `json:"auth"`
+	Dial Dial   `json:"dial"`
+}
+
+// TLS struct with TLS settings of connection.
+type TLS struct {
+	Enabled            bool   `json:"enabled"`
+	CAFile             string `json:"ca_file"`
+	CertFile           string `json:"cert_file"`
+	KeyFile            string `json:"key_file"`
+	ServerName         string `json:"server_name"`
+	InsecureSkipVerify bool   `json:"insecure_skip_verify"`
+}
+
+// Auth struct with per-call authentication settings of connection.
+type Auth struct {
+	Type         string   `json:"type"`
+	Token        string   `json:"token"`
+	TokenURL     string   `json:"token_url"`
+	ClientID     string   `json:"client_id"`
+	ClientSecret string   `json:"client_secret"`
+	Scopes       []string `json:"scopes"`
+	Command      string   `json:"command"`
+}
+
+// Dial struct with dial options of connection. Durations are in format of time.ParseDuration,
+// sizes are in bytes with optional unit suffix, e.g. "16MB".
+type Dial struct {
+	KeepaliveTime                string `json:"keepalive_time"`
+	KeepaliveTimeout             string `json:"keepalive_timeout"`
+	KeepalivePermitWithoutStream bool   `json:"keepalive_permit_without_stream"`
+	MaxSendMsgSize               string `json:"max_send_msg_size"`
+	MaxRecvMsgSize               string `json:"max_recv_msg_size"`
+	Compression                  string `json:"compression"`
+	InitialWindowSize            string `json:"initial_window_size"`
+	InitialConnWindowSize        string `json:"initial_conn_window_size"`
+	UserAgent                    string `json:"user_agent"`
+	Block                        bool   `json:"block"`
+	ConnectTimeout               string `json:"connect_timeout"`
+	BalancingPolicy              string `json:"balancing_policy"`
+	ResolveInterval              string `json:"resolve_interval"`
+	ChurnRequests                string `json:"churn_requests,omitempty"`
+	ChurnInterval                string `json:"churn_interval,omitempty"`
+	Proxy                        string `json:"proxy,omitempty"`
+	Authority                    string `json:"authority,omitempty"`
 }
 
 // Proto struct with info one proto file.
@@ -22,7 +84,37 @@ type Request struct {
 	Service         string     `json:"service"`
 	Method          string     `json:"method"`
 	RPS             string     `json:"rps"`
+	Transport       string     `json:"transport,omitempty"`
 	Metadata        []MetaData `json:"metadata"`
+	// Assertions of responses, nil if responses are not checked.
+	Assertions *Assertions `json:"assertions,omitempty"`
+	// Capture of sampled responses, nil if responses are not captured.
+	Capture *Capture `json:"capture,omitempty"`
+}
+
+// Capture struct with capture of sampled responses to JSONL file.
+type Capture struct {
+	Path        string `json:"path"`
+	SampleRate  string `json:"sample_rate,omitempty"`
+	FirstErrors string `json:"first_errors,omitempty"`
+}
+
+// Assertions struct with assertions of responses. Code is name of status code, e.g. "NotFound",
+// max latency is in format of time.ParseDuration.
+type Assertions struct {
+	Code       string           `json:"code,omitempty"`
+	MaxLatency string           `json:"max_latency,omitempty"`
+	Headers    []string         `json:"headers,omitempty"`
+	Trailers   []string         `json:"trailers,omitempty"`
+	Fields     []FieldAssertion `json:"fields,omitempty"`
+}
+
+// FieldAssertion struct with assertion of response field. Op is "equals", "contains", "matches" or "in range",
+// value of range is in format "min..max" with optional bounds.
+type FieldAssertion struct {
+	Path  string `json:"path"`
+	Op    string `json:"op"`
+	Value string `json:"value"`
 }
 
 // MetaData struct with metadata for request.
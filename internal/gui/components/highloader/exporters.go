@@ -0,0 +1,174 @@
+package highloader
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+
+	"github.com/AndreyNiki/grpc-highloader/internal/entity"
+	"github.com/AndreyNiki/grpc-highloader/internal/gui/components/highloader/config"
+)
+
+const (
+	labelPrometheusName    = "Prometheus"
+	placeholderPrometheus  = "listen address of /metrics, e.g. :9090"
+	checkPrometheusName    = "Serve metrics"
+	labelTelemetryName     = "OpenTelemetry"
+	placeholderTelemetry   = "OTLP endpoint, e.g. localhost:4317 or https://collector:4318"
+	placeholderSampleRatio = "span sample ratio 0..1, empty disables spans"
+	checkTelemetryName     = "Export"
+	telemetryProtocolGRPC  = "gRPC"
+	telemetryProtocolHTTP  = "HTTP"
+)
+
+// telemetryProtocols options for select of protocol in order of entity.TelemetryProtocol.
+var telemetryProtocols = []string{telemetryProtocolGRPC, telemetryProtocolHTTP}
+
+// prometheusListener controls of listener of Prometheus metrics.
+type prometheusListener struct {
+	content *fyne.Container
+	address *widget.Entry
+}
+
+// makePrometheusListener make controls which start and stop listener of Prometheus metrics.
+func (h *HighLoader) makePrometheusListener() *prometheusListener {
+	address := widget.NewEntry()
+	address.SetPlaceHolder(placeholderPrometheus)
+	var stop func()
+	check := widget.NewCheck(checkPrometheusName, nil)
+	check.OnChanged = func(checked bool) {
+		if !checked {
+			if stop != nil {
+				stop()
+				stop = nil
+			}
+			address.Enable()
+			return
+		}
+
+		var err error
+		stop, err = h.exporter.Listen(address.Text)
+		if err != nil {
+			dialog.ShowError(err, h.window)
+			check.SetChecked(false)
+			return
+		}
+		address.Disable()
+	}
+	if h.exporter == nil {
+		check.Disable()
+	}
+
+	content := container.NewBorder(nil, nil, widget.NewLabel(labelPrometheusName), check, address)
+	return &prometheusListener{content: content, address: address}
+}
+
+// telemetryExport controls of OpenTelemetry export.
+type telemetryExport struct {
+	content     *fyne.Container
+	endpoint    *widget.Entry
+	protocol    *widget.Select
+	sampleRatio *widget.Entry
+}
+
+// makeTelemetryExport make controls which start and stop OpenTelemetry export.
+func (h *HighLoader) makeTelemetryExport() *telemetryExport {
+	t := &telemetryExport{
+		endpoint:    widget.NewEntry(),
+		protocol:    widget.NewSelect(telemetryProtocols, nil),
+		sampleRatio: widget.NewEntry(),
+	}
+	t.endpoint.SetPlaceHolder(placeholderTelemetry)
+	t.protocol.SetSelected(telemetryProtocolGRPC)
+	t.sampleRatio.SetPlaceHolder(placeholderSampleRatio)
+
+	var stop func()
+	check := widget.NewCheck(checkTelemetryName, nil)
+	check.OnChanged = func(checked bool) {
+		if !checked {
+			if stop != nil {
+				stop()
+				stop = nil
+			}
+			t.setEnabled(true)
+			return
+		}
+
+		params, err := t.params()
+		if err == nil {
+			stop, err = h.telemetry.Start(params)
+		}
+		if err != nil {
+			dialog.ShowError(err, h.window)
+			check.SetChecked(false)
+			return
+		}
+		t.setEnabled(false)
+	}
+	if h.telemetry == nil {
+		check.Disable()
+	}
+
+	settings := container.NewGridWithColumns(2, t.protocol, t.sampleRatio)
+	t.content = container.NewBorder(nil, nil, widget.NewLabel(labelTelemetryName), check,
+		container.NewGridWithColumns(2, t.endpoint, settings))
+	return t
+}
+
+// params make params of export from controls.
+func (t *telemetryExport) params() (entity.TelemetryParams, error) {
+	params := entity.TelemetryParams{Endpoint: t.endpoint.Text}
+	if i := slices.Index(telemetryProtocols, t.protocol.Selected); i > 0 {
+		params.Protocol = entity.TelemetryProtocol(i)
+	}
+	if t.sampleRatio.Text != "" {
+		ratio, err := strconv.ParseFloat(t.sampleRatio.Text, 64)
+		if err != nil || ratio < 0 || ratio > 1 {
+			return params, fmt.Errorf("sample ratio must be a number from 0 to 1")
+		}
+		params.SampleRatio = ratio
+	}
+
+	return params, nil
+}
+
+// setEnabled enable or disable controls of settings.
+func (t *telemetryExport) setEnabled(enabled bool) {
+	for _, w := range []fyne.Disableable{t.endpoint, t.protocol, t.sampleRatio} {
+		if enabled {
+			w.Enable()
+		} else {
+			w.Disable()
+		}
+	}
+}
+
+// load settings from config.
+func (t *telemetryExport) load(c *config.Telemetry) {
+	if c == nil {
+		return
+	}
+	t.endpoint.SetText(c.Endpoint)
+	if slices.Contains(telemetryProtocols, c.Protocol) {
+		t.protocol.SetSelected(c.Protocol)
+	}
+	t.sampleRatio.SetText(c.SampleRatio)
+}
+
+// save settings to config, nil is returned if endpoint is not set.
+func (t *telemetryExport) save() *config.Telemetry {
+	if t.endpoint.Text == "" {
+		return nil
+	}
+
+	return &config.Telemetry{
+		Endpoint:    t.endpoint.Text,
+		Protocol:    t.protocol.Selected,
+		SampleRatio: t.sampleRatio.Text,
+	}
+}
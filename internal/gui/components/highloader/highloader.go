@@ -23,6 +23,7 @@ const (
 	buttonOpenConfigName  = "Open Config"
 	buttonSaveConfigName  = "Save Config"
 	labelHostName         = "Host"
+	placeholderHost       = "host:port, dns:///host:port, unix:///path.sock or backends host:port=weight,host:port"
 )
 
 // HighLoader struct for init highloader component.
@@ -30,14 +31,30 @@ type HighLoader struct {
 	window        fyne.Window
 	loaderFactory interfaces.LoaderFactory
 	parser        interfaces.Parser
+	watcher       interfaces.Watcher
+	validator     interfaces.Validator
+	exporter      interfaces.MetricsExporter
+	telemetry     interfaces.TelemetryExporter
 }
 
 // New create HighLoader.
-func New(w fyne.Window, loaderFactory interfaces.LoaderFactory, parser interfaces.Parser) *HighLoader {
+func New(
+	w fyne.Window,
+	loaderFactory interfaces.LoaderFactory,
+	parser interfaces.Parser,
+	watcher interfaces.Watcher,
+	validator interfaces.Validator,
+	exporter interfaces.MetricsExporter,
+	telemetry interfaces.TelemetryExporter,
+) *HighLoader {
 	return &HighLoader{
 		window:        w,
 		loaderFactory: loaderFactory,
 		parser:        parser,
+		watcher:       watcher,
+		validator:     validator,
+		exporter:      exporter,
+		telemetry:     telemetry,
 	}
 }
 
@@ -45,7 +62,12 @@ func New(w fyne.Window, loaderFactory interfaces.LoaderFactory, parser interface
 func (h *HighLoader) InitComponent() fyne.CanvasObject {
 	box := container.NewVBox()
 
+	connection := cards.NewConnectionSettings()
 	lineEntryHost := widget.NewEntry()
+	lineEntryHost.SetPlaceHolder(placeholderHost)
+	lineEntryHost.OnChanged = connection.SwitchHost
+	prometheus := h.makePrometheusListener()
+	telemetry := h.makeTelemetryExport()
 	var currentErr *guierrs.GUIError
 	protoCardHolder := cards.NewProtoCardsHolder()
 	buttonUploadProto := widget.NewButton(buttonUploadProtoName, func() {
@@ -74,6 +96,11 @@ func (h *HighLoader) InitComponent() fyne.CanvasObject {
 					Proto:         parsedProto,
 					LoaderFactory: h.loaderFactory,
 					Host:          lineEntryHost,
+					Connection:    connection,
+					Watcher:       h.watcher,
+					Validator:     h.validator,
+					Exporter:      h.exporter,
+					Telemetry:     h.telemetry,
 				}
 				protoCardHolder.Add(c, nil)
 			}
@@ -101,6 +128,9 @@ func (h *HighLoader) InitComponent() fyne.CanvasObject {
 					return
 				}
 				lineEntryHost.SetText(preloadConfig.Host)
+				prometheus.address.SetText(preloadConfig.Prometheus)
+				telemetry.load(preloadConfig.Telemetry)
+				connection.Load(preloadConfig.Hosts, preloadConfig.Host)
 				for _, p := range preloadConfig.Proto {
 					parsedProto, err := h.parser.ParseProto(p.FilePath)
 					if err != nil {
@@ -114,6 +144,11 @@ func (h *HighLoader) InitComponent() fyne.CanvasObject {
 						Proto:         parsedProto,
 						LoaderFactory: h.loaderFactory,
 						Host:          lineEntryHost,
+						Connection:    connection,
+						Watcher:       h.watcher,
+						Validator:     h.validator,
+						Exporter:      h.exporter,
+						Telemetry:     h.telemetry,
 					}
 					protoCardHolder.Add(c, &p)
 				}
@@ -137,9 +172,10 @@ func (h *HighLoader) InitComponent() fyne.CanvasObject {
 						Duration: req.Form.DeadlineReq.Entry.Value.Text,
 						Type:     req.Form.DeadlineReq.Select.Selected,
 					},
-					Service: req.Form.ServicesMethods.Services.Selected,
-					Method:  req.Form.ServicesMethods.Methods.Selected,
-					RPS:     req.Form.RPS.Value.Text,
+					Service:   req.Form.ServicesMethods.Services.Selected,
+					Method:    req.Form.ServicesMethods.Methods.Selected,
+					RPS:       req.Form.RPS.Value.Text,
+					Transport: req.Form.Transport.Selected,
 				}
 
 				var metadata []config.MetaData
@@ -151,6 +187,8 @@ func (h *HighLoader) InitComponent() fyne.CanvasObject {
 					metadata = append(metadata, md)
 				}
 				r.Metadata = metadata
+				r.Assertions = req.Form.Assertions.Save()
+				r.Capture = req.Form.Capture.Save()
 				requests = append(requests, r)
 			}
 
@@ -162,8 +200,11 @@ func (h *HighLoader) InitComponent() fyne.CanvasObject {
 		}
 
 		cfg := config.PreloadConfig{
-			Host:  lineEntryHost.Text,
-			Proto: proto,
+			Host:       lineEntryHost.Text,
+			Hosts:      connection.Save(),
+			Proto:      proto,
+			Prometheus: prometheus.address.Text,
+			Telemetry:  telemetry.save(),
 		}
 
 		dialog.ShowFileSave(func(writer fyne.URIWriteCloser, err error) {
@@ -189,6 +230,7 @@ func (h *HighLoader) InitComponent() fyne.CanvasObject {
 	buttonOpenConfig.Importance = widget.WarningImportance
 	buttonBox := container.NewGridWithColumns(2, buttonUploadProto, buttonOpenConfig)
 	scroll := container.NewVScroll(
-		container.NewVBox(widget.NewLabel(labelHostName), lineEntryHost, box, buttonBox, buttonSaveConfig))
+		container.NewVBox(widget.NewLabel(labelHostName), lineEntryHost, connection.Content, prometheus.content,
+			telemetry.content, box, buttonBox, buttonSaveConfig))
 	return scroll
 }
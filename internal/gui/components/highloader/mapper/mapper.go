@@ -1,6 +1,14 @@
 package mapper
 
 import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
 	"google.golang.org/protobuf/types/descriptorpb"
 
 	"github.com/AndreyNiki/grpc-highloader/internal/entity"
@@ -13,6 +21,38 @@ const (
 	defaultBool   = false
 )
 
+// actionPlaceholderPrefix prefix of placeholders of template actions in encoded message.
+const actionPlaceholderPrefix = "@action:"
+
+// actionPlaceholderRegexp regexp of quoted placeholder of template action with base64 encoded action.
+var actionPlaceholderRegexp = regexp.MustCompile(`"` + actionPlaceholderPrefix + `([A-Za-z0-9_-]*)"`)
+
+// exampleKind kind of generated example message.
+type exampleKind int
+
+// Available values for exampleKind.
+const (
+	exampleValid exampleKind = iota
+	exampleInvalid
+	exampleRandom
+)
+
+// scalarKind kind of scalar field.
+type scalarKind int
+
+// Available values for scalarKind.
+const (
+	scalarKindOther scalarKind = iota
+	scalarKindInt
+	scalarKindFloat
+	scalarKindBool
+	scalarKindString
+	scalarKindEnum
+)
+
+// templateAction template action of random message which renders JSON value, e.g. quoted string or number.
+type templateAction string
+
 // ExampleMessage map for example message.
 type ExampleMessage map[string]any
 
@@ -26,39 +66,160 @@ func NewMapper(proto *entity.ParsedProto) *Mapper {
 	return &Mapper{proto: proto}
 }
 
-// MakeExampleMessage create example message.
+// MakeExampleMessage create example message. Validation rules of fields are respected.
 func (m *Mapper) MakeExampleMessage(msg *entity.Message) *ExampleMessage {
+	return m.makeExampleMessage(msg, make(map[string]struct{}), exampleValid)
+}
+
+// MakeInvalidExampleMessage create example message where fields with validation rules violate them.
+// It is used for negative testing.
+func (m *Mapper) MakeInvalidExampleMessage(msg *entity.Message) *ExampleMessage {
+	return m.makeExampleMessage(msg, make(map[string]struct{}), exampleInvalid)
+}
+
+// MakeRandomMessageTemplate create message template which renders random values respecting validation rules
+// for every request.
+func (m *Mapper) MakeRandomMessageTemplate(msg *entity.Message) (string, error) {
+	exampleMessage := m.makeExampleMessage(msg, make(map[string]struct{}), exampleRandom)
+
+	// Template actions contain comparison chars, they must not be escaped.
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	err := encoder.Encode(exampleMessage)
+	if err != nil {
+		return "", err
+	}
+
+	// Actions are encoded as placeholders, because their quotes and backslashes must not be escaped.
+	tmpl := actionPlaceholderRegexp.ReplaceAllStringFunc(strings.TrimSuffix(buffer.String(), "\n"),
+		func(placeholder string) string {
+			action, _ := base64.RawURLEncoding.DecodeString(actionPlaceholderRegexp.FindStringSubmatch(placeholder)[1])
+			return string(action)
+		})
+
+	return tmpl, nil
+}
+
+// MarshalJSON implements json.Marshaler, action is encoded as placeholder which is replaced by action itself
+// after encoding of message.
+func (a templateAction) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + actionPlaceholderPrefix + base64.RawURLEncoding.EncodeToString([]byte(a)) + `"`), nil
+}
+
+// makeExampleMessage create example message. Messages that already are being made higher in the tree
+// are skipped, so recursive messages do not loop.
+//
+// Recursion.
+func (m *Mapper) makeExampleMessage(
+	msg *entity.Message,
+	parents map[string]struct{},
+	kind exampleKind,
+) *ExampleMessage {
+	parents[msg.FullName] = struct{}{}
+	defer delete(parents, msg.FullName)
+
 	exampleMessage := make(ExampleMessage)
 	for _, field := range msg.Fields {
+		if kind == exampleInvalid && field.Rules != nil && field.Rules.Required {
+			// Missing required field is a violation.
+			continue
+		}
 		if field.IsMap {
 			exampleMessage[field.Name] = make(map[string]any)
 			continue
 		}
-		if field.Message != nil {
-			exampleMessage[field.Name] = m.MakeExampleMessage(field.Message)
+		if field.IsRepeated {
+			exampleMessage[field.Name] = m.makeRepeatedValue(&field, parents, kind)
 			continue
 		}
-		exampleMessage[field.Name] = m.getDefaultValueForScalar(&field)
+
+		value, ok := m.makeValue(&field, field.Rules, parents, kind)
+		if ok {
+			exampleMessage[field.Name] = value
+		}
 	}
 
 	return &exampleMessage
 }
 
+// makeRepeatedValue make items of repeated field.
+func (m *Mapper) makeRepeatedValue(field *entity.Field, parents map[string]struct{}, kind exampleKind) []any {
+	count := 1
+	var itemRules *entity.FieldRules
+	if rules := field.Rules; rules != nil {
+		itemRules = rules.Items
+		if rules.MinItems != nil {
+			count = max(count, int(*rules.MinItems))
+		}
+		if rules.MaxItems != nil {
+			count = min(count, int(*rules.MaxItems))
+		}
+
+		if kind == exampleInvalid {
+			if rules.MinItems != nil && *rules.MinItems > 0 {
+				count = 0
+			} else if rules.MaxItems != nil {
+				count = int(*rules.MaxItems) + 1
+			}
+		}
+	}
+
+	items := make([]any, 0, count)
+	for i := 0; i < count; i++ {
+		value, ok := m.makeValue(field, itemRules, parents, kind)
+		if !ok {
+			break
+		}
+		items = append(items, value)
+	}
+
+	return items
+}
+
+// makeValue make single value of field with specified rules. Returns false if value could not be made.
+//
+// Recursion.
+func (m *Mapper) makeValue(
+	field *entity.Field,
+	rules *entity.FieldRules,
+	parents map[string]struct{},
+	kind exampleKind,
+) (any, bool) {
+	if field.Message != nil {
+		if _, ok := parents[field.Message.FullName]; ok {
+			return nil, false
+		}
+		// Calls itself.
+		return m.makeExampleMessage(field.Message, parents, kind), true
+	}
+
+	switch kind {
+	case exampleInvalid:
+		if value, ok := m.getInvalidValueForScalar(field, rules); ok {
+			return value, true
+		}
+		return m.getValidValueForScalar(field, rules), true
+	case exampleRandom:
+		return m.getRandomValueForScalar(field, rules), true
+	default:
+		return m.getValidValueForScalar(field, rules), true
+	}
+}
+
 // getDefaultValueForScalar return value for scalar.
 func (m *Mapper) getDefaultValueForScalar(field *entity.Field) any {
-	switch field.Type {
-	case descriptorpb.FieldDescriptorProto_TYPE_SINT32.String(), descriptorpb.FieldDescriptorProto_TYPE_SINT64.String(),
-		descriptorpb.FieldDescriptorProto_TYPE_INT32.String(), descriptorpb.FieldDescriptorProto_TYPE_INT64.String(),
-		descriptorpb.FieldDescriptorProto_TYPE_UINT32.String(), descriptorpb.FieldDescriptorProto_TYPE_UINT64.String(),
-		descriptorpb.FieldDescriptorProto_TYPE_FIXED32.String(), descriptorpb.FieldDescriptorProto_TYPE_FIXED64.String():
+	switch getScalarKind(field.Type) {
+	case scalarKindInt:
 		return defaultInt
-	case descriptorpb.FieldDescriptorProto_TYPE_FLOAT.String(), descriptorpb.FieldDescriptorProto_TYPE_DOUBLE.String():
+	case scalarKindFloat:
 		return defaultFloat
-	case descriptorpb.FieldDescriptorProto_TYPE_BOOL.String():
+	case scalarKindBool:
 		return defaultBool
-	case descriptorpb.FieldDescriptorProto_TYPE_STRING.String():
+	case scalarKindString:
 		return defaultString
-	case descriptorpb.FieldDescriptorProto_TYPE_ENUM.String():
+	case scalarKindEnum:
 		enum, ok := m.proto.FindEnumByName(field.EnumName)
 		if !ok {
 			return "EnumExampleNotFound"
@@ -69,18 +230,42 @@ func (m *Mapper) getDefaultValueForScalar(field *entity.Field) any {
 	}
 }
 
+// getScalarKind return kind of scalar by type of field.
+func getScalarKind(typ string) scalarKind {
+	switch typ {
+	case descriptorpb.FieldDescriptorProto_TYPE_SINT32.String(), descriptorpb.FieldDescriptorProto_TYPE_SINT64.String(),
+		descriptorpb.FieldDescriptorProto_TYPE_INT32.String(), descriptorpb.FieldDescriptorProto_TYPE_INT64.String(),
+		descriptorpb.FieldDescriptorProto_TYPE_UINT32.String(), descriptorpb.FieldDescriptorProto_TYPE_UINT64.String(),
+		descriptorpb.FieldDescriptorProto_TYPE_FIXED32.String(), descriptorpb.FieldDescriptorProto_TYPE_FIXED64.String(),
+		descriptorpb.FieldDescriptorProto_TYPE_SFIXED32.String(), descriptorpb.FieldDescriptorProto_TYPE_SFIXED64.String():
+		return scalarKindInt
+	case descriptorpb.FieldDescriptorProto_TYPE_FLOAT.String(), descriptorpb.FieldDescriptorProto_TYPE_DOUBLE.String():
+		return scalarKindFloat
+	case descriptorpb.FieldDescriptorProto_TYPE_BOOL.String():
+		return scalarKindBool
+	case descriptorpb.FieldDescriptorProto_TYPE_STRING.String():
+		return scalarKindString
+	case descriptorpb.FieldDescriptorProto_TYPE_ENUM.String():
+		return scalarKindEnum
+	default:
+		return scalarKindOther
+	}
+}
+
 // MakeProtoMapUI create ProtoMapUI for GUI.
 func (m *Mapper) MakeProtoMapUI(parsedProto *entity.ParsedProto) *ProtoMapUI {
 	protoMapUI := ProtoMapUI{
-		Services: make(map[string][]string),
-		Methods:  make(map[string]*entity.Message),
+		Services:         make(map[string][]string),
+		Methods:          make(map[string]*entity.Message),
+		serviceFullNames: make(map[string]string),
 	}
 	for _, service := range parsedProto.Services {
 		for _, method := range service.Methods {
 			// TODO: remove after implementation other types.
 			if method.Type == entity.MethodTypeUnaryRPC {
 				protoMapUI.Services[service.Name] = append(protoMapUI.Services[service.Name], method.Name)
-				protoMapUI.Methods[method.Name] = method.RequestMessage
+				protoMapUI.Methods[method.FullName] = method.RequestMessage
+				protoMapUI.serviceFullNames[service.Name] = service.FullName
 			}
 		}
 	}
@@ -90,18 +275,16 @@ func (m *Mapper) MakeProtoMapUI(parsedProto *entity.ParsedProto) *ProtoMapUI {
 
 // ProtoMapUI struct with needed params for GUI.
 type ProtoMapUI struct {
+	// Services methods names by service name.
 	Services map[string][]string
-	Methods  map[string]*entity.Message
+	// Methods request messages by fully-qualified method name.
+	Methods          map[string]*entity.Message
+	serviceFullNames map[string]string
 }
 
-// GetServicesNames return services names.
+// GetServicesNames return sorted services names.
 func (p *ProtoMapUI) GetServicesNames() []string {
-	names := make([]string, 0, len(p.Services))
-	for name := range p.Services {
-		names = append(names, name)
-	}
-
-	return names
+	return slices.Sorted(maps.Keys(p.Services))
 }
 
 // GetMethodsNamesByService return methods by service name.
@@ -110,8 +293,12 @@ func (p *ProtoMapUI) GetMethodsNamesByService(serviceName string) []string {
 	return methods
 }
 
-// GetMessageByMethodName return message by method name.
-func (p *ProtoMapUI) GetMessageByMethodName(methodName string) (*entity.Message, bool) {
-	m, ok := p.Methods[methodName]
+// GetMessageByMethodName return message by service and method name.
+func (p *ProtoMapUI) GetMessageByMethodName(serviceName, methodName string) (*entity.Message, bool) {
+	serviceFullName, ok := p.serviceFullNames[serviceName]
+	if !ok {
+		return nil, false
+	}
+	m, ok := p.Methods[serviceFullName+"."+methodName]
 	return m, ok
 }
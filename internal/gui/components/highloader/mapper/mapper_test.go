@@ -0,0 +1,155 @@
+package mapper
+
+import (
+	"encoding/json"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/AndreyNiki/grpc-highloader/internal/entity"
+	"github.com/AndreyNiki/grpc-highloader/internal/templates"
+	"github.com/AndreyNiki/grpc-highloader/internal/utils/ptr"
+)
+
+const codePattern = `^[A-Z]{3}-[0-9]{4}$`
+
+func newTestProto() (*entity.ParsedProto, *entity.Message) {
+	msg := &entity.Message{
+		Name:     "CreateRequest",
+		FullName: "example.CreateRequest",
+		Fields: []entity.Field{
+			{Name: "code", Type: "TYPE_STRING", Rules: &entity.FieldRules{Pattern: codePattern}},
+			{Name: "name", Type: "TYPE_STRING", Rules: &entity.FieldRules{MinLen: ptr.ToPtr(uint64(8))}},
+			{Name: "count", Type: "TYPE_INT32", Rules: &entity.FieldRules{
+				Min: ptr.ToPtr(10.0), MinExclusive: true, Max: ptr.ToPtr(20.0),
+			}},
+			{Name: "kind", Type: "TYPE_ENUM", EnumName: "example.Kind", Rules: &entity.FieldRules{In: []any{int32(2)}}},
+			{Name: "tags", Type: "TYPE_STRING", IsRepeated: true, Rules: &entity.FieldRules{MinItems: ptr.ToPtr(uint64(2))}},
+		},
+	}
+	// Recursive message.
+	msg.Fields = append(msg.Fields, entity.Field{Name: "parent", Type: "TYPE_MESSAGE", Message: msg})
+
+	return &entity.ParsedProto{
+		Enums: map[string]entity.Enum{
+			"example.Kind": {
+				Name: "Kind", FullName: "example.Kind",
+				Values: []string{"KIND_UNSPECIFIED", "KIND_A", "KIND_B"}, Numbers: []int32{0, 1, 2},
+			},
+		},
+		Messages: map[string]*entity.Message{msg.FullName: msg},
+	}, msg
+}
+
+func TestMapper_MakeExampleMessage(t *testing.T) {
+	parsed, msg := newTestProto()
+	m := NewMapper(parsed)
+
+	t.Run("Test valid example", func(t *testing.T) {
+		example := *m.MakeExampleMessage(msg)
+
+		assert.Regexp(t, regexp.MustCompile(codePattern), example["code"])
+		assert.GreaterOrEqual(t, len(example["name"].(string)), 8)
+		assert.Equal(t, int64(11), example["count"])
+		assert.Equal(t, "KIND_B", example["kind"])
+		assert.Len(t, example["tags"], 2)
+		assert.NotContains(t, example, "parent")
+	})
+
+	t.Run("Test invalid example", func(t *testing.T) {
+		example := *m.MakeInvalidExampleMessage(msg)
+
+		assert.NotRegexp(t, regexp.MustCompile(codePattern), example["code"])
+		assert.Equal(t, "", example["name"])
+		assert.Equal(t, 10.0, example["count"])
+		assert.NotEqual(t, "KIND_B", example["kind"])
+		assert.Empty(t, example["tags"])
+	})
+
+	t.Run("Test random template", func(t *testing.T) {
+		tmpl, err := m.MakeRandomMessageTemplate(msg)
+		require.NoError(t, err)
+		rendered, err := templates.NewTemplateBuilder().Process(tmpl)
+		require.NoError(t, err)
+
+		var example map[string]any
+		require.NoError(t, json.Unmarshal([]byte(rendered), &example))
+		assert.Regexp(t, regexp.MustCompile(codePattern), example["code"])
+		assert.GreaterOrEqual(t, len(example["name"].(string)), 8)
+		count := example["count"].(float64)
+		assert.Greater(t, count, 10.0)
+		assert.LessOrEqual(t, count, 20.0)
+		assert.Equal(t, "KIND_B", example["kind"])
+	})
+}
+
+func TestMapper_MakeRandomMessageTemplate_Escaping(t *testing.T) {
+	const (
+		digitsPattern = `^\d{3}$`
+		quotesPattern = `^"[a-z]{2}\\"$`
+	)
+	msg := &entity.Message{
+		Name:     "EscapeRequest",
+		FullName: "example.EscapeRequest",
+		Fields: []entity.Field{
+			{Name: "digits", Type: "TYPE_STRING", Rules: &entity.FieldRules{Pattern: digitsPattern}},
+			{Name: "quotes", Type: "TYPE_STRING", Rules: &entity.FieldRules{Pattern: quotesPattern}},
+			{Name: "quoted", Type: "TYPE_STRING", Rules: &entity.FieldRules{Prefix: `"`, Suffix: `\`}},
+			{Name: "choice", Type: "TYPE_STRING", Rules: &entity.FieldRules{In: []any{`a"b`}}},
+		},
+	}
+	m := NewMapper(&entity.ParsedProto{})
+
+	tmpl, err := m.MakeRandomMessageTemplate(msg)
+	require.NoError(t, err)
+	rendered, err := templates.NewTemplateBuilder().Process(tmpl)
+	require.NoError(t, err)
+
+	var example map[string]any
+	require.NoError(t, json.Unmarshal([]byte(rendered), &example), rendered)
+	assert.Regexp(t, regexp.MustCompile(digitsPattern), example["digits"])
+	assert.Regexp(t, regexp.MustCompile(quotesPattern), example["quotes"])
+	assert.Regexp(t, regexp.MustCompile(`^"[a-zA-Z0-9]+\\$`), example["quoted"])
+	assert.Equal(t, `a"b`, example["choice"])
+}
+
+func TestMapper_MakeInvalidExampleMessage_BoolConst(t *testing.T) {
+	msg := &entity.Message{
+		Name:     "BoolRequest",
+		FullName: "example.BoolRequest",
+		Fields: []entity.Field{
+			{Name: "enabled", Type: "TYPE_BOOL", Rules: &entity.FieldRules{Const: false}},
+		},
+	}
+	m := NewMapper(&entity.ParsedProto{})
+
+	assert.Equal(t, false, (*m.MakeExampleMessage(msg))["enabled"])
+	assert.Equal(t, true, (*m.MakeInvalidExampleMessage(msg))["enabled"])
+}
+
+func TestMapper_MakeProtoMapUI(t *testing.T) {
+	req := &entity.Message{Name: "GetRequest", FullName: "example.GetRequest"}
+	list := &entity.Message{Name: "ListRequest", FullName: "example.ListRequest"}
+	parsed := &entity.ParsedProto{
+		Services: []entity.Service{
+			{Name: "Users", FullName: "example.Users", Methods: []entity.Method{
+				{Name: "Get", FullName: "example.Users.Get", RequestMessage: req},
+			}},
+			{Name: "Admins", FullName: "example.Admins", Methods: []entity.Method{
+				{Name: "Get", FullName: "example.Admins.Get", RequestMessage: list},
+			}},
+		},
+	}
+
+	protoMapUI := NewMapper(parsed).MakeProtoMapUI(parsed)
+	assert.Equal(t, []string{"Admins", "Users"}, protoMapUI.GetServicesNames())
+
+	msg, ok := protoMapUI.GetMessageByMethodName("Users", "Get")
+	require.True(t, ok)
+	assert.Same(t, req, msg)
+	msg, ok = protoMapUI.GetMessageByMethodName("Admins", "Get")
+	require.True(t, ok)
+	assert.Same(t, list, msg)
+}
@@ -0,0 +1,378 @@
+package mapper
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/AndreyNiki/grpc-highloader/internal/entity"
+	"github.com/AndreyNiki/grpc-highloader/internal/templates"
+)
+
+const (
+	// randomMinLen and randomMaxLen default range of length for random strings.
+	randomMinLen = 5
+	randomMaxLen = 10
+	// randomMin and randomMax default range for random numbers.
+	randomMin = 1
+	randomMax = 100
+	// invalidString value for strings which violates rules.
+	invalidString = "invalid!"
+)
+
+// formatExamples examples of well-known string formats.
+var formatExamples = map[string]string{
+	"email":    "user@example.com",
+	"hostname": "example.com",
+	"ip":       "127.0.0.1",
+	"ipv4":     "127.0.0.1",
+	"ipv6":     "::1",
+	"address":  "127.0.0.1",
+	"uri":      "https://example.com",
+	"uri_ref":  "https://example.com",
+	"uuid":     "123e4567-e89b-42d3-a456-426614174000",
+	"tuuid":    "123e4567e89b42d3a456426614174000",
+}
+
+// getValidValueForScalar return value for scalar which respects rules.
+func (m *Mapper) getValidValueForScalar(field *entity.Field, rules *entity.FieldRules) any {
+	if rules == nil {
+		return m.getDefaultValueForScalar(field)
+	}
+	if rules.Const != nil {
+		return m.enumValueName(field, rules.Const)
+	}
+	if len(rules.In) != 0 {
+		return m.enumValueName(field, rules.In[0])
+	}
+
+	switch getScalarKind(field.Type) {
+	case scalarKindString:
+		return m.validString(rules)
+	case scalarKindInt:
+		lo, hi := m.intRange(rules, math.MinInt64, math.MaxInt64)
+		value := min(max(defaultInt, lo), hi)
+		for slices.ContainsFunc(rules.NotIn, func(v any) bool { return toFloat(v) == float64(value) }) && value < hi {
+			value++
+		}
+		return value
+	case scalarKindFloat:
+		return m.validFloat(rules)
+	case scalarKindEnum:
+		values := m.allowedEnumValues(field, rules)
+		if len(values) == 0 {
+			return m.getDefaultValueForScalar(field)
+		}
+		return values[0]
+	default:
+		return m.getDefaultValueForScalar(field)
+	}
+}
+
+// getInvalidValueForScalar return value for scalar which violates rules. Returns false if rules are empty.
+func (m *Mapper) getInvalidValueForScalar(field *entity.Field, rules *entity.FieldRules) (any, bool) {
+	if rules == nil {
+		return nil, false
+	}
+
+	kind := getScalarKind(field.Type)
+	switch {
+	case rules.Const != nil && kind == scalarKindString:
+		return fmt.Sprint(rules.Const) + "x", true
+	case rules.Const != nil && kind == scalarKindBool:
+		b, _ := rules.Const.(bool)
+		return !b, true
+	case rules.Const != nil:
+		return m.enumValueName(field, toFloat(rules.Const)+1), true
+	case len(rules.In) != 0 && kind == scalarKindString:
+		value := invalidString
+		for slices.Contains(rules.In, any(value)) {
+			value += "x"
+		}
+		return value, true
+	case len(rules.In) != 0:
+		value := toFloat(slices.MaxFunc(rules.In, func(a, b any) int {
+			return int(toFloat(a) - toFloat(b))
+		})) + 1
+		return m.enumValueName(field, value), true
+	}
+
+	switch kind {
+	case scalarKindString:
+		return m.invalidString(rules)
+	case scalarKindInt, scalarKindFloat:
+		switch {
+		case rules.Min != nil && rules.MinExclusive:
+			return *rules.Min, true
+		case rules.Min != nil:
+			return *rules.Min - 1, true
+		case rules.Max != nil && rules.MaxExclusive:
+			return *rules.Max, true
+		case rules.Max != nil:
+			return *rules.Max + 1, true
+		case len(rules.NotIn) != 0:
+			return rules.NotIn[0], true
+		}
+	case scalarKindEnum:
+		if len(rules.NotIn) != 0 {
+			return m.enumValueName(field, rules.NotIn[0]), true
+		}
+		if rules.DefinedOnly {
+			enum, ok := m.proto.FindEnumByName(field.EnumName)
+			if ok && len(enum.Numbers) != 0 {
+				// Number is used, because undefined value has no name.
+				return slices.Max(enum.Numbers) + 1, true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+// getRandomValueForScalar return template action in string, it renders random value which respects rules.
+func (m *Mapper) getRandomValueForScalar(field *entity.Field, rules *entity.FieldRules) any {
+	if rules == nil {
+		rules = &entity.FieldRules{}
+	}
+	if rules.Const != nil {
+		return m.getValidValueForScalar(field, rules)
+	}
+	if len(rules.In) != 0 {
+		values := make([]string, 0, len(rules.In))
+		for _, v := range rules.In {
+			values = append(values, fmt.Sprint(m.enumValueName(field, v)))
+		}
+		return randChoiceAction(values)
+	}
+
+	switch getScalarKind(field.Type) {
+	case scalarKindString:
+		return m.randomString(rules)
+	case scalarKindInt:
+		lo, hi := m.intRange(rules, randomMin, randomMax)
+		return templateAction(fmt.Sprintf("{{randNum %d %d}}", lo, hi))
+	case scalarKindFloat:
+		lo, hi := float64(randomMin), float64(randomMax)
+		if rules.Min != nil {
+			lo = *rules.Min
+			hi = max(hi, lo+randomMax)
+		}
+		if rules.Max != nil {
+			hi = *rules.Max
+			lo = min(lo, hi-randomMax)
+		}
+		return templateAction(fmt.Sprintf("{{randFloat %s %s}}", formatFloat(lo), formatFloat(hi)))
+	case scalarKindEnum:
+		return randChoiceAction(m.allowedEnumValues(field, rules))
+	default:
+		return m.getValidValueForScalar(field, rules)
+	}
+}
+
+// validString return string which respects rules.
+func (m *Mapper) validString(rules *entity.FieldRules) string {
+	if example, ok := formatExamples[rules.Format]; ok {
+		return example
+	}
+	if rules.Pattern != "" {
+		value, err := templates.GeneratePattern(rules.Pattern)
+		if err == nil {
+			return value
+		}
+	}
+
+	body := defaultString
+	affixLen := len(rules.Prefix) + len(rules.Suffix)
+	if rules.MinLen != nil && uint64(affixLen+len(body)) < *rules.MinLen {
+		body += strings.Repeat("x", int(*rules.MinLen)-affixLen-len(body))
+	}
+	if rules.MaxLen != nil && uint64(affixLen+len(body)) > *rules.MaxLen {
+		body = body[:max(int(*rules.MaxLen)-affixLen, 0)]
+	}
+
+	return rules.Prefix + body + rules.Suffix
+}
+
+// invalidString return string which violates rules. Returns false if string rules are empty.
+func (m *Mapper) invalidString(rules *entity.FieldRules) (string, bool) {
+	switch {
+	case rules.Format != "":
+		return invalidString, true
+	case rules.MinLen != nil && *rules.MinLen > 0:
+		return "", true
+	case rules.MaxLen != nil:
+		return strings.Repeat("x", int(*rules.MaxLen)+1), true
+	case rules.Prefix != "":
+		return invalidString, !strings.HasPrefix(invalidString, rules.Prefix)
+	case rules.Suffix != "":
+		return invalidString, !strings.HasSuffix(invalidString, rules.Suffix)
+	case rules.Pattern != "":
+		re, err := regexp.Compile(rules.Pattern)
+		if err != nil {
+			return "", false
+		}
+		for _, value := range []string{"", invalidString, " "} {
+			if !re.MatchString(value) {
+				return value, true
+			}
+		}
+	case len(rules.NotIn) != 0:
+		return fmt.Sprint(rules.NotIn[0]), true
+	}
+
+	return "", false
+}
+
+// randomString return template action for random string which respects rules, string is returned
+// if random value could not be made.
+func (m *Mapper) randomString(rules *entity.FieldRules) any {
+	switch {
+	case rules.Format == "uuid":
+		return templateAction("{{randUUID | toJSON}}")
+	case rules.Format == "email":
+		return templateAction(fmt.Sprintf("{{print (randString %d %d) %q | toJSON}}",
+			randomMinLen, randomMaxLen, "@example.com"))
+	case rules.Format != "":
+		return m.validString(rules)
+	case rules.Pattern != "":
+		if _, err := templates.GeneratePattern(rules.Pattern); err != nil {
+			return m.validString(rules)
+		}
+		return templateAction("{{randPattern " + strconv.Quote(rules.Pattern) + " | toJSON}}")
+	}
+
+	affixLen := len(rules.Prefix) + len(rules.Suffix)
+	lo, hi := randomMinLen, randomMaxLen
+	if rules.MinLen != nil {
+		lo = max(int(*rules.MinLen)-affixLen, 0)
+		hi = max(hi, lo+randomMaxLen-randomMinLen)
+	}
+	if rules.MaxLen != nil {
+		hi = max(int(*rules.MaxLen)-affixLen, 0)
+		lo = min(lo, hi)
+	}
+
+	return templateAction(fmt.Sprintf("{{print %q (randString %d %d) %q | toJSON}}", rules.Prefix, lo, hi,
+		rules.Suffix))
+}
+
+// validFloat return float which respects rules.
+func (m *Mapper) validFloat(rules *entity.FieldRules) float64 {
+	aboveMin := rules.Min == nil || defaultFloat > *rules.Min || !rules.MinExclusive && defaultFloat == *rules.Min
+	belowMax := rules.Max == nil || defaultFloat < *rules.Max || !rules.MaxExclusive && defaultFloat == *rules.Max
+	switch {
+	case aboveMin && belowMax:
+		return defaultFloat
+	case rules.Min != nil && rules.Max != nil:
+		return (*rules.Min + *rules.Max) / 2
+	case rules.Min != nil:
+		return *rules.Min + 1
+	default:
+		return *rules.Max - 1
+	}
+}
+
+// intRange return range of integers allowed by rules, lo and hi are used if bounds are not set.
+// If only one bound is set, the width of default range is kept.
+func (m *Mapper) intRange(rules *entity.FieldRules, lo, hi int64) (int64, int64) {
+	width := uint64(hi - lo)
+	if rules.Min != nil {
+		lo = int64(math.Floor(*rules.Min)) + 1
+		if !rules.MinExclusive && *rules.Min == math.Floor(*rules.Min) {
+			lo--
+		}
+		if hi < lo {
+			hi = lo + int64(min(width, uint64(math.MaxInt64-lo)))
+		}
+	}
+	if rules.Max != nil {
+		hi = int64(math.Ceil(*rules.Max)) - 1
+		if !rules.MaxExclusive && *rules.Max == math.Ceil(*rules.Max) {
+			hi++
+		}
+		if lo > hi {
+			lo = max(hi-int64(min(width, math.MaxInt64)), math.MinInt64)
+		}
+	}
+
+	return lo, hi
+}
+
+// allowedEnumValues return names of enum values allowed by rules.
+func (m *Mapper) allowedEnumValues(field *entity.Field, rules *entity.FieldRules) []string {
+	enum, ok := m.proto.FindEnumByName(field.EnumName)
+	if !ok {
+		return nil
+	}
+
+	var values []string
+	for i, number := range enum.Numbers {
+		isNumber := func(v any) bool { return toFloat(v) == float64(number) }
+		if slices.ContainsFunc(rules.NotIn, isNumber) {
+			continue
+		}
+		if len(rules.In) != 0 && !slices.ContainsFunc(rules.In, isNumber) {
+			continue
+		}
+		values = append(values, enum.Values[i])
+	}
+
+	return values
+}
+
+// enumValueName return name of enum value by number for enum fields, other values are returned as is.
+func (m *Mapper) enumValueName(field *entity.Field, value any) any {
+	if getScalarKind(field.Type) != scalarKindEnum {
+		return value
+	}
+	enum, ok := m.proto.FindEnumByName(field.EnumName)
+	if !ok {
+		return value
+	}
+	name, ok := enum.FindValueByNumber(int32(toFloat(value)))
+	if !ok {
+		return value
+	}
+
+	return name
+}
+
+// randChoiceAction return template action for random choice from values.
+func randChoiceAction(values []string) templateAction {
+	quoted := make([]string, 0, len(values))
+	for _, v := range values {
+		quoted = append(quoted, strconv.Quote(v))
+	}
+
+	return templateAction("{{randChoice " + strings.Join(quoted, " ") + " | toJSON}}")
+}
+
+// formatFloat format float for template action.
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// toFloat convert number of any type to float64, other types are converted to 0.
+func toFloat(value any) float64 {
+	switch v := value.(type) {
+	case int:
+		return float64(v)
+	case int32:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case uint32:
+		return float64(v)
+	case uint64:
+		return float64(v)
+	case float32:
+		return float64(v)
+	case float64:
+		return v
+	default:
+		return 0
+	}
+}
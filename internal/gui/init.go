@@ -16,15 +16,31 @@ type GUI struct {
 	height        float32
 	loaderFactory interfaces.LoaderFactory
 	parser        interfaces.Parser
+	watcher       interfaces.Watcher
+	validator     interfaces.Validator
+	exporter      interfaces.MetricsExporter
+	telemetry     interfaces.TelemetryExporter
 }
 
 // NewGUI create a new GUI.
-func NewGUI(width, height float32, loaderFactory interfaces.LoaderFactory, parser interfaces.Parser) *GUI {
+func NewGUI(
+	width, height float32,
+	loaderFactory interfaces.LoaderFactory,
+	parser interfaces.Parser,
+	watcher interfaces.Watcher,
+	validator interfaces.Validator,
+	exporter interfaces.MetricsExporter,
+	telemetry interfaces.TelemetryExporter,
+) *GUI {
 	return &GUI{
 		width:         width,
 		height:        height,
 		loaderFactory: loaderFactory,
 		parser:        parser,
+		watcher:       watcher,
+		validator:     validator,
+		exporter:      exporter,
+		telemetry:     telemetry,
 	}
 }
 
@@ -34,7 +50,7 @@ func (g *GUI) Run() {
 	w := a.NewWindow("GRPC HighLoader v1.0")
 	w.Resize(fyne.NewSize(g.width, g.height))
 
-	highLoader := highloader.New(w, g.loaderFactory, g.parser)
+	highLoader := highloader.New(w, g.loaderFactory, g.parser, g.watcher, g.validator, g.exporter, g.telemetry)
 	hlComponent := highLoader.InitComponent()
 
 	t := container.NewAppTabs(
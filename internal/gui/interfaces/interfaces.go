@@ -22,3 +22,26 @@ type LoaderFactory interface {
 type Parser interface {
 	ParseProto(fp string) (*entity.ParsedProto, error)
 }
+
+// Watcher interface for watching changes of proto file.
+type Watcher interface {
+	Watch(proto *entity.ParsedProto, fn func(proto *entity.ParsedProto, err error)) (stop func(), err error)
+}
+
+// MetricsExporter interface for exporting metrics of running requests by HTTP listener.
+type MetricsExporter interface {
+	Register(labels entity.MetricsLabels, metrics *metrics.Metrics) (unregister func())
+	Listen(addr string) (stop func(), err error)
+}
+
+// TelemetryExporter interface for exporting metrics of running requests to OpenTelemetry collector.
+type TelemetryExporter interface {
+	Register(labels entity.MetricsLabels, metrics *metrics.Metrics) (unregister func())
+	Start(params entity.TelemetryParams) (stop func(), err error)
+}
+
+// Validator interface for pre-flight validation of request.
+type Validator interface {
+	Validate(ctx context.Context, req *entity.RequestParams, send bool) []entity.ValidationProblem
+	ValidateMessage(req *entity.RequestParams) []entity.ValidationProblem
+}
@@ -0,0 +1,42 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// sizeUnits multipliers of size units, the longest suffixes go first.
+var sizeUnits = []struct {
+	suffix     string
+	multiplier int64
+}{
+	{"GB", 1 << 30},
+	{"MB", 1 << 20},
+	{"KB", 1 << 10},
+	{"B", 1},
+}
+
+// ParseSize parse size in bytes with optional unit suffix B, KB, MB or GB, e.g. "16MB".
+func ParseSize(s string) (int, error) {
+	value := strings.ToUpper(strings.TrimSpace(s))
+	multiplier := int64(1)
+	for _, u := range sizeUnits {
+		if strings.HasSuffix(value, u.suffix) {
+			value = strings.TrimSpace(strings.TrimSuffix(value, u.suffix))
+			multiplier = u.multiplier
+			break
+		}
+	}
+
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	if n > math.MaxInt32/multiplier {
+		return 0, fmt.Errorf("size %q is too large", s)
+	}
+
+	return int(n * multiplier), nil
+}
@@ -3,6 +3,7 @@ package utils
 import (
 	"errors"
 	"regexp"
+	"time"
 
 	"fyne.io/fyne/v2/widget"
 )
@@ -81,3 +82,31 @@ func NumberValidation() func(s string) error {
 		return nil
 	}
 }
+
+// DurationValidation validation for duration entry, e.g. "30s" or "1m30s".
+func DurationValidation() func(s string) error {
+	return func(s string) error {
+		if s == "" {
+			return nil
+		}
+		if _, err := time.ParseDuration(s); err != nil {
+			return errors.New("field is must be duration, e.g. 30s")
+		}
+
+		return nil
+	}
+}
+
+// SizeValidation validation for size entry, e.g. "1024", "512KB" or "16MB".
+func SizeValidation() func(s string) error {
+	return func(s string) error {
+		if s == "" {
+			return nil
+		}
+		if _, err := ParseSize(s); err != nil {
+			return errors.New("field is must be size, e.g. 16MB")
+		}
+
+		return nil
+	}
+}
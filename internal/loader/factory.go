@@ -10,6 +10,7 @@ import (
 // LoaderFactory implements factory interface for GUI.
 type LoaderFactory struct {
 	requesterFactory interfaces.RequesterFactory
+	tracer           interfaces.Tracer
 }
 
 // NewLoaderFactory create a new LoaderFactory.
@@ -19,6 +20,12 @@ func NewLoaderFactory(requesterFactory interfaces.RequesterFactory) *LoaderFacto
 	}
 }
 
+// WithTracer set tracer of requests of loaders.
+func (f *LoaderFactory) WithTracer(tracer interfaces.Tracer) *LoaderFactory {
+	f.tracer = tracer
+	return f
+}
+
 // NewLoader create a new Loader.
 func (f *LoaderFactory) NewLoader(req *entity.RequestParams, metrics *metrics.Metrics) (guiinterfaces.Loader, error) {
 	requester, err := f.requesterFactory.NewRequester(req, metrics)
@@ -27,5 +34,6 @@ func (f *LoaderFactory) NewLoader(req *entity.RequestParams, metrics *metrics.Me
 	}
 
 	loader := NewRequestLoader(requester, req, metrics)
+	loader.tracer = f.tracer
 	return loader, nil
 }
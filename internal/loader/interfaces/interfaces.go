@@ -13,6 +13,12 @@ type Requester interface {
 	Close()
 }
 
+// Tracer interface for client spans of requests.
+type Tracer interface {
+	// StartSpan start span of request and return W3C traceparent of it, empty if tracing is disabled.
+	StartSpan(req *entity.RequestParams) (traceparent string, end func(err error))
+}
+
 // RequesterFactory interface for makes Requester.
 type RequesterFactory interface {
 	NewRequester(req *entity.RequestParams, metrics *metrics.Metrics) (Requester, error)
@@ -2,6 +2,7 @@ package loader
 
 import (
 	"context"
+	"sync"
 	"time"
 
 	"google.golang.org/grpc/metadata"
@@ -12,11 +13,15 @@ import (
 	"github.com/AndreyNiki/grpc-highloader/internal/metrics"
 )
 
+// traceparentKey metadata key of W3C trace context.
+const traceparentKey = "traceparent"
+
 // RequestLoader implements loader interface for GUI.
 type RequestLoader struct {
 	requester interfaces.Requester
 	req       *entity.RequestParams
 	metrics   *metrics.Metrics
+	tracer    interfaces.Tracer
 }
 
 // NewRequestLoader create a new loader.
@@ -32,26 +37,43 @@ func NewRequestLoader(
 	}
 }
 
-// Run requests.
+// Run requests until context is done, requests in flight are awaited before return.
 func (rl *RequestLoader) Run(ctx context.Context) error {
 	md := metadata.New(rl.req.Metadata)
 	ctx = metadata.NewOutgoingContext(ctx, md)
 
 	rl.metrics.Reset()
+	rl.metrics.SetRequestPerSecond(int64(rl.req.RPS))
+	go rl.metrics.CollectSeries(ctx)
 
 	ticker := time.NewTicker(time.Second / time.Duration(rl.req.RPS))
 	defer ticker.Stop()
 
 	log := logger.LoggerFromContext(ctx)
 
+	var wg sync.WaitGroup
+	defer wg.Wait()
 	for {
 		if ctx.Err() != nil {
 			log.Info("context canceled")
 			break
 		}
 
+		rl.metrics.AddInFlight(1)
+		wg.Add(1)
 		go func() {
+			defer wg.Done()
+			defer rl.metrics.AddInFlight(-1)
+			ctx := ctx
 			var cancel context.CancelFunc
+			end := func(error) {}
+			if rl.tracer != nil {
+				var tp string
+				tp, end = rl.tracer.StartSpan(rl.req)
+				if tp != "" {
+					ctx = metadata.AppendToOutgoingContext(ctx, traceparentKey, tp)
+				}
+			}
 			if rl.req.RequestDeadline != nil {
 				ctx, cancel = context.WithDeadline(ctx, time.Now().Add(*rl.req.RequestDeadline))
 			}
@@ -59,6 +81,7 @@ func (rl *RequestLoader) Run(ctx context.Context) error {
 			switch rl.req.MethodType {
 			case entity.MethodTypeUnaryRPC:
 				err := rl.requester.SendUnaryRPCRequest(ctx)
+				end(err)
 				if err != nil {
 					log.Error("Error send unary rpc request", "Error", err)
 				}
@@ -0,0 +1,102 @@
+package loader
+
+import (
+	"context"
+	"net"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/AndreyNiki/grpc-highloader/internal/entity"
+	"github.com/AndreyNiki/grpc-highloader/internal/metrics"
+	"github.com/AndreyNiki/grpc-highloader/internal/proto"
+	"github.com/AndreyNiki/grpc-highloader/internal/utils/ptr"
+)
+
+// tracer fake tracer which counts ended spans.
+type tracer struct {
+	ended atomic.Int64
+}
+
+func (tr *tracer) StartSpan(_ *entity.RequestParams) (string, func(err error)) {
+	return testTraceparent, func(error) { tr.ended.Add(1) }
+}
+
+const testTraceparent = "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"
+
+func TestRequestLoader_Run(t *testing.T) {
+	lis := bufconn.Listen(1 << 20)
+	var traced atomic.Int64
+	srv := grpc.NewServer(grpc.UnknownServiceHandler(func(_ any, stream grpc.ServerStream) error {
+		md, _ := metadata.FromIncomingContext(stream.Context())
+		if slices.Equal(md.Get(traceparentKey), []string{testTraceparent}) && slices.Equal(md.Get("x-user"), []string{"test"}) {
+			traced.Add(1)
+		}
+		if err := stream.RecvMsg(&emptypb.Empty{}); err != nil {
+			return err
+		}
+		return stream.SendMsg(&emptypb.Empty{})
+	}))
+	go srv.Serve(lis)
+	defer srv.Stop()
+
+	parsed, err := proto.NewProtoParser().ParseProto("../proto/testdata/example.proto")
+	require.NoError(t, err)
+	req := &entity.RequestParams{
+		Host:            "passthrough:///bufnet",
+		Service:         "example.v1.UserService",
+		Method:          "Get",
+		MethodType:      entity.MethodTypeUnaryRPC,
+		Message:         `{"id": "{{randNum 1 100}}"}`,
+		RPS:             200,
+		RequestDeadline: ptr.ToPtr(time.Second),
+		Proto:           parsed,
+		Metadata:        map[string]string{"x-user": "test"},
+	}
+
+	requesterFactory := proto.NewRequesterFactory().WithDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+		return lis.DialContext(ctx)
+	})
+	m := metrics.InitMetrics()
+	tr := &tracer{}
+	loader, err := NewLoaderFactory(requesterFactory).WithTracer(tr).NewLoader(req, m)
+	require.NoError(t, err)
+	defer loader.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- loader.Run(ctx)
+	}()
+
+	require.Eventually(t, func() bool {
+		return m.Statuses.Get(codes.OK).Value.Load() >= 20 && len(m.Series.Points()) > 0
+	}, 5*time.Second, 10*time.Millisecond)
+	cancel()
+	require.NoError(t, <-done)
+
+	assert.GreaterOrEqual(t, m.RequestCounter.Value.Load(), int64(20))
+	assert.Len(t, m.Backends.Snapshot(), 1)
+	p, ok := m.Series.Last()
+	require.True(t, ok)
+	assert.Equal(t, int64(req.RPS), p.TargetRPS)
+	assert.Positive(t, p.RPS)
+	assert.Eventually(t, func() bool { return m.InFlightGauge.Value.Load() == 0 }, time.Second, 10*time.Millisecond)
+	assert.Equal(t, m.RequestCounter.Value.Load(), tr.ended.Load())
+	var outcomes int64
+	for _, count := range m.Outcomes.Snapshot() {
+		outcomes += count
+	}
+	assert.Equal(t, m.RequestCounter.Value.Load(), outcomes)
+	// Canceled requests may reach the server too.
+	assert.GreaterOrEqual(t, traced.Load(), m.Statuses.Get(codes.OK).Value.Load())
+}
@@ -0,0 +1,94 @@
+package metrics
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// maxAssertionSamples count of kept samples of failed assertions.
+const maxAssertionSamples = 20
+
+// Assertions metrics of checks of responses by assertions of request.
+type Assertions struct {
+	// Passed count of checked responses which passed all assertions.
+	Passed *Metric
+	// Failed counters of failed responses by failed assertion, the first failed assertion of response is counted.
+	Failed *Counters[string]
+	// mu guards samples.
+	mu      sync.Mutex
+	samples []AssertionSample
+}
+
+// AssertionSample sample of failed assertion with actual value of response.
+type AssertionSample struct {
+	Time      time.Time
+	Assertion string
+	Message   string
+}
+
+// AssertionStat count of responses failed assertion.
+type AssertionStat struct {
+	Assertion string
+	Count     int64
+}
+
+// AssertionsStat snapshot of assertions metrics.
+type AssertionsStat struct {
+	Passed int64
+	// Failed failed assertions sorted by count.
+	Failed  []AssertionStat
+	Samples []AssertionSample
+}
+
+// newAssertions create a new Assertions.
+func newAssertions() *Assertions {
+	return &Assertions{
+		Passed: &Metric{Value: &atomic.Int64{}},
+		Failed: newCounters[string](),
+	}
+}
+
+// Snapshot return stats of assertions.
+func (a *Assertions) Snapshot() AssertionsStat {
+	counts := a.Failed.Snapshot()
+	failed := make([]AssertionStat, 0, len(counts))
+	for assertion, count := range counts {
+		failed = append(failed, AssertionStat{Assertion: assertion, Count: count})
+	}
+	slices.SortFunc(failed, func(a, b AssertionStat) int {
+		return cmp.Or(cmp.Compare(b.Count, a.Count), strings.Compare(a.Assertion, b.Assertion))
+	})
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return AssertionsStat{
+		Passed:  a.Passed.Value.Load(),
+		Failed:  failed,
+		Samples: slices.Clone(a.samples),
+	}
+}
+
+// observeFailure count failed assertion, the first failures are kept as samples.
+func (a *Assertions) observeFailure(assertion, message string) {
+	a.Failed.Increment(assertion)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if len(a.samples) < maxAssertionSamples {
+		a.samples = append(a.samples, AssertionSample{Time: time.Now(), Assertion: assertion, Message: message})
+	}
+}
+
+// reset all values.
+func (a *Assertions) reset() {
+	a.Passed.Value.Store(0)
+	a.Failed.reset()
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.samples = nil
+}
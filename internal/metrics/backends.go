@@ -0,0 +1,93 @@
+package metrics
+
+import (
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Backend metrics of one backend.
+type Backend struct {
+	Address  string
+	Requests *Metric
+	Errors   *Metric
+	// Latency total latency of requests in nanoseconds.
+	Latency *Metric
+}
+
+// BackendStat snapshot of backend metrics.
+type BackendStat struct {
+	Address    string
+	Requests   int64
+	Errors     int64
+	AvgLatency time.Duration
+}
+
+// Backends metrics per backend, backends are added on the first response.
+type Backends struct {
+	mu     sync.RWMutex
+	holder map[string]*Backend
+}
+
+// newBackends create a new Backends.
+func newBackends() *Backends {
+	return &Backends{
+		holder: map[string]*Backend{},
+	}
+}
+
+// get return metrics of backend, metrics are created if they do not exist.
+func (b *Backends) get(address string) *Backend {
+	b.mu.RLock()
+	backend, ok := b.holder[address]
+	b.mu.RUnlock()
+	if ok {
+		return backend
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if backend, ok = b.holder[address]; ok {
+		return backend
+	}
+	backend = &Backend{
+		Address:  address,
+		Requests: &Metric{Value: &atomic.Int64{}},
+		Errors:   &Metric{Value: &atomic.Int64{}},
+		Latency:  &Metric{Value: &atomic.Int64{}},
+	}
+	b.holder[address] = backend
+	return backend
+}
+
+// Snapshot return stats of all backends sorted by address.
+func (b *Backends) Snapshot() []BackendStat {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	stats := make([]BackendStat, 0, len(b.holder))
+	for _, backend := range b.holder {
+		stat := BackendStat{
+			Address:  backend.Address,
+			Requests: backend.Requests.Value.Load(),
+			Errors:   backend.Errors.Value.Load(),
+		}
+		if stat.Requests != 0 {
+			stat.AvgLatency = time.Duration(backend.Latency.Value.Load() / stat.Requests)
+		}
+		stats = append(stats, stat)
+	}
+	slices.SortFunc(stats, func(a, b BackendStat) int {
+		return strings.Compare(a.Address, b.Address)
+	})
+	return stats
+}
+
+// reset remove all backends.
+func (b *Backends) reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.holder = map[string]*Backend{}
+}
@@ -0,0 +1,84 @@
+package metrics
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Connection metrics of client connections and their transports.
+type Connection struct {
+	// Transports established, Reconnects are transports to address whose previous transport was closed.
+	Transports  *Metric
+	Reconnects  *Metric
+	Disconnects *Metric
+	GoAways     *Metric
+	// TransientFailures transitions of connection to TRANSIENT_FAILURE state.
+	TransientFailures *Metric
+	HandshakeErrors   *Metric
+	// Connects and ConnectLatency number and total time of connecting to READY state in nanoseconds.
+	Connects       *Metric
+	ConnectLatency *Metric
+	// Handshakes and HandshakeLatency number and total time of security handshakes in nanoseconds.
+	Handshakes       *Metric
+	HandshakeLatency *Metric
+}
+
+// ConnectionStat snapshot of connection metrics.
+type ConnectionStat struct {
+	Transports          int64
+	Reconnects          int64
+	Disconnects         int64
+	GoAways             int64
+	TransientFailures   int64
+	HandshakeErrors     int64
+	AvgConnectLatency   time.Duration
+	AvgHandshakeLatency time.Duration
+}
+
+// newConnection create a new Connection.
+func newConnection() *Connection {
+	return &Connection{
+		Transports:        &Metric{Value: &atomic.Int64{}},
+		Reconnects:        &Metric{Value: &atomic.Int64{}},
+		Disconnects:       &Metric{Value: &atomic.Int64{}},
+		GoAways:           &Metric{Value: &atomic.Int64{}},
+		TransientFailures: &Metric{Value: &atomic.Int64{}},
+		HandshakeErrors:   &Metric{Value: &atomic.Int64{}},
+		Connects:          &Metric{Value: &atomic.Int64{}},
+		ConnectLatency:    &Metric{Value: &atomic.Int64{}},
+		Handshakes:        &Metric{Value: &atomic.Int64{}},
+		HandshakeLatency:  &Metric{Value: &atomic.Int64{}},
+	}
+}
+
+// Snapshot return stats of connections.
+func (c *Connection) Snapshot() ConnectionStat {
+	return ConnectionStat{
+		Transports:          c.Transports.Value.Load(),
+		Reconnects:          c.Reconnects.Value.Load(),
+		Disconnects:         c.Disconnects.Value.Load(),
+		GoAways:             c.GoAways.Value.Load(),
+		TransientFailures:   c.TransientFailures.Value.Load(),
+		HandshakeErrors:     c.HandshakeErrors.Value.Load(),
+		AvgConnectLatency:   average(c.ConnectLatency, c.Connects),
+		AvgHandshakeLatency: average(c.HandshakeLatency, c.Handshakes),
+	}
+}
+
+// reset all connection metrics.
+func (c *Connection) reset() {
+	for _, m := range []*Metric{c.Transports, c.Reconnects, c.Disconnects, c.GoAways, c.TransientFailures,
+		c.HandshakeErrors, c.Connects, c.ConnectLatency, c.Handshakes, c.HandshakeLatency} {
+		m.Value.Store(0)
+	}
+}
+
+// average return average duration of total nanoseconds by count.
+func average(total, count *Metric) time.Duration {
+	n := count.Value.Load()
+	if n == 0 {
+		return 0
+	}
+
+	return time.Duration(total.Value.Load() / n)
+}
@@ -0,0 +1,154 @@
+package metrics
+
+import (
+	"cmp"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// maxErrorMessageLen max length of normalized message in runes.
+	maxErrorMessageLen = 200
+	detailsSeparator   = "; "
+	// maxCachedMessages max count of cached normalized messages.
+	maxCachedMessages = 1024
+)
+
+// variableParts parts of error messages which differ between requests with the same cause, with their placeholders.
+// Parts are replaced in order, so digits of UUIDs, addresses and durations are not replaced as numbers.
+var variableParts = []struct {
+	re          *regexp.Regexp
+	placeholder string
+}{
+	{
+		re:          regexp.MustCompile(`(?i)\b[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}\b`),
+		placeholder: "<uuid>",
+	},
+	{re: regexp.MustCompile(`(?i)\b(0x[0-9a-f]+|[0-9a-f]{16,})\b`), placeholder: "<hex>"},
+	{re: regexp.MustCompile(`\b\d{1,3}(\.\d{1,3}){3}(:\d+)?\b`), placeholder: "<ip>"},
+	{re: regexp.MustCompile(`\b\d+(\.\d+)?(ns|us|µs|ms|s|m|h)\b`), placeholder: "<duration>"},
+	{re: regexp.MustCompile(`\b\d+(\.\d+)?\b`), placeholder: "<n>"},
+}
+
+// ErrorKey dimensions of failed attempts, message is normalized and detail describes google.rpc.Status details.
+type ErrorKey struct {
+	Code    codes.Code
+	Message string
+	Detail  string
+}
+
+// ErrorStat count of failed attempts with key.
+type ErrorStat struct {
+	ErrorKey
+	Count int64
+}
+
+// TopErrors return at most n most frequent errors.
+func (m *Metrics) TopErrors(n int) []ErrorStat {
+	counts := m.Errors.Snapshot()
+	stats := make([]ErrorStat, 0, len(counts))
+	for key, count := range counts {
+		if count != 0 {
+			stats = append(stats, ErrorStat{ErrorKey: key, Count: count})
+		}
+	}
+	slices.SortFunc(stats, func(a, b ErrorStat) int {
+		return cmp.Or(cmp.Compare(b.Count, a.Count), cmp.Compare(a.Code, b.Code),
+			strings.Compare(a.Message, b.Message), strings.Compare(a.Detail, b.Detail))
+	})
+
+	return stats[:min(n, len(stats))]
+}
+
+// newErrorKey make key of failed attempt with status.
+func newErrorKey(st *status.Status, messages *messageCache) ErrorKey {
+	return ErrorKey{
+		Code:    st.Code(),
+		Message: messages.normalize(st.Message()),
+		Detail:  describeDetails(st.Details()),
+	}
+}
+
+// messageCache normalized messages by raw messages, so regexps run once per distinct message.
+// Messages are not cached when cache is full, since messages with unknown variable parts may be all unique.
+type messageCache struct {
+	mu       sync.RWMutex
+	messages map[string]string
+}
+
+// newMessageCache create a new messageCache.
+func newMessageCache() *messageCache {
+	return &messageCache{
+		messages: map[string]string{},
+	}
+}
+
+// normalize return normalized message from cache, message is normalized and cached if it is not found.
+func (c *messageCache) normalize(msg string) string {
+	c.mu.RLock()
+	normalized, ok := c.messages[msg]
+	c.mu.RUnlock()
+	if ok {
+		return normalized
+	}
+
+	normalized = normalizeMessage(msg)
+	c.mu.Lock()
+	if len(c.messages) < maxCachedMessages {
+		c.messages[msg] = normalized
+	}
+	c.mu.Unlock()
+
+	return normalized
+}
+
+// normalizeMessage replace variable parts of message with placeholders, so messages with the same cause are equal.
+func normalizeMessage(msg string) string {
+	for _, part := range variableParts {
+		msg = part.re.ReplaceAllString(msg, part.placeholder)
+	}
+	if runes := []rune(msg); len(runes) > maxErrorMessageLen {
+		msg = string(runes[:maxErrorMessageLen]) + "…"
+	}
+
+	return msg
+}
+
+// describeDetails describe details of google.rpc.Status, details of unknown types are described by type name.
+func describeDetails(details []any) string {
+	parts := make([]string, 0, len(details))
+	for _, d := range details {
+		switch d := d.(type) {
+		case *errdetails.ErrorInfo:
+			parts = append(parts, fmt.Sprintf("ErrorInfo: reason %s, domain %s", d.GetReason(), d.GetDomain()))
+		case *errdetails.RetryInfo:
+			parts = append(parts, fmt.Sprintf("RetryInfo: retry after %s",
+				d.GetRetryDelay().AsDuration().Round(time.Millisecond)))
+		case *errdetails.QuotaFailure:
+			for _, v := range d.GetViolations() {
+				parts = append(parts, fmt.Sprintf("QuotaFailure: %s: %s", normalizeMessage(v.GetSubject()),
+					v.GetDescription()))
+			}
+		case *errdetails.BadRequest:
+			for _, v := range d.GetFieldViolations() {
+				parts = append(parts, fmt.Sprintf("BadRequest: field %s: %s", v.GetField(),
+					normalizeMessage(v.GetDescription())))
+			}
+		case proto.Message:
+			parts = append(parts, string(d.ProtoReflect().Descriptor().FullName()))
+		case error:
+			parts = append(parts, "undecodable detail")
+		}
+	}
+
+	return strings.Join(parts, detailsSeparator)
+}
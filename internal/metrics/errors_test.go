@@ -0,0 +1,81 @@
+package metrics
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func TestNormalizeMessage(t *testing.T) {
+	tests := []struct {
+		msg  string
+		want string
+	}{
+		{msg: "field user_id: must be UUID", want: "field user_id: must be UUID"},
+		{msg: "user 8a3a6e0c-4b1f-4e6a-9d2b-0a1b2c3d4e5f not found", want: "user <uuid> not found"},
+		{msg: "order 12345 is locked by 0xDEADBEEF", want: "order <n> is locked by <hex>"},
+		{msg: "trace 4bf92f3577b34da6a3ce929d0e0e4736 failed", want: "trace <hex> failed"},
+		{msg: "dial tcp 10.0.0.12:8080: connection refused", want: "dial tcp <ip>: connection refused"},
+		{msg: "timeout after 1.5s, limit 250ms", want: "timeout after <duration>, limit <duration>"},
+		{msg: "api v2 is deprecated", want: "api v2 is deprecated"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.msg, func(t *testing.T) {
+			assert.Equal(t, tt.want, normalizeMessage(tt.msg))
+		})
+	}
+}
+
+func TestMessageCache(t *testing.T) {
+	c := newMessageCache()
+	assert.Equal(t, "user <n> not found", c.normalize("user 1 not found"))
+	assert.Equal(t, "user <n> not found", c.normalize("user 1 not found"))
+	assert.Len(t, c.messages, 1)
+
+	for i := range maxCachedMessages + 10 {
+		assert.Equal(t, "user <n> not found", c.normalize(fmt.Sprintf("user %d not found", i+2)))
+	}
+	assert.Len(t, c.messages, maxCachedMessages)
+}
+
+func TestMetrics_TopErrors(t *testing.T) {
+	m := InitMetrics()
+	st, err := status.New(codes.InvalidArgument, "invalid request 1").WithDetails(
+		&errdetails.BadRequest{FieldViolations: []*errdetails.BadRequest_FieldViolation{
+			{Field: "user_id", Description: "must be UUID"},
+		}},
+		&errdetails.ErrorInfo{Reason: "INVALID_USER", Domain: "users.example.com"},
+	)
+	require.NoError(t, err)
+	quota, err := status.New(codes.ResourceExhausted, "quota exceeded").WithDetails(
+		&errdetails.QuotaFailure{Violations: []*errdetails.QuotaFailure_Violation{
+			{Subject: "clientip:10.0.0.1", Description: "daily limit"},
+		}},
+		&errdetails.RetryInfo{RetryDelay: durationpb.New(30 * time.Second)},
+	)
+	require.NoError(t, err)
+
+	for range 3 {
+		m.ObserveOutcome(OutcomeStatus, st)
+	}
+	m.ObserveOutcome(OutcomeStatus, status.New(codes.InvalidArgument, "invalid request 2"))
+	m.ObserveOutcome(OutcomeStatus, quota)
+	m.ObserveOutcome(OutcomeStatus, quota)
+	m.ObserveOutcome(OutcomeStatus, status.New(codes.OK, ""))
+
+	assert.Equal(t, []ErrorStat{
+		{ErrorKey: ErrorKey{Code: codes.InvalidArgument, Message: "invalid request <n>",
+			Detail: "BadRequest: field user_id: must be UUID; ErrorInfo: reason INVALID_USER, domain users.example.com"},
+			Count: 3},
+		{ErrorKey: ErrorKey{Code: codes.ResourceExhausted, Message: "quota exceeded",
+			Detail: "QuotaFailure: clientip:<ip>: daily limit; RetryInfo: retry after 30s"}, Count: 2},
+	}, m.TopErrors(2))
+	assert.Len(t, m.TopErrors(10), 3)
+}
@@ -0,0 +1,157 @@
+package metrics
+
+import (
+	"math"
+	"math/bits"
+	"sync/atomic"
+)
+
+const (
+	// histogramSubBucketBits precision of histogram, every power of two is split to 2^bits buckets,
+	// so relative error of values is less than 1/2^bits.
+	histogramSubBucketBits = 6
+	histogramSubBuckets    = 1 << histogramSubBucketBits
+	// histogramBuckets number of buckets which cover all non-negative int64 values.
+	histogramBuckets = (64 - histogramSubBucketBits) * histogramSubBuckets
+)
+
+// Histogram concurrent histogram of non-negative values, e.g. durations in nanoseconds or sizes in bytes,
+// with logarithmic buckets of fixed relative precision, like HDR histogram.
+type Histogram struct {
+	counts [histogramBuckets]atomic.Int64
+	sum    atomic.Int64
+	min    atomic.Int64
+	max    atomic.Int64
+}
+
+// HistogramStat snapshot of histogram with percentiles.
+type HistogramStat struct {
+	Count int64
+	Min   int64
+	Mean  int64
+	P50   int64
+	P90   int64
+	P95   int64
+	P99   int64
+	P999  int64
+	Max   int64
+}
+
+// NewHistogram create a new Histogram.
+func NewHistogram() *Histogram {
+	h := &Histogram{}
+	h.min.Store(math.MaxInt64)
+	return h
+}
+
+// Record add value to histogram, negative values are recorded as zero.
+func (h *Histogram) Record(v int64) {
+	v = max(v, 0)
+	h.counts[bucketIndex(v)].Add(1)
+	h.sum.Add(v)
+	for cur := h.min.Load(); v < cur && !h.min.CompareAndSwap(cur, v); cur = h.min.Load() {
+	}
+	for cur := h.max.Load(); v > cur && !h.max.CompareAndSwap(cur, v); cur = h.max.Load() {
+	}
+}
+
+// Snapshot return stats of histogram. Percentiles are upper bounds of buckets limited by max.
+func (h *Histogram) Snapshot() HistogramStat {
+	var counts [histogramBuckets]int64
+	var total int64
+	for i := range h.counts {
+		counts[i] = h.counts[i].Load()
+		total += counts[i]
+	}
+	if total == 0 {
+		return HistogramStat{}
+	}
+
+	maxValue := h.max.Load()
+	percentile := func(q float64) int64 {
+		rank := int64(math.Ceil(q * float64(total)))
+		var cumulative int64
+		for i, c := range counts {
+			cumulative += c
+			if cumulative >= rank {
+				return min(bucketUpperBound(i), maxValue)
+			}
+		}
+		return maxValue
+	}
+
+	return HistogramStat{
+		Count: total,
+		Min:   h.min.Load(),
+		Mean:  h.sum.Load() / total,
+		P50:   percentile(0.5),
+		P90:   percentile(0.9),
+		P95:   percentile(0.95),
+		P99:   percentile(0.99),
+		P999:  percentile(0.999),
+		Max:   maxValue,
+	}
+}
+
+// Buckets return cumulative counts of values less than or equal to bounds, total count and sum of values.
+// Value is counted for bound if the lowest value of its bucket is not greater than bound.
+func (h *Histogram) Buckets(bounds []int64) ([]int64, int64, int64) {
+	cumulative := make([]int64, len(bounds))
+	var total int64
+	for i := range h.counts {
+		c := h.counts[i].Load()
+		if c == 0 {
+			continue
+		}
+		total += c
+		lower := bucketLowerBound(i)
+		for j, bound := range bounds {
+			if lower <= bound {
+				cumulative[j] += c
+			}
+		}
+	}
+
+	return cumulative, total, h.sum.Load()
+}
+
+// reset remove all values.
+func (h *Histogram) reset() {
+	for i := range h.counts {
+		h.counts[i].Store(0)
+	}
+	h.sum.Store(0)
+	h.min.Store(math.MaxInt64)
+	h.max.Store(0)
+}
+
+// bucketIndex return index of bucket of value. Values less than number of sub-buckets have own buckets,
+// larger values are grouped by their highest bits.
+func bucketIndex(v int64) int {
+	if v < histogramSubBuckets {
+		return int(v)
+	}
+	n := bits.Len64(uint64(v))
+	shift := n - histogramSubBucketBits - 1
+	return (n-histogramSubBucketBits)*histogramSubBuckets + int(v>>shift) - histogramSubBuckets
+}
+
+// bucketLowerBound return the smallest value of bucket.
+func bucketLowerBound(i int) int64 {
+	group := i / histogramSubBuckets
+	if group == 0 {
+		return int64(i)
+	}
+
+	return int64(i%histogramSubBuckets+histogramSubBuckets) << (group - 1)
+}
+
+// bucketUpperBound return the largest value of bucket.
+func bucketUpperBound(i int) int64 {
+	group := i / histogramSubBuckets
+	if group == 0 {
+		return int64(i)
+	}
+
+	return bucketLowerBound(i) + (1 << (group - 1)) - 1
+}
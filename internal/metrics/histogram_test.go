@@ -0,0 +1,94 @@
+package metrics
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+)
+
+func TestHistogram(t *testing.T) {
+	t.Run("Test bucket bounds", func(t *testing.T) {
+		values := []int64{0, 1, 63, 64, 65, 127, 128, 1000, 123456789, int64(time.Hour), 1<<63 - 1}
+		for _, v := range values {
+			i := bucketIndex(v)
+			assert.Less(t, i, histogramBuckets, v)
+			assert.GreaterOrEqual(t, bucketUpperBound(i), v, v)
+			assert.LessOrEqual(t, bucketLowerBound(i), v, v)
+			if i > 0 {
+				assert.Less(t, bucketUpperBound(i-1), v, v)
+			}
+		}
+	})
+
+	t.Run("Test snapshot of concurrent records", func(t *testing.T) {
+		h := NewHistogram()
+		assert.Equal(t, HistogramStat{}, h.Snapshot())
+
+		var wg sync.WaitGroup
+		for i := 1; i <= 1000; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				h.Record(int64(i) * int64(time.Millisecond))
+			}()
+		}
+		wg.Wait()
+
+		stat := newLatencyStat(h.Snapshot())
+		assert.Equal(t, int64(1000), stat.Count)
+		assert.Equal(t, time.Millisecond, stat.Min)
+		assert.Equal(t, time.Second, stat.Max)
+		assert.InEpsilon(t, 500500*time.Microsecond, stat.Mean, 0.001)
+		percentiles := map[time.Duration]time.Duration{
+			500 * time.Millisecond: stat.P50,
+			900 * time.Millisecond: stat.P90,
+			950 * time.Millisecond: stat.P95,
+			990 * time.Millisecond: stat.P99,
+			999 * time.Millisecond: stat.P999,
+		}
+		for want, got := range percentiles {
+			assert.GreaterOrEqual(t, got, want)
+			assert.InEpsilon(t, want, got, 1.0/histogramSubBuckets)
+		}
+
+		h.reset()
+		assert.Equal(t, HistogramStat{}, h.Snapshot())
+	})
+
+	t.Run("Test cumulative buckets", func(t *testing.T) {
+		h := NewHistogram()
+		for _, d := range []time.Duration{time.Millisecond, 5 * time.Millisecond, 10 * time.Millisecond, time.Second} {
+			h.Record(int64(d))
+		}
+
+		counts, total, sum := h.Buckets(Nanoseconds([]time.Duration{0, time.Millisecond, 10 * time.Millisecond,
+			100 * time.Millisecond}))
+		assert.Equal(t, []int64{0, 1, 3, 3}, counts)
+		assert.Equal(t, int64(4), total)
+		assert.Equal(t, int64(1016*time.Millisecond), sum)
+	})
+}
+
+func TestMetrics_ObserveLatency(t *testing.T) {
+	m := InitMetrics()
+	m.ObserveLatency(codes.OK, 10*time.Millisecond)
+	m.ObserveLatency(codes.OK, 20*time.Millisecond)
+	m.ObserveLatency(codes.Unavailable, time.Second)
+
+	t.Run("Test latency by code", func(t *testing.T) {
+		assert.Equal(t, int64(3), m.Latency.Snapshot().Count)
+		byCode := m.Latency.ByCode()
+		assert.Len(t, byCode, 2)
+		assert.Equal(t, int64(2), byCode[codes.OK].Count)
+		assert.Equal(t, time.Second, byCode[codes.Unavailable].Max)
+	})
+
+	t.Run("Test reset", func(t *testing.T) {
+		m.Reset()
+		assert.Empty(t, m.Latency.ByCode())
+		assert.Zero(t, m.Latency.Snapshot().Count)
+	})
+}
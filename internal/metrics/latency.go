@@ -0,0 +1,146 @@
+package metrics
+
+import (
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc/codes"
+)
+
+// maxCode the last known status code.
+const maxCode = codes.Unauthenticated
+
+// LatencyStat snapshot of histogram of latency with percentiles.
+type LatencyStat struct {
+	Count int64
+	Min   time.Duration
+	Mean  time.Duration
+	P50   time.Duration
+	P90   time.Duration
+	P95   time.Duration
+	P99   time.Duration
+	P999  time.Duration
+	Max   time.Duration
+}
+
+// Latency histograms of latency of requests in nanoseconds, total and per status code.
+type Latency struct {
+	Total *Histogram
+	// window histogram of the current window of time series.
+	window atomic.Pointer[Histogram]
+	// byCode histograms are created on the first response with code.
+	byCode [maxCode + 1]atomic.Pointer[Histogram]
+}
+
+// newLatency create a new Latency.
+func newLatency() *Latency {
+	l := &Latency{
+		Total: NewHistogram(),
+	}
+	l.window.Store(NewHistogram())
+	return l
+}
+
+// record add latency to total, window and code histograms.
+func (l *Latency) record(code codes.Code, latency time.Duration) {
+	l.Total.Record(int64(latency))
+	l.window.Load().Record(int64(latency))
+	if h := l.code(code); h != nil {
+		h.Record(int64(latency))
+	}
+}
+
+// rotateWindow start a new window and return stats of the previous one.
+// Latencies recorded concurrently with rotation could be lost for windows, but not for totals.
+func (l *Latency) rotateWindow() LatencyStat {
+	return newLatencyStat(l.window.Swap(NewHistogram()).Snapshot())
+}
+
+// Snapshot return stats of total latency.
+func (l *Latency) Snapshot() LatencyStat {
+	return newLatencyStat(l.Total.Snapshot())
+}
+
+// ByCode return stats of latency per status code, codes without responses are skipped.
+func (l *Latency) ByCode() map[codes.Code]LatencyStat {
+	stats := map[codes.Code]LatencyStat{}
+	for code := range l.byCode {
+		h := l.byCode[code].Load()
+		if h == nil {
+			continue
+		}
+		if stat := h.Snapshot(); stat.Count > 0 {
+			stats[codes.Code(code)] = newLatencyStat(stat)
+		}
+	}
+
+	return stats
+}
+
+// newLatencyStat convert stats of histogram of latencies in nanoseconds.
+func newLatencyStat(s HistogramStat) LatencyStat {
+	return LatencyStat{
+		Count: s.Count,
+		Min:   time.Duration(s.Min),
+		Mean:  time.Duration(s.Mean),
+		P50:   time.Duration(s.P50),
+		P90:   time.Duration(s.P90),
+		P95:   time.Duration(s.P95),
+		P99:   time.Duration(s.P99),
+		P999:  time.Duration(s.P999),
+		Max:   time.Duration(s.Max),
+	}
+}
+
+// LatencyBuckets upper bounds of buckets of exported latency histograms.
+var LatencyBuckets = []time.Duration{
+	time.Millisecond, 2500 * time.Microsecond, 5 * time.Millisecond, 10 * time.Millisecond, 25 * time.Millisecond,
+	50 * time.Millisecond, 100 * time.Millisecond, 250 * time.Millisecond, 500 * time.Millisecond, time.Second,
+	2500 * time.Millisecond, 5 * time.Second, 10 * time.Second,
+}
+
+// Nanoseconds convert durations to values of histograms of latency, e.g. to bounds of buckets.
+func Nanoseconds(ds []time.Duration) []int64 {
+	values := make([]int64, len(ds))
+	for i, d := range ds {
+		values[i] = int64(d)
+	}
+
+	return values
+}
+
+// Histograms return histograms in nanoseconds per status code, codes without responses are skipped.
+func (l *Latency) Histograms() map[codes.Code]*Histogram {
+	histograms := map[codes.Code]*Histogram{}
+	for code := range l.byCode {
+		if h := l.byCode[code].Load(); h != nil {
+			histograms[codes.Code(code)] = h
+		}
+	}
+
+	return histograms
+}
+
+// code return histogram of status code, unknown codes have no histogram.
+func (l *Latency) code(code codes.Code) *Histogram {
+	if code > maxCode {
+		return nil
+	}
+	if h := l.byCode[code].Load(); h != nil {
+		return h
+	}
+	l.byCode[code].CompareAndSwap(nil, NewHistogram())
+
+	return l.byCode[code].Load()
+}
+
+// reset latency.
+func (l *Latency) reset() {
+	l.Total.reset()
+	l.window.Store(NewHistogram())
+	for code := range l.byCode {
+		if h := l.byCode[code].Load(); h != nil {
+			h.reset()
+		}
+	}
+}
@@ -1,9 +1,12 @@
 package metrics
 
 import (
+	"context"
 	"sync/atomic"
+	"time"
 
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // Metric stat with value.
@@ -13,47 +16,43 @@ type Metric struct {
 
 // Metrics struct with needed metrics.
 type Metrics struct {
-	RequestCounter                          *Metric
-	RequestPerSecondGauge                   *Metric
-	ResponseStatusOKCounter                 *Metric
-	ResponseStatusUnknownCounter            *Metric
-	ResponseStatusCancelledCounter          *Metric
-	ResponseStatusInvalidArgumentCounter    *Metric
-	ResponseStatusDeadlineExceededCounter   *Metric
-	ResponseStatusNotFoundCounter           *Metric
-	ResponseStatusAlreadyExistsCounter      *Metric
-	ResponseStatusPermissionDeniedCounter   *Metric
-	ResponseStatusResourceExhaustedCounter  *Metric
-	ResponseStatusFailedPreconditionCounter *Metric
-	ResponseStatusAbortedCounter            *Metric
-	ResponseStatusOutOfRangeCounter         *Metric
-	ResponseStatusUnimplementedCounter      *Metric
-	ResponseStatusUnavailableCounter        *Metric
-	ResponseStatusDataLossCounter           *Metric
-	ResponseStatusUnauthenticatedCounter    *Metric
+	RequestCounter        *Metric
+	RequestPerSecondGauge *Metric
+	InFlightGauge         *Metric
+	// Statuses counters of responses by status code, all codes are registered.
+	Statuses *Counters[codes.Code]
+	// Outcomes counters of attempts by outcome, their sum is equal to RequestCounter when no request is in flight.
+	Outcomes *Counters[Outcome]
+	// Errors counters of failed attempts by code, normalized message and details.
+	Errors     *Counters[ErrorKey]
+	Assertions *Assertions
+	Backends   *Backends
+	Connection *Connection
+	Latency    *Latency
+	Payload    *Payload
+	Series     *Series
+	// messages cache of normalized messages of Errors.
+	messages *messageCache
 }
 
 // InitMetrics initialize metrics.
 func InitMetrics() *Metrics {
 	return &Metrics{
-		RequestCounter:                          &Metric{Value: &atomic.Int64{}},
-		RequestPerSecondGauge:                   &Metric{Value: &atomic.Int64{}},
-		ResponseStatusOKCounter:                 &Metric{Value: &atomic.Int64{}},
-		ResponseStatusUnknownCounter:            &Metric{Value: &atomic.Int64{}},
-		ResponseStatusCancelledCounter:          &Metric{Value: &atomic.Int64{}},
-		ResponseStatusInvalidArgumentCounter:    &Metric{Value: &atomic.Int64{}},
-		ResponseStatusDeadlineExceededCounter:   &Metric{Value: &atomic.Int64{}},
-		ResponseStatusNotFoundCounter:           &Metric{Value: &atomic.Int64{}},
-		ResponseStatusAlreadyExistsCounter:      &Metric{Value: &atomic.Int64{}},
-		ResponseStatusPermissionDeniedCounter:   &Metric{Value: &atomic.Int64{}},
-		ResponseStatusResourceExhaustedCounter:  &Metric{Value: &atomic.Int64{}},
-		ResponseStatusFailedPreconditionCounter: &Metric{Value: &atomic.Int64{}},
-		ResponseStatusAbortedCounter:            &Metric{Value: &atomic.Int64{}},
-		ResponseStatusOutOfRangeCounter:         &Metric{Value: &atomic.Int64{}},
-		ResponseStatusUnimplementedCounter:      &Metric{Value: &atomic.Int64{}},
-		ResponseStatusUnavailableCounter:        &Metric{Value: &atomic.Int64{}},
-		ResponseStatusDataLossCounter:           &Metric{Value: &atomic.Int64{}},
-		ResponseStatusUnauthenticatedCounter:    &Metric{Value: &atomic.Int64{}},
+		RequestCounter:        &Metric{Value: &atomic.Int64{}},
+		RequestPerSecondGauge: &Metric{Value: &atomic.Int64{}},
+		InFlightGauge:         &Metric{Value: &atomic.Int64{}},
+		Statuses:              newCounters(statusCodes()...),
+		Outcomes:              newCounters(outcomes...),
+		Errors: newCounters[ErrorKey]().withLimit(maxErrorKeys, func(k ErrorKey) ErrorKey {
+			return ErrorKey{Code: k.Code, Message: otherErrorMessage}
+		}),
+		Assertions: newAssertions(),
+		Backends:   newBackends(),
+		Connection: newConnection(),
+		Latency:    newLatency(),
+		Payload:    newPayload(),
+		Series:     newSeries(),
+		messages:   newMessageCache(),
 	}
 }
 
@@ -67,61 +66,144 @@ func (m *Metrics) IncrementRequestCount() {
 	m.RequestCounter.Value.Add(1)
 }
 
-// IncrementResponseStatus increment response status by code.
-func (m *Metrics) IncrementResponseStatus(code codes.Code) {
-	switch code {
-	case codes.OK:
-		m.ResponseStatusOKCounter.Value.Add(1)
-	case codes.Unknown:
-		m.ResponseStatusUnknownCounter.Value.Add(1)
-	case codes.Canceled:
-		m.ResponseStatusCancelledCounter.Value.Add(1)
-	case codes.InvalidArgument:
-		m.ResponseStatusInvalidArgumentCounter.Value.Add(1)
-	case codes.DeadlineExceeded:
-		m.ResponseStatusDeadlineExceededCounter.Value.Add(1)
-	case codes.NotFound:
-		m.ResponseStatusNotFoundCounter.Value.Add(1)
-	case codes.AlreadyExists:
-		m.ResponseStatusAlreadyExistsCounter.Value.Add(1)
-	case codes.PermissionDenied:
-		m.ResponseStatusPermissionDeniedCounter.Value.Add(1)
-	case codes.ResourceExhausted:
-		m.ResponseStatusResourceExhaustedCounter.Value.Add(1)
-	case codes.FailedPrecondition:
-		m.ResponseStatusFailedPreconditionCounter.Value.Add(1)
-	case codes.Aborted:
-		m.ResponseStatusAbortedCounter.Value.Add(1)
-	case codes.OutOfRange:
-		m.ResponseStatusOutOfRangeCounter.Value.Add(1)
-	case codes.Unimplemented:
-		m.ResponseStatusUnimplementedCounter.Value.Add(1)
-	case codes.Unavailable:
-		m.ResponseStatusUnavailableCounter.Value.Add(1)
-	case codes.DataLoss:
-		m.ResponseStatusDataLossCounter.Value.Add(1)
-	case codes.Unauthenticated:
-		m.ResponseStatusUnauthenticatedCounter.Value.Add(1)
+// AddInFlight add delta to InFlightGauge.
+func (m *Metrics) AddInFlight(delta int64) {
+	m.InFlightGauge.Value.Add(delta)
+}
+
+// ObserveOutcome count outcome of attempt. Status is counted for all outcomes except OutcomeAssertion,
+// so deadlines and transport errors are counted by their codes and responses which failed assertions
+// are not counted as successful. Failed attempts of all outcomes are also counted by code,
+// normalized message and details.
+func (m *Metrics) ObserveOutcome(outcome Outcome, st *status.Status) {
+	m.Outcomes.Increment(outcome)
+	if outcome != OutcomeAssertion {
+		m.Statuses.Increment(st.Code())
+	}
+	if st.Code() != codes.OK {
+		m.Errors.Increment(newErrorKey(st, m.messages))
+	}
+}
+
+// ObserveAssertionPass count response which passed assertions.
+func (m *Metrics) ObserveAssertionPass() {
+	m.Assertions.Passed.Value.Add(1)
+}
+
+// ObserveAssertionFailure count failed assertion with message about actual value of response.
+func (m *Metrics) ObserveAssertionFailure(assertion, message string) {
+	m.Assertions.observeFailure(assertion, message)
+}
+
+// StatusCounts return counts of responses by status code.
+func (m *Metrics) StatusCounts() map[codes.Code]int64 {
+	return m.Statuses.Snapshot()
+}
+
+// Sample add point of current metrics to Series.
+func (m *Metrics) Sample(now time.Time) Point {
+	return m.Series.add(now, seriesTotals{
+		requests: m.RequestCounter.Value.Load(),
+		sent:     m.Payload.WireSent.Value.Load(),
+		received: m.Payload.WireReceived.Value.Load(),
+		statuses: m.StatusCounts(),
+		outcomes: m.Outcomes.Snapshot(),
+	}, Point{
+		TargetRPS: m.RequestPerSecondGauge.Value.Load(),
+		InFlight:  m.InFlightGauge.Value.Load(),
+		Latency:   m.Latency.rotateWindow(),
+	})
+}
+
+// CollectSeries add point to Series every second until context is done.
+func (m *Metrics) CollectSeries(ctx context.Context) {
+	ticker := time.NewTicker(seriesInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			m.Sample(now)
+		}
+	}
+}
+
+// ObserveBackend add response of backend to its metrics.
+func (m *Metrics) ObserveBackend(address string, latency time.Duration, failed bool) {
+	backend := m.Backends.get(address)
+	backend.Requests.Value.Add(1)
+	backend.Latency.Value.Add(int64(latency))
+	if failed {
+		backend.Errors.Value.Add(1)
+	}
+}
+
+// ObserveLatency add latency of response with status code.
+func (m *Metrics) ObserveLatency(code codes.Code, latency time.Duration) {
+	m.Latency.record(code, latency)
+}
+
+// ObserveSent add size of sent message, wire size includes compression and framing, it is zero if unknown.
+func (m *Metrics) ObserveSent(size, wire int) {
+	m.Payload.observeSent(size, wire)
+}
+
+// ObserveReceived add size of received message, wire size includes compression and framing, it is zero if unknown.
+func (m *Metrics) ObserveReceived(size, wire int) {
+	m.Payload.observeReceived(size, wire)
+}
+
+// ObserveTransportOpen count established transport, reconnect is true if previous transport to address was closed.
+func (m *Metrics) ObserveTransportOpen(reconnect bool) {
+	m.Connection.Transports.Value.Add(1)
+	if reconnect {
+		m.Connection.Reconnects.Value.Add(1)
 	}
 }
 
+// ObserveTransportClose count closed transport.
+func (m *Metrics) ObserveTransportClose() {
+	m.Connection.Disconnects.Value.Add(1)
+}
+
+// ObserveConnect add time of connecting to READY state.
+func (m *Metrics) ObserveConnect(latency time.Duration) {
+	m.Connection.Connects.Value.Add(1)
+	m.Connection.ConnectLatency.Value.Add(int64(latency))
+}
+
+// ObserveHandshake add time of security handshake, failed handshakes are counted as errors only.
+func (m *Metrics) ObserveHandshake(latency time.Duration, failed bool) {
+	if failed {
+		m.Connection.HandshakeErrors.Value.Add(1)
+		return
+	}
+	m.Connection.Handshakes.Value.Add(1)
+	m.Connection.HandshakeLatency.Value.Add(int64(latency))
+}
+
+// IncrementGoAway increment received GOAWAY frames.
+func (m *Metrics) IncrementGoAway() {
+	m.Connection.GoAways.Value.Add(1)
+}
+
+// IncrementTransientFailure increment transitions of connection to TRANSIENT_FAILURE state.
+func (m *Metrics) IncrementTransientFailure() {
+	m.Connection.TransientFailures.Value.Add(1)
+}
+
 // Reset all metrics.
 func (m *Metrics) Reset() {
 	m.RequestCounter.Value.Store(0)
-	m.ResponseStatusOKCounter.Value.Store(0)
-	m.ResponseStatusUnknownCounter.Value.Store(0)
-	m.ResponseStatusCancelledCounter.Value.Store(0)
-	m.ResponseStatusInvalidArgumentCounter.Value.Store(0)
-	m.ResponseStatusDeadlineExceededCounter.Value.Store(0)
-	m.ResponseStatusNotFoundCounter.Value.Store(0)
-	m.ResponseStatusAlreadyExistsCounter.Value.Store(0)
-	m.ResponseStatusPermissionDeniedCounter.Value.Store(0)
-	m.ResponseStatusResourceExhaustedCounter.Value.Store(0)
-	m.ResponseStatusFailedPreconditionCounter.Value.Store(0)
-	m.ResponseStatusAbortedCounter.Value.Store(0)
-	m.ResponseStatusOutOfRangeCounter.Value.Store(0)
-	m.ResponseStatusUnimplementedCounter.Value.Store(0)
-	m.ResponseStatusUnavailableCounter.Value.Store(0)
-	m.ResponseStatusDataLossCounter.Value.Store(0)
-	m.ResponseStatusUnauthenticatedCounter.Value.Store(0)
+	m.Statuses.reset()
+	m.Outcomes.reset()
+	m.Errors.reset()
+	m.Assertions.reset()
+	m.Backends.reset()
+	m.Connection.reset()
+	m.Latency.reset()
+	m.Payload.reset()
+	m.Series.reset()
 }
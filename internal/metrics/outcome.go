@@ -0,0 +1,39 @@
+package metrics
+
+// Outcome class of result of request attempt, every attempt has exactly one outcome.
+type Outcome int
+
+// Available values for Outcome.
+const (
+	// OutcomeStatus response with status was received.
+	OutcomeStatus Outcome = iota
+	// OutcomeTransport request failed in transport before response was received.
+	OutcomeTransport
+	// OutcomeMarshal request message failed to build or marshal on client.
+	OutcomeMarshal
+	// OutcomeDeadline deadline of request exceeded on client.
+	OutcomeDeadline
+	// OutcomeAssertion response was received, but it failed assertions of request.
+	OutcomeAssertion
+)
+
+// outcomes all outcomes.
+var outcomes = []Outcome{OutcomeStatus, OutcomeTransport, OutcomeMarshal, OutcomeDeadline, OutcomeAssertion}
+
+// String return name of outcome.
+func (o Outcome) String() string {
+	switch o {
+	case OutcomeStatus:
+		return "status"
+	case OutcomeTransport:
+		return "transport_error"
+	case OutcomeMarshal:
+		return "marshal_error"
+	case OutcomeDeadline:
+		return "deadline"
+	case OutcomeAssertion:
+		return "assertion_failed"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,106 @@
+package metrics
+
+import (
+	"sync/atomic"
+)
+
+// Payload metrics of sizes of request and response messages.
+type Payload struct {
+	// Sent and Received total serialized size of messages in bytes.
+	Sent     *Metric
+	Received *Metric
+	// WireSent and WireReceived total size of messages on wire with compression and framing in bytes.
+	WireSent     *Metric
+	WireReceived *Metric
+	// sentSizes and receivedSizes histograms of serialized sizes in bytes.
+	sentSizes     *Histogram
+	receivedSizes *Histogram
+}
+
+// SizeStat snapshot of sizes of messages in bytes with percentiles.
+type SizeStat struct {
+	Count int64
+	Min   int64
+	Mean  int64
+	P50   int64
+	P90   int64
+	P99   int64
+	Max   int64
+}
+
+// PayloadStat snapshot of payload metrics.
+type PayloadStat struct {
+	Sent          int64
+	Received      int64
+	WireSent      int64
+	WireReceived  int64
+	SentSizes     SizeStat
+	ReceivedSizes SizeStat
+}
+
+// newPayload create a new Payload.
+func newPayload() *Payload {
+	return &Payload{
+		Sent:          &Metric{Value: &atomic.Int64{}},
+		Received:      &Metric{Value: &atomic.Int64{}},
+		WireSent:      &Metric{Value: &atomic.Int64{}},
+		WireReceived:  &Metric{Value: &atomic.Int64{}},
+		sentSizes:     NewHistogram(),
+		receivedSizes: NewHistogram(),
+	}
+}
+
+// Snapshot return stats of payload.
+func (p *Payload) Snapshot() PayloadStat {
+	return PayloadStat{
+		Sent:          p.Sent.Value.Load(),
+		Received:      p.Received.Value.Load(),
+		WireSent:      p.WireSent.Value.Load(),
+		WireReceived:  p.WireReceived.Value.Load(),
+		SentSizes:     newSizeStat(p.sentSizes.Snapshot()),
+		ReceivedSizes: newSizeStat(p.receivedSizes.Snapshot()),
+	}
+}
+
+// reset all values.
+func (p *Payload) reset() {
+	p.Sent.Value.Store(0)
+	p.Received.Value.Store(0)
+	p.WireSent.Value.Store(0)
+	p.WireReceived.Value.Store(0)
+	p.sentSizes.reset()
+	p.receivedSizes.reset()
+}
+
+// newSizeStat convert stats of histogram of sizes.
+func newSizeStat(s HistogramStat) SizeStat {
+	return SizeStat{
+		Count: s.Count,
+		Min:   s.Min,
+		Mean:  s.Mean,
+		P50:   s.P50,
+		P90:   s.P90,
+		P99:   s.P99,
+		Max:   s.Max,
+	}
+}
+
+// observeSent add size of sent message.
+func (p *Payload) observeSent(size, wire int) {
+	recordSize(p.sentSizes, p.Sent, p.WireSent, size, wire)
+}
+
+// observeReceived add size of received message.
+func (p *Payload) observeReceived(size, wire int) {
+	recordSize(p.receivedSizes, p.Received, p.WireReceived, size, wire)
+}
+
+// recordSize add size of message to histogram and totals, wire size is the same as size if it is unknown.
+func recordSize(h *Histogram, total, wireTotal *Metric, size, wire int) {
+	if wire <= 0 {
+		wire = size
+	}
+	h.Record(int64(size))
+	total.Value.Add(int64(size))
+	wireTotal.Value.Add(int64(wire))
+}
@@ -0,0 +1,114 @@
+package metrics
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"google.golang.org/grpc/codes"
+)
+
+const (
+	// maxErrorKeys max distinct error keys, responses with new keys are counted under otherErrorMessage.
+	maxErrorKeys = 1000
+	// otherErrorMessage message of errors over maxErrorKeys.
+	otherErrorMessage = "(other)"
+)
+
+// Counters registry of counters by key, counters are created on the first increment.
+type Counters[K comparable] struct {
+	mu     sync.RWMutex
+	holder map[K]*Metric
+	// static keys registered at creation, their counters are never removed.
+	static map[K]struct{}
+	// limit max counters, zero is unlimited.
+	limit int
+	// overflow return key for counting of new key when limit is reached.
+	overflow func(K) K
+}
+
+// newCounters create a new Counters with counters of keys.
+func newCounters[K comparable](keys ...K) *Counters[K] {
+	c := &Counters[K]{
+		holder: make(map[K]*Metric, len(keys)),
+		static: make(map[K]struct{}, len(keys)),
+	}
+	for _, k := range keys {
+		c.holder[k] = &Metric{Value: &atomic.Int64{}}
+		c.static[k] = struct{}{}
+	}
+
+	return c
+}
+
+// withLimit limit distinct keys, new keys over limit are counted under key returned by overflow.
+func (c *Counters[K]) withLimit(limit int, overflow func(K) K) *Counters[K] {
+	c.limit = limit
+	c.overflow = overflow
+	return c
+}
+
+// Get return counter of key, counter is created if it does not exist.
+func (c *Counters[K]) Get(key K) *Metric {
+	c.mu.RLock()
+	m, ok := c.holder[key]
+	c.mu.RUnlock()
+	if ok {
+		return m
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if m, ok = c.holder[key]; ok {
+		return m
+	}
+	if c.limit > 0 && len(c.holder) >= c.limit {
+		key = c.overflow(key)
+		if m, ok = c.holder[key]; ok {
+			return m
+		}
+	}
+	m = &Metric{Value: &atomic.Int64{}}
+	c.holder[key] = m
+	return m
+}
+
+// Increment increment counter of key.
+func (c *Counters[K]) Increment(key K) {
+	c.Get(key).Value.Add(1)
+}
+
+// Snapshot return values of all counters.
+func (c *Counters[K]) Snapshot() map[K]int64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	values := make(map[K]int64, len(c.holder))
+	for k, m := range c.holder {
+		values[k] = m.Value.Load()
+	}
+
+	return values
+}
+
+// reset zero counters of static keys and remove others.
+func (c *Counters[K]) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for k, m := range c.holder {
+		if _, ok := c.static[k]; ok {
+			m.Value.Store(0)
+			continue
+		}
+		delete(c.holder, k)
+	}
+}
+
+// statusCodes all status codes.
+func statusCodes() []codes.Code {
+	list := make([]codes.Code, 0, maxCode+1)
+	for code := codes.OK; code <= maxCode; code++ {
+		list = append(list, code)
+	}
+
+	return list
+}
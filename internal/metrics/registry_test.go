@@ -0,0 +1,64 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestMetrics_ObserveOutcome(t *testing.T) {
+	m := InitMetrics()
+	ok := m.Statuses.Get(codes.OK)
+	m.ObserveOutcome(OutcomeStatus, status.New(codes.OK, ""))
+	m.ObserveOutcome(OutcomeStatus, status.New(codes.Internal, "db is down"))
+	m.ObserveOutcome(OutcomeStatus, status.New(codes.Internal, "db is down"))
+	m.ObserveOutcome(OutcomeStatus, status.New(codes.Internal, "cache is down"))
+	m.ObserveOutcome(OutcomeTransport, status.New(codes.Unavailable, "connection refused"))
+	m.ObserveOutcome(OutcomeDeadline, status.New(codes.DeadlineExceeded, "context deadline exceeded"))
+	m.ObserveOutcome(OutcomeAssertion, status.New(codes.OK, ""))
+
+	t.Run("Test statuses", func(t *testing.T) {
+		counts := m.StatusCounts()
+		assert.Len(t, counts, int(maxCode)+1)
+		assert.Equal(t, int64(1), counts[codes.OK], "failed assertion is not counted as OK")
+		assert.Equal(t, int64(3), counts[codes.Internal])
+		assert.Equal(t, int64(1), counts[codes.Unavailable])
+		assert.Equal(t, int64(1), counts[codes.DeadlineExceeded])
+	})
+
+	t.Run("Test outcomes", func(t *testing.T) {
+		assert.Equal(t, map[Outcome]int64{OutcomeStatus: 4, OutcomeTransport: 1, OutcomeMarshal: 0, OutcomeDeadline: 1,
+			OutcomeAssertion: 1}, m.Outcomes.Snapshot())
+	})
+
+	t.Run("Test errors by message", func(t *testing.T) {
+		assert.Equal(t, map[ErrorKey]int64{
+			{Code: codes.Internal, Message: "db is down"}:                        2,
+			{Code: codes.Internal, Message: "cache is down"}:                     1,
+			{Code: codes.Unavailable, Message: "connection refused"}:             1,
+			{Code: codes.DeadlineExceeded, Message: "context deadline exceeded"}: 1,
+		}, m.Errors.Snapshot())
+	})
+
+	t.Run("Test reset keeps counters", func(t *testing.T) {
+		m.Reset()
+		assert.Same(t, ok, m.Statuses.Get(codes.OK))
+		assert.Zero(t, m.StatusCounts()[codes.OK])
+		assert.Empty(t, m.Errors.Snapshot())
+	})
+}
+
+func TestCounters_Limit(t *testing.T) {
+	t.Run("Test keys over limit are merged", func(t *testing.T) {
+		c := newCounters[string]().withLimit(2, func(string) string { return "other" })
+		c.Increment("a")
+		c.Increment("b")
+		c.Increment("c")
+		c.Increment("d")
+		c.Increment("a")
+
+		assert.Equal(t, map[string]int64{"a": 2, "b": 1, "other": 2}, c.Snapshot())
+	})
+}
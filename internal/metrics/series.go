@@ -0,0 +1,116 @@
+package metrics
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+)
+
+// seriesInterval interval between points of time series.
+const seriesInterval = time.Second
+
+// Point metrics of one interval of time series.
+type Point struct {
+	Time time.Time
+	// Elapsed time since start of load.
+	Elapsed time.Duration
+	// RPS requests sent during interval, TargetRPS requests per second set for load.
+	RPS       int64
+	TargetRPS int64
+	// InFlight requests waiting for response at the end of interval.
+	InFlight int64
+	// SentBytes and ReceivedBytes size of messages on wire during interval.
+	SentBytes     int64
+	ReceivedBytes int64
+	// Statuses attempts finished during interval by status code, failed assertions are not counted.
+	Statuses map[codes.Code]int64
+	// Outcomes attempts finished during interval by outcome.
+	Outcomes map[Outcome]int64
+	// Latency stats of responses received during interval.
+	Latency LatencyStat
+}
+
+// Series time series of metrics with one point per second.
+type Series struct {
+	mu     sync.RWMutex
+	start  time.Time
+	points []Point
+	prev   seriesTotals
+}
+
+// seriesTotals counters of metrics which are converted to deltas of points.
+type seriesTotals struct {
+	requests int64
+	sent     int64
+	received int64
+	statuses map[codes.Code]int64
+	outcomes map[Outcome]int64
+}
+
+// newSeries create a new Series.
+func newSeries() *Series {
+	return &Series{
+		start: time.Now(),
+	}
+}
+
+// Points return copy of points.
+func (s *Series) Points() []Point {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return slices.Clone(s.points)
+}
+
+// Last return the last point, false is returned if there are no points.
+func (s *Series) Last() (Point, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if len(s.points) == 0 {
+		return Point{}, false
+	}
+
+	return s.points[len(s.points)-1], true
+}
+
+// add point of metrics, totals are converted to deltas since previous point.
+func (s *Series) add(now time.Time, totals seriesTotals, p Point) Point {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p.Time = now
+	p.Elapsed = now.Sub(s.start)
+	p.RPS = totals.requests - s.prev.requests
+	p.SentBytes = totals.sent - s.prev.sent
+	p.ReceivedBytes = totals.received - s.prev.received
+	p.Statuses = deltas(totals.statuses, s.prev.statuses)
+	p.Outcomes = deltas(totals.outcomes, s.prev.outcomes)
+	s.prev = totals
+	s.points = append(s.points, p)
+
+	return p
+}
+
+// deltas return positive differences of counters since previous totals.
+func deltas[K comparable](totals, prev map[K]int64) map[K]int64 {
+	d := make(map[K]int64, len(totals))
+	for key, count := range totals {
+		if delta := count - prev[key]; delta > 0 {
+			d[key] = delta
+		}
+	}
+
+	return d
+}
+
+// reset series and start it from now.
+func (s *Series) reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.start = time.Now()
+	s.points = nil
+	s.prev = seriesTotals{}
+}
@@ -0,0 +1,74 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestMetrics_Sample(t *testing.T) {
+	m := InitMetrics()
+	m.SetRequestPerSecond(100)
+	start := time.Now()
+
+	for range 3 {
+		m.IncrementRequestCount()
+		m.ObserveOutcome(OutcomeStatus, status.New(codes.OK, ""))
+		m.ObserveLatency(codes.OK, 10*time.Millisecond)
+	}
+	m.AddInFlight(2)
+	m.ObserveSent(100, 40)
+	m.ObserveReceived(10, 0)
+	first := m.Sample(start.Add(time.Second))
+
+	m.IncrementRequestCount()
+	m.ObserveOutcome(OutcomeTransport, status.New(codes.Unavailable, "connection refused"))
+	m.ObserveLatency(codes.Unavailable, time.Second)
+	m.AddInFlight(-2)
+	m.ObserveSent(100, 60)
+	second := m.Sample(start.Add(2 * time.Second))
+
+	t.Run("Test first interval", func(t *testing.T) {
+		assert.Equal(t, int64(3), first.RPS)
+		assert.Equal(t, int64(100), first.TargetRPS)
+		assert.Equal(t, int64(2), first.InFlight)
+		assert.Equal(t, map[codes.Code]int64{codes.OK: 3}, first.Statuses)
+		assert.Equal(t, map[Outcome]int64{OutcomeStatus: 3}, first.Outcomes)
+		assert.Equal(t, int64(3), first.Latency.Count)
+		assert.Equal(t, int64(40), first.SentBytes)
+		assert.Equal(t, int64(10), first.ReceivedBytes)
+	})
+
+	t.Run("Test deltas of second interval", func(t *testing.T) {
+		assert.Equal(t, int64(1), second.RPS)
+		assert.Zero(t, second.InFlight)
+		assert.Equal(t, map[codes.Code]int64{codes.Unavailable: 1}, second.Statuses)
+		assert.Equal(t, map[Outcome]int64{OutcomeTransport: 1}, second.Outcomes)
+		assert.Equal(t, time.Second, second.Latency.Min)
+		assert.Equal(t, int64(60), second.SentBytes)
+		assert.Zero(t, second.ReceivedBytes)
+	})
+
+	t.Run("Test totals are not reset by sampling", func(t *testing.T) {
+		assert.Equal(t, PayloadStat{Sent: 200, Received: 10, WireSent: 100, WireReceived: 10,
+			SentSizes:     SizeStat{Count: 2, Min: 100, Mean: 100, P50: 100, P90: 100, P99: 100, Max: 100},
+			ReceivedSizes: SizeStat{Count: 1, Min: 10, Mean: 10, P50: 10, P90: 10, P99: 10, Max: 10}}, m.Payload.Snapshot())
+		assert.Equal(t, int64(4), m.Latency.Snapshot().Count)
+	})
+
+	t.Run("Test points", func(t *testing.T) {
+		points := m.Series.Points()
+		require.Len(t, points, 2)
+		last, ok := m.Series.Last()
+		assert.True(t, ok)
+		assert.Equal(t, second, last)
+
+		m.Reset()
+		_, ok = m.Series.Last()
+		assert.False(t, ok)
+	})
+}
@@ -0,0 +1,174 @@
+package otel
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/AndreyNiki/grpc-highloader/internal/entity"
+)
+
+const (
+	metricsServiceMethod = "/opentelemetry.proto.collector.metrics.v1.MetricsService/Export"
+	traceServiceMethod   = "/opentelemetry.proto.collector.trace.v1.TraceService/Export"
+	metricsHTTPPath      = "/v1/metrics"
+	tracesHTTPPath       = "/v1/traces"
+	protobufContentType  = "application/x-protobuf"
+	// exportTimeout max time of one export request.
+	exportTimeout = 10 * time.Second
+	// maxErrorBodyLen max length of response body in error.
+	maxErrorBodyLen = 1 << 10
+)
+
+// signal kind of exported telemetry.
+type signal int
+
+// Available values for signal.
+const (
+	signalMetrics signal = iota
+	signalTraces
+)
+
+// client sends encoded export requests to collector.
+type client interface {
+	export(ctx context.Context, s signal, body []byte) error
+	close()
+}
+
+// newClient create client of protocol, TLS is used if endpoint has "https" scheme.
+func newClient(params entity.TelemetryParams) (client, error) {
+	endpoint := params.Endpoint
+	secure := false
+	if strings.Contains(endpoint, "://") {
+		u, err := url.Parse(endpoint)
+		if err != nil {
+			return nil, fmt.Errorf("invalid endpoint %q: %w", endpoint, err)
+		}
+		if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+			return nil, fmt.Errorf("invalid endpoint %q: only http:// and https:// schemes are supported", endpoint)
+		}
+		secure = u.Scheme == "https"
+		if params.Protocol == entity.TelemetryProtocolGRPC {
+			endpoint = u.Host
+		}
+	} else if params.Protocol == entity.TelemetryProtocolHTTP {
+		endpoint = "http://" + endpoint
+	}
+	if endpoint == "" {
+		return nil, fmt.Errorf("endpoint is not set")
+	}
+
+	switch params.Protocol {
+	case entity.TelemetryProtocolGRPC:
+		creds := insecure.NewCredentials()
+		if secure {
+			creds = credentials.NewTLS(&tls.Config{MinVersion: tls.VersionTLS12})
+		}
+		conn, err := grpc.Dial(endpoint, grpc.WithTransportCredentials(creds))
+		if err != nil {
+			return nil, err
+		}
+		return &grpcClient{conn: conn}, nil
+	case entity.TelemetryProtocolHTTP:
+		return &httpClient{client: &http.Client{Timeout: exportTimeout}, url: strings.TrimSuffix(endpoint, "/")}, nil
+	default:
+		return nil, fmt.Errorf("protocol %d is not supported", params.Protocol)
+	}
+}
+
+// grpcClient exports by OTLP/gRPC.
+type grpcClient struct {
+	conn *grpc.ClientConn
+}
+
+// export implements client.
+func (c *grpcClient) export(ctx context.Context, s signal, body []byte) error {
+	method := metricsServiceMethod
+	if s == signalTraces {
+		method = traceServiceMethod
+	}
+	var resp []byte
+
+	return c.conn.Invoke(ctx, method, &body, &resp, grpc.ForceCodec(rawCodec{}))
+}
+
+// close implements client.
+func (c *grpcClient) close() {
+	c.conn.Close()
+}
+
+// httpClient exports by OTLP/HTTP in binary protobuf encoding.
+type httpClient struct {
+	client *http.Client
+	url    string
+}
+
+// export implements client.
+func (c *httpClient) export(ctx context.Context, s signal, body []byte) error {
+	path := metricsHTTPPath
+	if s == signalTraces {
+		path = tracesHTTPPath
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", protobufContentType)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodyLen))
+		return fmt.Errorf("collector responded %s: %s", resp.Status, msg)
+	}
+	io.Copy(io.Discard, resp.Body)
+
+	return nil
+}
+
+// close implements client.
+func (c *httpClient) close() {
+	c.client.CloseIdleConnections()
+}
+
+// rawCodec codec of already encoded messages.
+type rawCodec struct{}
+
+// Marshal implements encoding.Codec.
+func (rawCodec) Marshal(v any) ([]byte, error) {
+	b, ok := v.(*[]byte)
+	if !ok {
+		return nil, fmt.Errorf("unexpected message %T", v)
+	}
+
+	return *b, nil
+}
+
+// Unmarshal implements encoding.Codec.
+func (rawCodec) Unmarshal(data []byte, v any) error {
+	b, ok := v.(*[]byte)
+	if !ok {
+		return fmt.Errorf("unexpected message %T", v)
+	}
+	*b = append((*b)[:0], data...)
+
+	return nil
+}
+
+// Name implements encoding.Codec.
+func (rawCodec) Name() string {
+	return "proto"
+}
@@ -0,0 +1,39 @@
+package otel
+
+import (
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+)
+
+// attribute key and value of resource, data point or span. Value is string or int64.
+type attribute struct {
+	key   string
+	value any
+}
+
+// keyValues convert attributes to OTLP attributes.
+func keyValues(attrs []attribute) []*commonpb.KeyValue {
+	kvs := make([]*commonpb.KeyValue, 0, len(attrs))
+	for _, a := range attrs {
+		value := &commonpb.AnyValue{}
+		switch v := a.value.(type) {
+		case string:
+			value.Value = &commonpb.AnyValue_StringValue{StringValue: v}
+		case int64:
+			value.Value = &commonpb.AnyValue_IntValue{IntValue: v}
+		}
+		kvs = append(kvs, &commonpb.KeyValue{Key: a.key, Value: value})
+	}
+
+	return kvs
+}
+
+// newResource make resource of exported telemetry.
+func newResource(attrs []attribute) *resourcepb.Resource {
+	return &resourcepb.Resource{Attributes: keyValues(attrs)}
+}
+
+// newScope make instrumentation scope of exported telemetry.
+func newScope() *commonpb.InstrumentationScope {
+	return &commonpb.InstrumentationScope{Name: scopeName, Version: scopeVersion}
+}
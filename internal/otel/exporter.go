@@ -0,0 +1,210 @@
+package otel
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/AndreyNiki/grpc-highloader/internal/entity"
+	"github.com/AndreyNiki/grpc-highloader/internal/logger"
+	"github.com/AndreyNiki/grpc-highloader/internal/metrics"
+)
+
+const (
+	scopeName    = "github.com/AndreyNiki/grpc-highloader"
+	scopeVersion = "1.0"
+	serviceName  = "grpc-highloader"
+	// defaultInterval interval of export of metrics if it is not set.
+	defaultInterval = 10 * time.Second
+	// spansInterval interval of export of queued spans.
+	spansInterval = time.Second
+	// maxQueuedSpans max spans waiting for export, new spans are dropped if queue is full.
+	maxQueuedSpans = 4096
+)
+
+// errAlreadyStarted error of starting of running exporter.
+var errAlreadyStarted = errors.New("OpenTelemetry export is already started")
+
+// resource attributes of exported telemetry.
+var resource = []attribute{{key: "service.name", value: serviceName}}
+
+// Exporter exports metrics of registered request cards and sampled client spans to collector by OTLP.
+type Exporter struct {
+	mu      sync.Mutex
+	nextID  int
+	targets map[int]*target
+	running atomic.Pointer[run]
+}
+
+// run state of started export.
+type run struct {
+	params entity.TelemetryParams
+	client client
+	cancel context.CancelFunc
+	done   chan struct{}
+	// mu guards spans queued for export.
+	mu    sync.Mutex
+	spans []span
+}
+
+// NewExporter create a new Exporter.
+func NewExporter() *Exporter {
+	return &Exporter{
+		targets: map[int]*target{},
+	}
+}
+
+// Register add metrics of request card, they are exported until unregister is called.
+func (e *Exporter) Register(labels entity.MetricsLabels, m *metrics.Metrics) (unregister func()) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	id := e.nextID
+	e.nextID++
+	t := &target{labels: labels, metrics: m, started: time.Now()}
+	e.targets[id] = t
+	return func() {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		if e.running.Load() == nil {
+			delete(e.targets, id)
+			return
+		}
+		t.done = true
+	}
+}
+
+// Start export to collector, stop exports the remaining telemetry and waits for it.
+func (e *Exporter) Start(params entity.TelemetryParams) (stop func(), err error) {
+	c, err := newClient(params)
+	if err != nil {
+		return nil, err
+	}
+	if params.Interval <= 0 {
+		params.Interval = defaultInterval
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &run{params: params, client: c, cancel: cancel, done: make(chan struct{})}
+	if !e.running.CompareAndSwap(nil, r) {
+		cancel()
+		c.close()
+		return nil, errAlreadyStarted
+	}
+	go e.loop(ctx, r)
+
+	return func() {
+		r.cancel()
+		<-r.done
+	}, nil
+}
+
+// StartSpan start client span of request and return W3C traceparent of it. Only sampled spans are exported,
+// empty traceparent is returned if spans are disabled.
+func (e *Exporter) StartSpan(req *entity.RequestParams) (string, func(err error)) {
+	r := e.running.Load()
+	if r == nil || r.params.SampleRatio <= 0 {
+		return "", func(error) {}
+	}
+
+	traceID, spanID := newSpanIDs()
+	sampled := rand.Float64() < r.params.SampleRatio
+	tp := traceparent(traceID, spanID, sampled)
+	if !sampled {
+		return tp, func(error) {}
+	}
+
+	start := time.Now()
+	return tp, func(err error) {
+		s := newSpan(req, traceID, spanID, start, time.Now(), err)
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		if len(r.spans) < maxQueuedSpans {
+			r.spans = append(r.spans, s)
+		}
+	}
+}
+
+// loop export metrics and spans periodically until context is done, then export them for the last time.
+func (e *Exporter) loop(ctx context.Context, r *run) {
+	defer close(r.done)
+	metricsTicker := time.NewTicker(r.params.Interval)
+	defer metricsTicker.Stop()
+	spansTicker := time.NewTicker(spansInterval)
+	defer spansTicker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			e.exportSpans(r)
+			e.exportMetrics(r)
+			e.running.Store(nil)
+			e.removeDone()
+			r.client.close()
+			return
+		case <-spansTicker.C:
+			e.exportSpans(r)
+		case <-metricsTicker.C:
+			e.exportMetrics(r)
+		}
+	}
+}
+
+// exportMetrics export metrics of targets, unregistered targets are removed after export.
+func (e *Exporter) exportMetrics(r *run) {
+	e.mu.Lock()
+	targets := make([]*target, 0, len(e.targets))
+	for _, t := range e.targets {
+		targets = append(targets, t)
+	}
+	e.mu.Unlock()
+	if len(targets) == 0 {
+		return
+	}
+
+	e.send(r, signalMetrics, newMetricsData(resource, targets, time.Now()))
+	e.removeDone()
+}
+
+// exportSpans export queued spans.
+func (e *Exporter) exportSpans(r *run) {
+	r.mu.Lock()
+	spans := r.spans
+	r.spans = nil
+	r.mu.Unlock()
+	if len(spans) == 0 {
+		return
+	}
+
+	e.send(r, signalTraces, newTracesData(resource, spans))
+}
+
+// send encode and export request, errors are logged.
+func (e *Exporter) send(r *run, s signal, req proto.Message) {
+	ctx, cancel := context.WithTimeout(context.Background(), exportTimeout)
+	defer cancel()
+	body, err := proto.Marshal(req)
+	if err != nil {
+		logger.LoggerFromContext(ctx).Error("Error encode telemetry", "Error", err)
+		return
+	}
+	if err := r.client.export(ctx, s, body); err != nil {
+		logger.LoggerFromContext(ctx).Error("Error export telemetry", "Error", err)
+	}
+}
+
+// removeDone remove unregistered targets.
+func (e *Exporter) removeDone() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for id, t := range e.targets {
+		if t.done {
+			delete(e.targets, id)
+		}
+	}
+}
@@ -0,0 +1,250 @@
+package otel
+
+import (
+	"encoding/hex"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/AndreyNiki/grpc-highloader/internal/entity"
+	"github.com/AndreyNiki/grpc-highloader/internal/metrics"
+)
+
+// receiver in-process collector which stores received export requests by path or method.
+type receiver struct {
+	mu       sync.Mutex
+	requests map[string][][]byte
+}
+
+func (r *receiver) add(path string, body []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests[path] = append(r.requests[path], body)
+}
+
+func (r *receiver) get(path string) [][]byte {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.requests[path]
+}
+
+// newHTTPReceiver start OTLP/HTTP receiver and return its endpoint.
+func newHTTPReceiver(t *testing.T) (string, *receiver) {
+	t.Helper()
+	r := &receiver{requests: map[string][][]byte{}}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, protobufContentType, req.Header.Get("Content-Type"))
+		body, err := io.ReadAll(req.Body)
+		assert.NoError(t, err)
+		r.add(req.URL.Path, body)
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL, r
+}
+
+// newGRPCReceiver start OTLP/gRPC receiver and return its endpoint.
+func newGRPCReceiver(t *testing.T) (string, *receiver) {
+	t.Helper()
+	r := &receiver{requests: map[string][][]byte{}}
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	srv := grpc.NewServer(grpc.ForceServerCodec(rawCodec{}),
+		grpc.UnknownServiceHandler(func(_ any, stream grpc.ServerStream) error {
+			method, _ := grpc.MethodFromServerStream(stream)
+			var body []byte
+			if err := stream.RecvMsg(&body); err != nil {
+				return err
+			}
+			r.add(method, body)
+			resp := []byte{}
+			return stream.SendMsg(&resp)
+		}))
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+	return lis.Addr().String(), r
+}
+
+// decodeMetrics decode export request of metrics to metrics by names.
+func decodeMetrics(t *testing.T, body []byte) map[string]*metricspb.Metric {
+	t.Helper()
+	// MetricsData has the same encoding as ExportMetricsServiceRequest of collector.
+	req := &metricspb.MetricsData{}
+	require.NoError(t, proto.Unmarshal(body, req))
+	require.Len(t, req.GetResourceMetrics(), 1)
+	resourceMetrics := req.GetResourceMetrics()[0]
+	assert.Equal(t, serviceName, attributes(resourceMetrics.GetResource().GetAttributes())["service.name"])
+	require.Len(t, resourceMetrics.GetScopeMetrics(), 1)
+	assert.Equal(t, scopeName, resourceMetrics.GetScopeMetrics()[0].GetScope().GetName())
+
+	items := map[string]*metricspb.Metric{}
+	for _, m := range resourceMetrics.GetScopeMetrics()[0].GetMetrics() {
+		items[m.GetName()] = m
+	}
+	return items
+}
+
+// decodeSpans decode export request of spans.
+func decodeSpans(t *testing.T, body []byte) []*tracepb.Span {
+	t.Helper()
+	// TracesData has the same encoding as ExportTraceServiceRequest of collector.
+	req := &tracepb.TracesData{}
+	require.NoError(t, proto.Unmarshal(body, req))
+	require.Len(t, req.GetResourceSpans(), 1)
+	resourceSpans := req.GetResourceSpans()[0]
+	assert.Equal(t, serviceName, attributes(resourceSpans.GetResource().GetAttributes())["service.name"])
+	require.Len(t, resourceSpans.GetScopeSpans(), 1)
+
+	return resourceSpans.GetScopeSpans()[0].GetSpans()
+}
+
+// attributes return attributes with string values.
+func attributes(kvs []*commonpb.KeyValue) map[string]string {
+	attrs := map[string]string{}
+	for _, kv := range kvs {
+		if v, ok := kv.GetValue().GetValue().(*commonpb.AnyValue_StringValue); ok {
+			attrs[kv.GetKey()] = v.StringValue
+		}
+	}
+
+	return attrs
+}
+
+func TestExporter_HTTP(t *testing.T) {
+	endpoint, r := newHTTPReceiver(t)
+	e := NewExporter()
+	m := metrics.InitMetrics()
+	m.IncrementRequestCount()
+	m.ObserveOutcome(metrics.OutcomeStatus, status.New(codes.OK, ""))
+	m.ObserveLatency(codes.OK, 3*time.Millisecond)
+	m.ObserveSent(10, 15)
+	unregister := e.Register(entity.MetricsLabels{Proto: "example.proto", Service: "example.v1.UserService",
+		Method: "Get", Host: "localhost:50051", Card: "0"}, m)
+
+	stop, err := e.Start(entity.TelemetryParams{Endpoint: endpoint, Protocol: entity.TelemetryProtocolHTTP,
+		Interval: time.Hour, SampleRatio: 1})
+	require.NoError(t, err)
+	_, err = e.Start(entity.TelemetryParams{Endpoint: endpoint})
+	assert.ErrorIs(t, err, errAlreadyStarted)
+
+	req := &entity.RequestParams{Service: "example.v1.UserService", Method: "Get", Host: "localhost:50051"}
+	tp, end := e.StartSpan(req)
+	require.Regexp(t, regexp.MustCompile(`^00-[0-9a-f]{32}-[0-9a-f]{16}-01$`), tp)
+	end(status.Error(codes.NotFound, "user not found"))
+	unregister()
+	stop()
+
+	tpTraceID, tpSpanID := tp[3:35], tp[36:52]
+	tp, _ = e.StartSpan(req)
+	assert.Empty(t, tp, "spans are disabled after stop")
+
+	traces := r.get(tracesHTTPPath)
+	require.Len(t, traces, 1)
+	spans := decodeSpans(t, traces[0])
+	require.Len(t, spans, 1)
+	span := spans[0]
+	assert.Equal(t, tpTraceID, hex.EncodeToString(span.GetTraceId()))
+	assert.Equal(t, tpSpanID, hex.EncodeToString(span.GetSpanId()))
+	assert.Equal(t, "example.v1.UserService/Get", span.GetName())
+	assert.Equal(t, tracepb.Span_SPAN_KIND_CLIENT, span.GetKind())
+	assert.Equal(t, "grpc", attributes(span.GetAttributes())["rpc.system"])
+	assert.Equal(t, tracepb.Status_STATUS_CODE_ERROR, span.GetStatus().GetCode())
+	assert.Equal(t, "user not found", span.GetStatus().GetMessage())
+
+	exported := r.get(metricsHTTPPath)
+	require.Len(t, exported, 1)
+	items := decodeMetrics(t, exported[0])
+	require.Len(t, items, 7)
+
+	requests := items[metricRequests].GetSum()
+	assert.True(t, requests.GetIsMonotonic())
+	assert.Equal(t, metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE,
+		requests.GetAggregationTemporality())
+	point := requests.GetDataPoints()[0]
+	assert.Equal(t, int64(1), point.GetAsInt())
+	assert.Equal(t, "localhost:50051", attributes(point.GetAttributes())["host"])
+
+	outcomes := items[metricOutcomes].GetSum().GetDataPoints()
+	require.Len(t, outcomes, len(metrics.InitMetrics().Outcomes.Snapshot()))
+	for _, p := range outcomes {
+		if attributes(p.GetAttributes())["outcome"] == metrics.OutcomeStatus.String() {
+			assert.Equal(t, int64(1), p.GetAsInt())
+		}
+	}
+
+	assert.Equal(t, int64(15), items[metricSent].GetSum().GetDataPoints()[0].GetAsInt())
+	assert.NotNil(t, items[metricInFlight].GetGauge())
+
+	latency := items[metricLatency].GetHistogram()
+	require.Len(t, latency.GetDataPoints(), 1)
+	histogram := latency.GetDataPoints()[0]
+	assert.Equal(t, "OK", attributes(histogram.GetAttributes())["code"])
+	assert.Equal(t, uint64(1), histogram.GetCount())
+	require.Len(t, histogram.GetBucketCounts(), len(metrics.LatencyBuckets)+1)
+	assert.Len(t, histogram.GetExplicitBounds(), len(metrics.LatencyBuckets))
+	// 3ms is in bucket (2.5ms, 5ms].
+	assert.Equal(t, uint64(1), histogram.GetBucketCounts()[2])
+	assert.InDelta(t, 0.003, histogram.GetSum(), 1e-9)
+	assert.InDelta(t, 0.003, histogram.GetMin(), 1e-9)
+
+	e.mu.Lock()
+	assert.Empty(t, e.targets)
+	e.mu.Unlock()
+}
+
+func TestExporter_GRPC(t *testing.T) {
+	endpoint, r := newGRPCReceiver(t)
+	e := NewExporter()
+	defer e.Register(entity.MetricsLabels{Card: "0"}, metrics.InitMetrics())()
+
+	stop, err := e.Start(entity.TelemetryParams{Endpoint: "http://" + endpoint, Interval: 20 * time.Millisecond})
+	require.NoError(t, err)
+	tp, _ := e.StartSpan(&entity.RequestParams{})
+	assert.Empty(t, tp, "spans are disabled without sample ratio")
+
+	assert.Eventually(t, func() bool { return len(r.get(metricsServiceMethod)) >= 2 }, 5*time.Second,
+		10*time.Millisecond)
+	stop()
+	assert.Empty(t, r.get(traceServiceMethod))
+	assert.Len(t, decodeMetrics(t, r.get(metricsServiceMethod)[0]), 7)
+}
+
+func TestNewClient(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  entity.TelemetryParams
+		wantErr bool
+	}{
+		{name: "grpc", params: entity.TelemetryParams{Endpoint: "localhost:4317"}},
+		{name: "grpc with tls", params: entity.TelemetryParams{Endpoint: "https://collector:4317"}},
+		{name: "http", params: entity.TelemetryParams{Endpoint: "localhost:4318",
+			Protocol: entity.TelemetryProtocolHTTP}},
+		{name: "empty endpoint", wantErr: true},
+		{name: "unsupported scheme", params: entity.TelemetryParams{Endpoint: "ftp://collector"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := newClient(tt.params)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			c.close()
+		})
+	}
+}
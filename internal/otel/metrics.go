@@ -0,0 +1,147 @@
+package otel
+
+import (
+	"maps"
+	"slices"
+	"time"
+
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/AndreyNiki/grpc-highloader/internal/entity"
+	"github.com/AndreyNiki/grpc-highloader/internal/metrics"
+)
+
+// Names of exported metrics.
+const (
+	metricRequests  = "grpc_highloader.requests"
+	metricResponses = "grpc_highloader.responses"
+	metricOutcomes  = "grpc_highloader.outcomes"
+	metricInFlight  = "grpc_highloader.in_flight"
+	metricLatency   = "grpc_highloader.latency"
+	metricSent      = "grpc_highloader.sent"
+	metricReceived  = "grpc_highloader.received"
+)
+
+// target metrics of one request card.
+type target struct {
+	labels  entity.MetricsLabels
+	metrics *metrics.Metrics
+	started time.Time
+	// done is set when card is unregistered, its metrics are exported for the last time.
+	done bool
+}
+
+// newMetricsData make export request of metrics of targets.
+// MetricsData has the same encoding as ExportMetricsServiceRequest of collector.
+func newMetricsData(resource []attribute, targets []*target, now time.Time) *metricspb.MetricsData {
+	var requests, responses, outcomes, inFlight, sent, received []*metricspb.NumberDataPoint
+	var latency []*metricspb.HistogramDataPoint
+	for _, t := range targets {
+		attrs := targetAttributes(t.labels)
+		requests = append(requests, numberPoint(attrs, t.started, now, t.metrics.RequestCounter.Value.Load()))
+		inFlight = append(inFlight, numberPoint(attrs, t.started, now, t.metrics.InFlightGauge.Value.Load()))
+		sent = append(sent, numberPoint(attrs, t.started, now, t.metrics.Payload.WireSent.Value.Load()))
+		received = append(received, numberPoint(attrs, t.started, now, t.metrics.Payload.WireReceived.Value.Load()))
+
+		counts := t.metrics.StatusCounts()
+		for _, code := range slices.Sorted(maps.Keys(counts)) {
+			codeAttrs := append(slices.Clip(attrs), attribute{key: "code", value: code.String()})
+			responses = append(responses, numberPoint(codeAttrs, t.started, now, counts[code]))
+		}
+		outcomeCounts := t.metrics.Outcomes.Snapshot()
+		for _, outcome := range slices.Sorted(maps.Keys(outcomeCounts)) {
+			outcomeAttrs := append(slices.Clip(attrs), attribute{key: "outcome", value: outcome.String()})
+			outcomes = append(outcomes, numberPoint(outcomeAttrs, t.started, now, outcomeCounts[outcome]))
+		}
+		histograms := t.metrics.Latency.Histograms()
+		for _, code := range slices.Sorted(maps.Keys(histograms)) {
+			codeAttrs := append(slices.Clip(attrs), attribute{key: "code", value: code.String()})
+			latency = append(latency, histogramPoint(codeAttrs, t.started, now, histograms[code]))
+		}
+	}
+
+	return &metricspb.MetricsData{ResourceMetrics: []*metricspb.ResourceMetrics{{
+		Resource: newResource(resource),
+		ScopeMetrics: []*metricspb.ScopeMetrics{{
+			Scope: newScope(),
+			Metrics: []*metricspb.Metric{
+				{Name: metricRequests, Description: "Requests sent.", Unit: "{request}", Data: cumulativeSum(requests)},
+				{Name: metricResponses, Description: "Attempts by status code, failed assertions are not counted.",
+					Unit: "{response}", Data: cumulativeSum(responses)},
+				{Name: metricOutcomes, Description: "Attempts by outcome, their sum is equal to requests.",
+					Unit: "{request}", Data: cumulativeSum(outcomes)},
+				{Name: metricInFlight, Description: "Requests waiting for response.", Unit: "{request}",
+					Data: &metricspb.Metric_Gauge{Gauge: &metricspb.Gauge{DataPoints: inFlight}}},
+				{Name: metricLatency, Description: "Latency of responses by status code.", Unit: "s",
+					Data: &metricspb.Metric_Histogram{Histogram: &metricspb.Histogram{
+						DataPoints:             latency,
+						AggregationTemporality: metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE,
+					}}},
+				{Name: metricSent, Description: "Size of sent messages on wire.", Unit: "By", Data: cumulativeSum(sent)},
+				{Name: metricReceived, Description: "Size of received messages on wire.", Unit: "By",
+					Data: cumulativeSum(received)},
+			},
+		}},
+	}}}
+}
+
+// targetAttributes return attributes of data points of target.
+func targetAttributes(l entity.MetricsLabels) []attribute {
+	return []attribute{
+		{key: "proto", value: l.Proto},
+		{key: "service", value: l.Service},
+		{key: "method", value: l.Method},
+		{key: "host", value: l.Host},
+		{key: "card", value: l.Card},
+	}
+}
+
+// cumulativeSum make data of monotonic cumulative Sum.
+func cumulativeSum(points []*metricspb.NumberDataPoint) *metricspb.Metric_Sum {
+	return &metricspb.Metric_Sum{Sum: &metricspb.Sum{
+		DataPoints:             points,
+		AggregationTemporality: metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE,
+		IsMonotonic:            true,
+	}}
+}
+
+// numberPoint make NumberDataPoint with int value.
+func numberPoint(attrs []attribute, start, now time.Time, value int64) *metricspb.NumberDataPoint {
+	return &metricspb.NumberDataPoint{
+		Attributes:        keyValues(attrs),
+		StartTimeUnixNano: uint64(start.UnixNano()),
+		TimeUnixNano:      uint64(now.UnixNano()),
+		Value:             &metricspb.NumberDataPoint_AsInt{AsInt: value},
+	}
+}
+
+// histogramPoint make HistogramDataPoint in seconds, counts of buckets are not cumulative in OTLP.
+func histogramPoint(attrs []attribute, start, now time.Time, h *metrics.Histogram) *metricspb.HistogramDataPoint {
+	cumulativeCounts, total, sum := h.Buckets(metrics.Nanoseconds(metrics.LatencyBuckets))
+	counts := make([]uint64, 0, len(metrics.LatencyBuckets)+1)
+	bounds := make([]float64, 0, len(metrics.LatencyBuckets))
+	var prev int64
+	for i, c := range cumulativeCounts {
+		counts = append(counts, uint64(c-prev))
+		bounds = append(bounds, metrics.LatencyBuckets[i].Seconds())
+		prev = c
+	}
+	counts = append(counts, uint64(total-prev))
+
+	p := &metricspb.HistogramDataPoint{
+		Attributes:        keyValues(attrs),
+		StartTimeUnixNano: uint64(start.UnixNano()),
+		TimeUnixNano:      uint64(now.UnixNano()),
+		Count:             uint64(total),
+		Sum:               proto.Float64(time.Duration(sum).Seconds()),
+		BucketCounts:      counts,
+		ExplicitBounds:    bounds,
+	}
+	if stat := h.Snapshot(); stat.Count > 0 {
+		p.Min = proto.Float64(time.Duration(stat.Min).Seconds())
+		p.Max = proto.Float64(time.Duration(stat.Max).Seconds())
+	}
+
+	return p
+}
@@ -0,0 +1,105 @@
+package otel
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"math/rand/v2"
+	"time"
+
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/AndreyNiki/grpc-highloader/internal/entity"
+)
+
+const (
+	// traceparentVersion version of W3C trace context.
+	traceparentVersion = "00"
+	flagSampled        = "01"
+	flagNotSampled     = "00"
+)
+
+// span finished client span of request.
+type span struct {
+	traceID [16]byte
+	spanID  [8]byte
+	name    string
+	start   time.Time
+	end     time.Time
+	attrs   []attribute
+	code    codes.Code
+	message string
+}
+
+// newSpanIDs return random non-zero ids of trace and span.
+func newSpanIDs() ([16]byte, [8]byte) {
+	var traceID [16]byte
+	var spanID [8]byte
+	binary.BigEndian.PutUint64(traceID[:8], rand.Uint64())
+	binary.BigEndian.PutUint64(traceID[8:], rand.Uint64()|1)
+	binary.BigEndian.PutUint64(spanID[:], rand.Uint64()|1)
+	return traceID, spanID
+}
+
+// traceparent format W3C traceparent header.
+func traceparent(traceID [16]byte, spanID [8]byte, sampled bool) string {
+	flags := flagNotSampled
+	if sampled {
+		flags = flagSampled
+	}
+
+	return traceparentVersion + "-" + hex.EncodeToString(traceID[:]) + "-" + hex.EncodeToString(spanID[:]) + "-" + flags
+}
+
+// newSpan make span of request with attributes of RPC semantic conventions.
+func newSpan(req *entity.RequestParams, traceID [16]byte, spanID [8]byte, start, end time.Time, err error) span {
+	code := status.Code(err)
+	s := span{
+		traceID: traceID,
+		spanID:  spanID,
+		name:    req.Service + "/" + req.Method,
+		start:   start,
+		end:     end,
+		attrs: []attribute{
+			{key: "rpc.system", value: "grpc"},
+			{key: "rpc.service", value: req.Service},
+			{key: "rpc.method", value: req.Method},
+			{key: "rpc.grpc.status_code", value: int64(code)},
+			{key: "server.address", value: req.Host},
+		},
+		code: code,
+	}
+	if err != nil {
+		s.message = status.Convert(err).Message()
+	}
+
+	return s
+}
+
+// newTracesData make export request of spans.
+// TracesData has the same encoding as ExportTraceServiceRequest of collector.
+func newTracesData(resource []attribute, spans []span) *tracepb.TracesData {
+	items := make([]*tracepb.Span, 0, len(spans))
+	for _, s := range spans {
+		st := &tracepb.Status{Code: tracepb.Status_STATUS_CODE_OK}
+		if s.code != codes.OK {
+			st = &tracepb.Status{Code: tracepb.Status_STATUS_CODE_ERROR, Message: s.message}
+		}
+		items = append(items, &tracepb.Span{
+			TraceId:           s.traceID[:],
+			SpanId:            s.spanID[:],
+			Name:              s.name,
+			Kind:              tracepb.Span_SPAN_KIND_CLIENT,
+			StartTimeUnixNano: uint64(s.start.UnixNano()),
+			EndTimeUnixNano:   uint64(s.end.UnixNano()),
+			Attributes:        keyValues(s.attrs),
+			Status:            st,
+		})
+	}
+
+	return &tracepb.TracesData{ResourceSpans: []*tracepb.ResourceSpans{{
+		Resource:   newResource(resource),
+		ScopeSpans: []*tracepb.ScopeSpans{{Scope: newScope(), Spans: items}},
+	}}}
+}
@@ -0,0 +1,187 @@
+package prometheus
+
+import (
+	"bufio"
+	"cmp"
+	"fmt"
+	"io"
+	"maps"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AndreyNiki/grpc-highloader/internal/entity"
+	"github.com/AndreyNiki/grpc-highloader/internal/metrics"
+)
+
+const (
+	contentType       = "text/plain; version=0.0.4; charset=utf-8"
+	metricsPath       = "/metrics"
+	readHeaderTimeout = 10 * time.Second
+	namePrefix        = "grpc_highloader_"
+)
+
+// labelValueReplacer escapes label values of text format.
+var labelValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// target metrics of one request card.
+type target struct {
+	labels  entity.MetricsLabels
+	metrics *metrics.Metrics
+}
+
+// Exporter serves metrics of registered request cards in Prometheus text format.
+type Exporter struct {
+	mu      sync.RWMutex
+	nextID  int
+	targets map[int]target
+}
+
+// NewExporter create a new Exporter.
+func NewExporter() *Exporter {
+	return &Exporter{
+		targets: map[int]target{},
+	}
+}
+
+// Register add metrics of request card, they are exported until unregister is called.
+func (e *Exporter) Register(labels entity.MetricsLabels, m *metrics.Metrics) (unregister func()) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	id := e.nextID
+	e.nextID++
+	e.targets[id] = target{labels: labels, metrics: m}
+	return func() {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		delete(e.targets, id)
+	}
+}
+
+// Listen start HTTP listener which serves metrics on /metrics path.
+func (e *Exporter) Listen(addr string) (stop func(), err error) {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("could not listen %q: %w", addr, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(metricsPath, e)
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: readHeaderTimeout}
+	go srv.Serve(lis)
+
+	return func() { srv.Close() }, nil
+}
+
+// ServeHTTP implements http.Handler.
+func (e *Exporter) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", contentType)
+	bw := bufio.NewWriter(w)
+	e.write(bw)
+	bw.Flush()
+}
+
+// write metrics of all targets grouped by metric families.
+func (e *Exporter) write(w io.Writer) {
+	e.mu.RLock()
+	targets := slices.Collect(maps.Values(e.targets))
+	e.mu.RUnlock()
+	slices.SortFunc(targets, func(a, b target) int {
+		return cmp.Or(strings.Compare(a.labels.Proto, b.labels.Proto), strings.Compare(a.labels.Card, b.labels.Card),
+			strings.Compare(a.labels.Host, b.labels.Host))
+	})
+
+	writeHeader(w, "requests_total", "counter", "Requests sent.")
+	for _, t := range targets {
+		writeSample(w, "requests_total", formatLabels(t.labels), t.metrics.RequestCounter.Value.Load())
+	}
+
+	writeHeader(w, "responses_total", "counter", "Attempts by status code, failed assertions are not counted.")
+	for _, t := range targets {
+		counts := t.metrics.StatusCounts()
+		for _, code := range slices.Sorted(maps.Keys(counts)) {
+			writeSample(w, "responses_total", formatLabels(t.labels, "code", code.String()), counts[code])
+		}
+	}
+
+	writeHeader(w, "outcomes_total", "counter", "Attempts by outcome, their sum is equal to requests.")
+	for _, t := range targets {
+		counts := t.metrics.Outcomes.Snapshot()
+		for _, outcome := range slices.Sorted(maps.Keys(counts)) {
+			writeSample(w, "outcomes_total", formatLabels(t.labels, "outcome", outcome.String()), counts[outcome])
+		}
+	}
+
+	writeHeader(w, "sent_bytes_total", "counter", "Size of sent messages on wire.")
+	for _, t := range targets {
+		writeSample(w, "sent_bytes_total", formatLabels(t.labels), t.metrics.Payload.WireSent.Value.Load())
+	}
+
+	writeHeader(w, "received_bytes_total", "counter", "Size of received messages on wire.")
+	for _, t := range targets {
+		writeSample(w, "received_bytes_total", formatLabels(t.labels), t.metrics.Payload.WireReceived.Value.Load())
+	}
+
+	writeHeader(w, "in_flight", "gauge", "Requests waiting for response.")
+	for _, t := range targets {
+		writeSample(w, "in_flight", formatLabels(t.labels), t.metrics.InFlightGauge.Value.Load())
+	}
+
+	writeHeader(w, "target_rps", "gauge", "Requests per second set for load.")
+	for _, t := range targets {
+		writeSample(w, "target_rps", formatLabels(t.labels), t.metrics.RequestPerSecondGauge.Value.Load())
+	}
+
+	writeHeader(w, "latency_seconds", "histogram", "Latency of responses by status code.")
+	for _, t := range targets {
+		histograms := t.metrics.Latency.Histograms()
+		for _, code := range slices.Sorted(maps.Keys(histograms)) {
+			writeHistogram(w, "latency_seconds", formatLabels(t.labels, "code", code.String()), histograms[code])
+		}
+	}
+}
+
+// writeHeader write help and type of metric family.
+func writeHeader(w io.Writer, name, kind, help string) {
+	fmt.Fprintf(w, "# HELP %s%s %s\n# TYPE %s%s %s\n", namePrefix, name, help, namePrefix, name, kind)
+}
+
+// writeSample write one sample of metric.
+func writeSample(w io.Writer, name, labels string, value int64) {
+	fmt.Fprintf(w, "%s%s{%s} %d\n", namePrefix, name, labels, value)
+}
+
+// writeHistogram write cumulative buckets, sum and count of histogram.
+func writeHistogram(w io.Writer, name, labels string, h *metrics.Histogram) {
+	counts, total, sum := h.Buckets(metrics.Nanoseconds(metrics.LatencyBuckets))
+	for i, bound := range metrics.LatencyBuckets {
+		le := strconv.FormatFloat(bound.Seconds(), 'f', -1, 64)
+		fmt.Fprintf(w, "%s%s_bucket{%s,le=%q} %d\n", namePrefix, name, labels, le, counts[i])
+	}
+	fmt.Fprintf(w, "%s%s_bucket{%s,le=\"+Inf\"} %d\n", namePrefix, name, labels, total)
+	fmt.Fprintf(w, "%s%s_sum{%s} %s\n", namePrefix, name, labels, strconv.FormatFloat(time.Duration(sum).Seconds(), 'f', -1, 64))
+	fmt.Fprintf(w, "%s%s_count{%s} %d\n", namePrefix, name, labels, total)
+}
+
+// formatLabels format labels of request card and extra pairs of names and values.
+func formatLabels(l entity.MetricsLabels, extra ...string) string {
+	pairs := append([]string{"proto", l.Proto, "service", l.Service, "method", l.Method, "host", l.Host,
+		"card", l.Card}, extra...)
+	var b strings.Builder
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(pairs[i])
+		b.WriteString(`="`)
+		b.WriteString(labelValueReplacer.Replace(pairs[i+1]))
+		b.WriteByte('"')
+	}
+
+	return b.String()
+}
@@ -0,0 +1,111 @@
+package prometheus
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/AndreyNiki/grpc-highloader/internal/entity"
+	"github.com/AndreyNiki/grpc-highloader/internal/metrics"
+)
+
+func TestExporter_ServeHTTP(t *testing.T) {
+	e := NewExporter()
+
+	m := metrics.InitMetrics()
+	m.SetRequestPerSecond(50)
+	m.IncrementRequestCount()
+	m.IncrementRequestCount()
+	m.ObserveOutcome(metrics.OutcomeStatus, status.New(codes.OK, ""))
+	m.ObserveLatency(codes.OK, 3*time.Millisecond)
+	m.ObserveOutcome(metrics.OutcomeTransport, status.New(codes.Unavailable, "connection refused"))
+	m.ObserveLatency(codes.Unavailable, 2*time.Second)
+	m.ObserveSent(10, 15)
+	labels := entity.MetricsLabels{
+		Proto:   "/protos/example.proto",
+		Service: "example.v1.UserService",
+		Method:  "Get",
+		Host:    "localhost:50051",
+		Card:    "0",
+	}
+	unregister := e.Register(labels, m)
+	e.Register(entity.MetricsLabels{Proto: `C:\protos\"other".proto`, Card: "1"}, metrics.InitMetrics())
+	const base = `proto="/protos/example.proto",service="example.v1.UserService",method="Get",host="localhost:50051",card="0"`
+
+	t.Run("Test families with labels", func(t *testing.T) {
+		body := scrape(t, e)
+		for _, line := range []string{
+			"# TYPE grpc_highloader_requests_total counter",
+			"grpc_highloader_requests_total{" + base + "} 2",
+			"grpc_highloader_responses_total{" + base + `,code="OK"} 1`,
+			"grpc_highloader_responses_total{" + base + `,code="Unavailable"} 1`,
+			"grpc_highloader_outcomes_total{" + base + `,outcome="status"} 1`,
+			"grpc_highloader_outcomes_total{" + base + `,outcome="transport_error"} 1`,
+			"grpc_highloader_target_rps{" + base + "} 50",
+			"grpc_highloader_sent_bytes_total{" + base + "} 15",
+			"grpc_highloader_received_bytes_total{" + base + "} 0",
+		} {
+			assert.Contains(t, body, line+"\n")
+		}
+	})
+
+	t.Run("Test latency histograms", func(t *testing.T) {
+		body := scrape(t, e)
+		for _, line := range []string{
+			"# TYPE grpc_highloader_latency_seconds histogram",
+			"grpc_highloader_latency_seconds_bucket{" + base + `,code="OK",le="0.0025"} 0`,
+			"grpc_highloader_latency_seconds_bucket{" + base + `,code="OK",le="0.005"} 1`,
+			"grpc_highloader_latency_seconds_bucket{" + base + `,code="Unavailable",le="1"} 0`,
+			"grpc_highloader_latency_seconds_bucket{" + base + `,code="Unavailable",le="+Inf"} 1`,
+			"grpc_highloader_latency_seconds_sum{" + base + `,code="Unavailable"} 2`,
+			"grpc_highloader_latency_seconds_count{" + base + `,code="OK"} 1`,
+		} {
+			assert.Contains(t, body, line+"\n")
+		}
+	})
+
+	t.Run("Test escaped label values", func(t *testing.T) {
+		assert.Contains(t, scrape(t, e),
+			`grpc_highloader_requests_total{proto="C:\\protos\\\"other\".proto",service="",method="",host="",card="1"} 0`+"\n")
+	})
+
+	t.Run("Test unregister", func(t *testing.T) {
+		unregister()
+		assert.NotContains(t, scrape(t, e), base)
+	})
+}
+
+func TestExporter_Listen(t *testing.T) {
+	t.Run("Test listen and stop", func(t *testing.T) {
+		stop, err := NewExporter().Listen("127.0.0.1:0")
+		require.NoError(t, err)
+		stop()
+	})
+
+	t.Run("Test invalid address", func(t *testing.T) {
+		_, err := NewExporter().Listen("invalid address")
+		assert.Error(t, err)
+	})
+}
+
+// scrape get metrics from exporter.
+func scrape(t *testing.T, e *Exporter) string {
+	t.Helper()
+	srv := httptest.NewServer(e)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + metricsPath)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, contentType, resp.Header.Get("Content-Type"))
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return string(body)
+}
@@ -0,0 +1,389 @@
+package proto
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	protov1 "github.com/golang/protobuf/proto"
+	"github.com/jhump/protoreflect/desc"
+	"github.com/jhump/protoreflect/dynamic"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	"github.com/AndreyNiki/grpc-highloader/internal/entity"
+	"github.com/AndreyNiki/grpc-highloader/internal/metrics"
+)
+
+// response result of call which is checked by assertions, message is nil for responses with error status.
+type response struct {
+	message *dynamic.Message
+	header  metadata.MD
+	trailer metadata.MD
+	latency time.Duration
+}
+
+// assertionError failed assertion of response. It has status of response, so failed responses with OK status
+// are not counted as errors.
+type assertionError struct {
+	assertion string
+	message   string
+	st        *status.Status
+}
+
+// Error implements error.
+func (e *assertionError) Error() string {
+	return fmt.Sprintf("assertion %s failed: %s", e.assertion, e.message)
+}
+
+// GRPCStatus return status of response.
+func (e *assertionError) GRPCStatus() *status.Status {
+	return e.st
+}
+
+// assertions compiled assertions of request.
+type assertions struct {
+	code       *codes.Code
+	fields     []*fieldAssertion
+	headers    []string
+	trailers   []string
+	maxLatency time.Duration
+}
+
+// fieldAssertion compiled assertion of response field.
+type fieldAssertion struct {
+	entity.FieldAssertion
+	steps []pathStep
+	// leaf descriptor of checked value, for items of maps it is descriptor of map value.
+	leaf *desc.FieldDescriptor
+	// collection is true if checked value is the whole repeated or map field.
+	collection bool
+	re         *regexp.Regexp
+	name       string
+}
+
+// pathStep step of path to response field, item of repeated field is selected by index and value of map by key.
+type pathStep struct {
+	field *desc.FieldDescriptor
+	index int
+	key   any
+}
+
+// newAssertions compile assertions for responses of message type, nil is returned for nil assertions.
+func newAssertions(a *entity.Assertions, md *desc.MessageDescriptor) (*assertions, error) {
+	if a == nil {
+		return nil, nil
+	}
+
+	compiled := &assertions{code: a.Code, maxLatency: a.MaxLatency}
+	for _, h := range a.Headers {
+		compiled.headers = append(compiled.headers, strings.ToLower(h))
+	}
+	for _, t := range a.Trailers {
+		compiled.trailers = append(compiled.trailers, strings.ToLower(t))
+	}
+	for _, f := range a.Fields {
+		fa, err := newFieldAssertion(f, md)
+		if err != nil {
+			return nil, fmt.Errorf("invalid assertion of field %q: %w", f.Path, err)
+		}
+		compiled.fields = append(compiled.fields, fa)
+	}
+
+	return compiled, nil
+}
+
+// verify check response of attempt by assertions, failed assertion is returned as error of attempt with
+// OutcomeAssertion. Responses with error status are checked only if code is expected, attempts with other outcomes
+// are not checked.
+func (a *assertions) verify(ctx context.Context, m *metrics.Metrics, resp *response, err error) error {
+	if a == nil {
+		return err
+	}
+	outcome, st := outcomeOf(ctx, err)
+	if outcome != metrics.OutcomeStatus || (err != nil && a.code == nil) {
+		return err
+	}
+
+	if st == nil {
+		st = status.New(codes.OK, "")
+	}
+	failure := a.check(resp, st)
+	if failure == nil {
+		m.ObserveAssertionPass()
+		return err
+	}
+	m.ObserveAssertionFailure(failure.assertion, failure.message)
+	return newOutcomeError(metrics.OutcomeAssertion, failure)
+}
+
+// check response with status, the first failed assertion is returned.
+func (a *assertions) check(resp *response, st *status.Status) *assertionError {
+	fail := func(assertion, format string, args ...any) *assertionError {
+		return &assertionError{assertion: assertion, message: fmt.Sprintf(format, args...), st: st}
+	}
+
+	if a.code != nil && st.Code() != *a.code {
+		return fail("code = "+a.code.String(), "got %s", st.Code())
+	}
+	if a.maxLatency > 0 && resp.latency > a.maxLatency {
+		return fail("latency <= "+a.maxLatency.String(), "got %s", resp.latency.Round(time.Microsecond))
+	}
+	for _, h := range a.headers {
+		if len(resp.header.Get(h)) == 0 {
+			return fail("header "+h, "missing")
+		}
+	}
+	for _, t := range a.trailers {
+		if len(resp.trailer.Get(t)) == 0 {
+			return fail("trailer "+t, "missing")
+		}
+	}
+	if resp.message == nil {
+		return nil
+	}
+	for _, f := range a.fields {
+		if msg, ok := f.check(resp.message); !ok {
+			return fail(f.name, "%s", msg)
+		}
+	}
+
+	return nil
+}
+
+// newFieldAssertion compile assertion of field of message type.
+func newFieldAssertion(f entity.FieldAssertion, md *desc.MessageDescriptor) (*fieldAssertion, error) {
+	fa := &fieldAssertion{FieldAssertion: f}
+	segments := strings.Split(f.Path, ".")
+	for i := 0; i < len(segments); i++ {
+		if md == nil {
+			return nil, fmt.Errorf("%q is not a message", strings.Join(segments[:i], "."))
+		}
+		fd := md.FindFieldByJSONName(segments[i])
+		if fd == nil {
+			fd = md.FindFieldByName(segments[i])
+		}
+		if fd == nil {
+			return nil, fmt.Errorf("field %q not found in %s", segments[i], md.GetFullyQualifiedName())
+		}
+
+		step := pathStep{field: fd, index: -1}
+		fa.leaf, fa.collection = fd, fd.IsRepeated()
+		md = fd.GetMessageType()
+		switch {
+		case fd.IsMap() && i+1 < len(segments):
+			i++
+			key, err := parseMapKey(fd.GetMapKeyType(), segments[i])
+			if err != nil {
+				return nil, err
+			}
+			step.key = key
+			fa.leaf, fa.collection = fd.GetMapValueType(), false
+			md = fa.leaf.GetMessageType()
+		case fd.IsRepeated() && i+1 < len(segments):
+			i++
+			index, err := strconv.Atoi(segments[i])
+			if err != nil || index < 0 {
+				return nil, fmt.Errorf("invalid index %q of repeated field %q", segments[i], fd.GetName())
+			}
+			step.index = index
+			fa.collection = false
+		case fd.IsRepeated():
+			md = nil
+		}
+		fa.steps = append(fa.steps, step)
+	}
+
+	switch f.Op {
+	case entity.AssertionOpEquals:
+		fa.name = fmt.Sprintf("%s %s %q", f.Path, f.Op, f.Value)
+	case entity.AssertionOpContains:
+		if fa.leaf.IsMap() && fa.collection {
+			return nil, errors.New("contains could not be checked for map")
+		}
+		fa.name = fmt.Sprintf("%s %s %q", f.Path, f.Op, f.Value)
+	case entity.AssertionOpRegex:
+		re, err := regexp.Compile(f.Value)
+		if err != nil {
+			return nil, err
+		}
+		fa.re = re
+		fa.name = fmt.Sprintf("%s %s %q", f.Path, f.Op, f.Value)
+	case entity.AssertionOpRange:
+		if !fa.collection && (fa.leaf.GetMessageType() != nil ||
+			fa.leaf.GetType() == descriptorpb.FieldDescriptorProto_TYPE_BOOL) {
+			return nil, errors.New("range could be checked for numbers, strings, bytes, repeated and map fields")
+		}
+		fa.name = fmt.Sprintf("%s %s [%s, %s]", f.Path, f.Op, formatBound(f.Min, "-inf"), formatBound(f.Max, "+inf"))
+	default:
+		return nil, fmt.Errorf("unknown operation %d", f.Op)
+	}
+	if fa.collection && f.Op != entity.AssertionOpContains && f.Op != entity.AssertionOpRange {
+		return nil, fmt.Errorf("%s could not be checked for repeated field, select item by index", f.Op)
+	}
+
+	return fa, nil
+}
+
+// check value of field in message, message about actual value is returned if check failed.
+func (f *fieldAssertion) check(msg *dynamic.Message) (string, bool) {
+	v, ok := f.value(msg)
+	if !ok {
+		return "field is not set", false
+	}
+
+	switch f.Op {
+	case entity.AssertionOpContains:
+		if items, ok := v.([]any); ok {
+			if slices.ContainsFunc(items, func(item any) bool { return f.text(item) == f.Value }) {
+				return "", true
+			}
+			return fmt.Sprintf("got %d items", len(items)), false
+		}
+		text := f.text(v)
+		return fmt.Sprintf("got %q", text), strings.Contains(text, f.Value)
+	case entity.AssertionOpRegex:
+		text := f.text(v)
+		return fmt.Sprintf("got %q", text), f.re.MatchString(text)
+	case entity.AssertionOpRange:
+		n, kind := number(v)
+		ok := (f.Min == nil || n >= *f.Min) && (f.Max == nil || n <= *f.Max)
+		return fmt.Sprintf("got %s %s", kind, strconv.FormatFloat(n, 'g', -1, 64)), ok
+	default:
+		text := f.text(v)
+		return fmt.Sprintf("got %q", text), text == f.Value
+	}
+}
+
+// value return value of field by path, ok is false if message on path is not set or item is not found.
+func (f *fieldAssertion) value(msg *dynamic.Message) (any, bool) {
+	var v any = msg
+	for _, step := range f.steps {
+		m, ok := asDynamicMessage(v)
+		if !ok {
+			return nil, false
+		}
+		fd := step.field
+		if fd.GetMessageType() != nil && !fd.IsRepeated() && !m.HasField(fd) {
+			return nil, false
+		}
+		v = m.GetField(fd)
+
+		switch {
+		case step.key != nil:
+			items, _ := v.(map[any]any)
+			if v, ok = items[step.key]; !ok {
+				return nil, false
+			}
+		case step.index >= 0:
+			items, _ := v.([]any)
+			if step.index >= len(items) {
+				return nil, false
+			}
+			v = items[step.index]
+		}
+	}
+
+	return v, true
+}
+
+// text format value like it is done in JSON, messages are formatted as JSON.
+func (f *fieldAssertion) text(v any) string {
+	if m, ok := asDynamicMessage(v); ok {
+		b, err := m.MarshalJSON()
+		if err != nil {
+			return err.Error()
+		}
+		return string(b)
+	}
+
+	return formatScalar(f.leaf, v)
+}
+
+// asDynamicMessage return value as dynamic message, ok is false for values of other types and nil messages.
+func asDynamicMessage(v any) (*dynamic.Message, bool) {
+	switch m := v.(type) {
+	case *dynamic.Message:
+		return m, m != nil
+	case protov1.Message:
+		dm, err := dynamic.AsDynamicMessage(m)
+		return dm, err == nil
+	default:
+		return nil, false
+	}
+}
+
+// number return number or length of value with its kind.
+func number(v any) (float64, string) {
+	switch v := v.(type) {
+	case []any:
+		return float64(len(v)), "count"
+	case map[any]any:
+		return float64(len(v)), "count"
+	case string:
+		return float64(utf8.RuneCountInString(v)), "length"
+	case []byte:
+		return float64(len(v)), "length"
+	case int32:
+		return float64(v), "value"
+	case int64:
+		return float64(v), "value"
+	case uint32:
+		return float64(v), "value"
+	case uint64:
+		return float64(v), "value"
+	case float32:
+		return float64(v), "value"
+	case float64:
+		return v, "value"
+	default:
+		return 0, "value"
+	}
+}
+
+// parseMapKey parse key of map with type of key field.
+func parseMapKey(fd *desc.FieldDescriptor, s string) (any, error) {
+	var key any
+	var err error
+	switch fd.GetType() {
+	case descriptorpb.FieldDescriptorProto_TYPE_STRING:
+		key = s
+	case descriptorpb.FieldDescriptorProto_TYPE_BOOL:
+		key, err = strconv.ParseBool(s)
+	case descriptorpb.FieldDescriptorProto_TYPE_INT32, descriptorpb.FieldDescriptorProto_TYPE_SINT32,
+		descriptorpb.FieldDescriptorProto_TYPE_SFIXED32:
+		var n int64
+		n, err = strconv.ParseInt(s, 10, 32)
+		key = int32(n)
+	case descriptorpb.FieldDescriptorProto_TYPE_UINT32, descriptorpb.FieldDescriptorProto_TYPE_FIXED32:
+		var n uint64
+		n, err = strconv.ParseUint(s, 10, 32)
+		key = uint32(n)
+	case descriptorpb.FieldDescriptorProto_TYPE_UINT64, descriptorpb.FieldDescriptorProto_TYPE_FIXED64:
+		key, err = strconv.ParseUint(s, 10, 64)
+	default:
+		key, err = strconv.ParseInt(s, 10, 64)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid map key %q: %w", s, err)
+	}
+
+	return key, nil
+}
+
+// formatBound format bound of range, empty bound is formatted as infinity.
+func formatBound(b *float64, infinity string) string {
+	if b == nil {
+		return infinity
+	}
+
+	return strconv.FormatFloat(*b, 'g', -1, 64)
+}
@@ -0,0 +1,166 @@
+package proto
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jhump/protoreflect/dynamic"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/AndreyNiki/grpc-highloader/internal/entity"
+	"github.com/AndreyNiki/grpc-highloader/internal/metrics"
+	"github.com/AndreyNiki/grpc-highloader/internal/utils/ptr"
+)
+
+func TestAssertions_Check(t *testing.T) {
+	methodDesc, err := NewProtoParser().GetMethodDescriptor("testdata/rest.proto", "UpdateBook",
+		"example.rest.v1.BookService")
+	require.NoError(t, err)
+	book := dynamic.NewMessage(methodDesc.GetOutputType())
+	require.NoError(t, book.UnmarshalJSON([]byte(
+		`{"book": {"name": "shelves/1/books/2", "format": "FORMAT_PAPER", "tags": ["new", "sale"]}}`)))
+	empty := dynamic.NewMessage(methodDesc.GetOutputType())
+
+	tests := []struct {
+		name       string
+		assertions entity.Assertions
+		message    *dynamic.Message
+		code       codes.Code
+		wantFailed string
+	}{
+		{name: "no assertions", message: book},
+		{
+			name: "fields pass",
+			assertions: entity.Assertions{Fields: []entity.FieldAssertion{
+				{Path: "book.name", Op: entity.AssertionOpEquals, Value: "shelves/1/books/2"},
+				{Path: "book.format", Op: entity.AssertionOpEquals, Value: "FORMAT_PAPER"},
+				{Path: "book.tags", Op: entity.AssertionOpContains, Value: "sale"},
+				{Path: "book.tags.0", Op: entity.AssertionOpRegex, Value: "^n"},
+				{Path: "book.tags", Op: entity.AssertionOpRange, Min: ptr.ToPtr(1.0), Max: ptr.ToPtr(2.0)},
+				{Path: "book.title", Op: entity.AssertionOpRange, Max: ptr.ToPtr(0.0)},
+			}},
+			message: book,
+		},
+		{
+			name: "empty list",
+			assertions: entity.Assertions{Fields: []entity.FieldAssertion{
+				{Path: "book.tags", Op: entity.AssertionOpRange, Min: ptr.ToPtr(1.0)},
+			}},
+			message:    empty,
+			wantFailed: "book.tags in range [1, +inf]",
+		},
+		{
+			name: "missing item",
+			assertions: entity.Assertions{Fields: []entity.FieldAssertion{
+				{Path: "book.tags.2", Op: entity.AssertionOpContains, Value: "a"},
+			}},
+			message:    book,
+			wantFailed: `book.tags.2 contains "a"`,
+		},
+		{
+			name:       "unexpected code",
+			assertions: entity.Assertions{Code: ptr.ToPtr(codes.NotFound)},
+			message:    book,
+			wantFailed: "code = NotFound",
+		},
+		{
+			name:       "expected error code",
+			assertions: entity.Assertions{Code: ptr.ToPtr(codes.NotFound), Headers: []string{"X-Request-Id"}},
+			code:       codes.NotFound,
+		},
+		{
+			name:       "missing trailer",
+			assertions: entity.Assertions{Trailers: []string{"x-checksum"}},
+			message:    book,
+			wantFailed: "trailer x-checksum",
+		},
+		{
+			name:       "slow",
+			assertions: entity.Assertions{MaxLatency: time.Millisecond},
+			message:    book,
+			wantFailed: "latency <= 1ms",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := newAssertions(&tt.assertions, methodDesc.GetOutputType())
+			require.NoError(t, err)
+
+			resp := &response{
+				message: tt.message,
+				header:  metadata.Pairs("x-request-id", "1"),
+				latency: 2 * time.Millisecond,
+			}
+			failure := a.check(resp, status.New(tt.code, ""))
+			if tt.wantFailed == "" {
+				assert.Nil(t, failure)
+				return
+			}
+			require.NotNil(t, failure)
+			assert.Equal(t, tt.wantFailed, failure.assertion)
+		})
+	}
+}
+
+func TestNewAssertions_Invalid(t *testing.T) {
+	methodDesc, err := NewProtoParser().GetMethodDescriptor("testdata/rest.proto", "UpdateBook",
+		"example.rest.v1.BookService")
+	require.NoError(t, err)
+
+	for _, f := range []entity.FieldAssertion{
+		{Path: "book.author", Op: entity.AssertionOpEquals},
+		{Path: "book.name.first", Op: entity.AssertionOpEquals},
+		{Path: "book.tags", Op: entity.AssertionOpEquals},
+		{Path: "book.tags.first", Op: entity.AssertionOpEquals},
+		{Path: "book", Op: entity.AssertionOpRange},
+		{Path: "book.name", Op: entity.AssertionOpRegex, Value: "("},
+	} {
+		_, err := newAssertions(&entity.Assertions{Fields: []entity.FieldAssertion{f}}, methodDesc.GetOutputType())
+		assert.Error(t, err, f.Path)
+	}
+}
+
+func TestRequester_Assertions(t *testing.T) {
+	host := newTestServer(t, func(ctx context.Context, md metadata.MD) error {
+		if len(md.Get("x-not-found")) != 0 {
+			return status.Error(codes.NotFound, "user not found")
+		}
+		return nil
+	})
+	req := newTestRequest(t, host)
+	req.Assertions = &entity.Assertions{Fields: []entity.FieldAssertion{
+		{Path: "id", Op: entity.AssertionOpEquals, Value: "1"},
+	}}
+	m := metrics.InitMetrics()
+	r, err := NewRequester(req, m)
+	require.NoError(t, err)
+	defer r.Close()
+
+	// Empty response has OK status, but it is not counted as successful.
+	err = r.SendUnaryRPCRequest(context.Background())
+	assert.EqualError(t, err, `assertion id equals "1" failed: got ""`)
+	// Responses with error status are not checked without expected code.
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-not-found", "1")
+	assert.Equal(t, codes.NotFound, status.Code(r.SendUnaryRPCRequest(ctx)))
+
+	assert.Equal(t, map[metrics.Outcome]int64{
+		metrics.OutcomeStatus: 1, metrics.OutcomeTransport: 0, metrics.OutcomeMarshal: 0, metrics.OutcomeDeadline: 0,
+		metrics.OutcomeAssertion: 1,
+	}, m.Outcomes.Snapshot())
+	assert.Zero(t, m.StatusCounts()[codes.OK])
+	assert.Len(t, m.TopErrors(10), 1)
+	backends := m.Backends.Snapshot()
+	require.Len(t, backends, 1)
+	assert.Equal(t, int64(2), backends[0].Errors, "failed assertion is an error of backend")
+
+	stat := m.Assertions.Snapshot()
+	assert.Zero(t, stat.Passed)
+	assert.Equal(t, []metrics.AssertionStat{{Assertion: `id equals "1"`, Count: 1}}, stat.Failed)
+	require.Len(t, stat.Samples, 1)
+	assert.Equal(t, `got ""`, stat.Samples[0].Message)
+}
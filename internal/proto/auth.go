@@ -0,0 +1,281 @@
+package proto
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/credentials"
+
+	"github.com/AndreyNiki/grpc-highloader/internal/entity"
+)
+
+const (
+	authorizationHeader = "authorization"
+	// tokenRefreshBefore token is refreshed this time before expiry, for short-lived tokens the half of lifetime is used.
+	tokenRefreshBefore = time.Minute
+	// execTokenTTL lifetime of token printed by exec command without expiry.
+	execTokenTTL = 5 * time.Minute
+	// tokenRequestTimeout timeout of fetching token by request to token endpoint or exec command.
+	tokenRequestTimeout = 30 * time.Second
+	// tokenRetryBackoff time after failed fetch of token when the next fetch is not tried.
+	tokenRetryBackoff = time.Second
+	// maxErrorBodyLen max length of response body from token endpoint in error.
+	maxErrorBodyLen = 512
+)
+
+// tokenSource fetch token with its expiry, zero expiry means that token never expires.
+type tokenSource func(ctx context.Context) (token string, expiry time.Time, err error)
+
+// newPerRPCCredentials create credentials which are sent with every call, nil params means no credentials.
+func newPerRPCCredentials(params *entity.AuthParams) (credentials.PerRPCCredentials, error) {
+	if params == nil {
+		return nil, nil
+	}
+
+	var source tokenSource
+	switch params.Type {
+	case entity.AuthTypeBearer:
+		if params.Token == "" {
+			return nil, errors.New("bearer token is empty")
+		}
+		source = func(context.Context) (string, time.Time, error) {
+			return params.Token, time.Time{}, nil
+		}
+	case entity.AuthTypeClientCredentials:
+		if params.TokenURL == "" || params.ClientID == "" {
+			return nil, errors.New("token URL and client ID are required for client credentials")
+		}
+		source = clientCredentialsSource(params, &http.Client{})
+	case entity.AuthTypeExec:
+		if params.Command == "" {
+			return nil, errors.New("auth command is empty")
+		}
+		source = execSource(params.Command)
+	default:
+		return nil, fmt.Errorf("unknown auth type %d", params.Type)
+	}
+
+	return newTokenCredentials(source), nil
+}
+
+// tokenCredentials implements credentials.PerRPCCredentials with bearer token.
+// The token is cached and fetched again in background before it expires. If fetch fails, the cached token is used
+// until its expiry and fetch is retried after backoff.
+type tokenCredentials struct {
+	source    tokenSource
+	now       func() time.Time
+	mu        sync.Mutex
+	token     string
+	expiry    time.Time
+	refreshAt time.Time
+	// fetching fetch in progress, nil if there is none.
+	fetching *tokenFetch
+	// err error of the last failed fetch, it is returned until retryAt if there is no valid token.
+	err     error
+	retryAt time.Time
+}
+
+// tokenFetch single fetch of token, its result is set before done is closed.
+type tokenFetch struct {
+	done  chan struct{}
+	token string
+	err   error
+}
+
+// newTokenCredentials create a new tokenCredentials.
+func newTokenCredentials(source tokenSource) *tokenCredentials {
+	return &tokenCredentials{
+		source: source,
+		now:    time.Now,
+	}
+}
+
+// GetRequestMetadata return metadata with authorization header.
+func (c *tokenCredentials) GetRequestMetadata(context.Context, ...string) (map[string]string, error) {
+	token, err := c.getToken()
+	if err != nil {
+		return nil, fmt.Errorf("could not get auth token: %w", err)
+	}
+
+	return map[string]string{authorizationHeader: "Bearer " + token}, nil
+}
+
+// RequireTransportSecurity return false, load tests are often run against plaintext servers.
+func (c *tokenCredentials) RequireTransportSecurity() bool {
+	return false
+}
+
+// getToken return cached token and start its refresh when it is due. Calls wait for fetch only if there is
+// no valid token, concurrent calls wait for one fetch.
+func (c *tokenCredentials) getToken() (string, error) {
+	c.mu.Lock()
+	now := c.now()
+	if c.token != "" && (c.refreshAt.IsZero() || now.Before(c.refreshAt)) {
+		defer c.mu.Unlock()
+		return c.token, nil
+	}
+	f := c.fetching
+	if f == nil && !now.Before(c.retryAt) {
+		f = &tokenFetch{done: make(chan struct{})}
+		c.fetching = f
+		go c.fetch(f, now)
+	}
+	valid := c.token != "" && (c.expiry.IsZero() || now.Before(c.expiry))
+	token, err := c.token, c.err
+	c.mu.Unlock()
+
+	if valid {
+		return token, nil
+	}
+	if f == nil {
+		return "", err
+	}
+	<-f.done
+	return f.token, f.err
+}
+
+// fetch get a new token from source and cache it.
+// Fetch is not bound to context of call, so short deadlines of requests don't cancel it.
+func (c *tokenCredentials) fetch(f *tokenFetch, now time.Time) {
+	defer close(f.done)
+	ctx, cancel := context.WithTimeout(context.Background(), tokenRequestTimeout)
+	defer cancel()
+	token, expiry, err := c.source(ctx)
+	if err == nil && token == "" {
+		err = errors.New("token is empty")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.fetching = nil
+	f.token, f.err = token, err
+	if err != nil {
+		c.err = err
+		c.retryAt = now.Add(tokenRetryBackoff)
+		return
+	}
+
+	c.token = token
+	c.expiry = expiry
+	c.err = nil
+	c.retryAt = time.Time{}
+	c.refreshAt = time.Time{}
+	if !expiry.IsZero() {
+		c.refreshAt = expiry.Add(-min(tokenRefreshBefore, expiry.Sub(now)/2))
+	}
+}
+
+// tokenResponse response of token endpoint or output of exec command in JSON.
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	Token       string `json:"token"`
+	ExpiresIn   int64  `json:"expires_in"`
+	Expiry      string `json:"expiry"`
+}
+
+// expiry return expiry of token, zero time if it is not set.
+func (t *tokenResponse) expiry(now time.Time) (time.Time, error) {
+	if t.Expiry != "" {
+		return time.Parse(time.RFC3339, t.Expiry)
+	}
+	if t.ExpiresIn > 0 {
+		return now.Add(time.Duration(t.ExpiresIn) * time.Second), nil
+	}
+
+	return time.Time{}, nil
+}
+
+// clientCredentialsSource return source which fetches token by OAuth2 client credentials flow.
+func clientCredentialsSource(params *entity.AuthParams, client *http.Client) tokenSource {
+	return func(ctx context.Context) (string, time.Time, error) {
+		form := url.Values{"grant_type": {"client_credentials"}}
+		if len(params.Scopes) != 0 {
+			form.Set("scope", strings.Join(params.Scopes, " "))
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, params.TokenURL, strings.NewReader(form.Encode()))
+		if err != nil {
+			return "", time.Time{}, err
+		}
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("Accept", "application/json")
+		req.SetBasicAuth(url.QueryEscape(params.ClientID), url.QueryEscape(params.ClientSecret))
+
+		now := time.Now()
+		resp, err := client.Do(req)
+		if err != nil {
+			return "", time.Time{}, err
+		}
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return "", time.Time{}, err
+		}
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			return "", time.Time{}, fmt.Errorf("token endpoint returned %s: %s",
+				resp.Status, body[:min(len(body), maxErrorBodyLen)])
+		}
+
+		var t tokenResponse
+		if err := json.Unmarshal(body, &t); err != nil {
+			return "", time.Time{}, fmt.Errorf("could not parse token response: %w", err)
+		}
+		expiry, err := t.expiry(now)
+		return t.AccessToken, expiry, err
+	}
+}
+
+// execSource return source which runs command and reads token from its output.
+// The output is either a token or JSON with "token" (or "access_token") and "expiry" (or "expires_in").
+// Tokens without expiry are cached for execTokenTTL.
+func execSource(command string) tokenSource {
+	return func(ctx context.Context) (string, time.Time, error) {
+		var cmd *exec.Cmd
+		if runtime.GOOS == "windows" {
+			cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+		} else {
+			cmd = exec.CommandContext(ctx, "sh", "-c", command)
+		}
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+
+		now := time.Now()
+		out, err := cmd.Output()
+		if err != nil {
+			return "", time.Time{}, fmt.Errorf("auth command failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+		}
+
+		output := strings.TrimSpace(string(out))
+		if !strings.HasPrefix(output, "{") {
+			return output, now.Add(execTokenTTL), nil
+		}
+
+		var t tokenResponse
+		if err := json.Unmarshal([]byte(output), &t); err != nil {
+			return "", time.Time{}, fmt.Errorf("could not parse output of auth command: %w", err)
+		}
+		expiry, err := t.expiry(now)
+		if err != nil {
+			return "", time.Time{}, err
+		}
+		if expiry.IsZero() {
+			expiry = now.Add(execTokenTTL)
+		}
+		if t.Token == "" {
+			t.Token = t.AccessToken
+		}
+		return t.Token, expiry, nil
+	}
+}
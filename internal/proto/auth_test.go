@@ -0,0 +1,247 @@
+package proto
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/AndreyNiki/grpc-highloader/internal/entity"
+	"github.com/AndreyNiki/grpc-highloader/internal/metrics"
+)
+
+func TestTokenCredentials_ClientCredentials(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, secret, ok := r.BasicAuth()
+		if !ok || id != "client" || secret != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		assert.Equal(t, "client_credentials", r.FormValue("grant_type"))
+		assert.Equal(t, "read write", r.FormValue("scope"))
+
+		n := calls.Add(1)
+		fmt.Fprintf(w, `{"access_token": "token-%d", "token_type": "Bearer", "expires_in": 600}`, n)
+	}))
+	defer srv.Close()
+
+	creds, err := newPerRPCCredentials(&entity.AuthParams{
+		Type:         entity.AuthTypeClientCredentials,
+		TokenURL:     srv.URL,
+		ClientID:     "client",
+		ClientSecret: "secret",
+		Scopes:       []string{"read", "write"},
+	})
+	require.NoError(t, err)
+	tc := creds.(*tokenCredentials)
+	now := time.Now()
+	tc.now = func() time.Time { return now }
+
+	md, err := tc.GetRequestMetadata(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer token-1", md[authorizationHeader])
+
+	// Token is cached.
+	now = now.Add(8 * time.Minute)
+	md, err = tc.GetRequestMetadata(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer token-1", md[authorizationHeader])
+
+	// Token is refreshed in background a minute before expiry.
+	now = now.Add(time.Minute + time.Second)
+	md, err = tc.GetRequestMetadata(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer token-1", md[authorizationHeader])
+	waitFetch(tc)
+	md, err = tc.GetRequestMetadata(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer token-2", md[authorizationHeader])
+	assert.Equal(t, int32(2), calls.Load())
+
+	t.Run("Test invalid client", func(t *testing.T) {
+		creds, err := newPerRPCCredentials(&entity.AuthParams{
+			Type: entity.AuthTypeClientCredentials, TokenURL: srv.URL, ClientID: "unknown",
+		})
+		require.NoError(t, err)
+		_, err = creds.GetRequestMetadata(context.Background())
+		assert.ErrorContains(t, err, "401")
+	})
+}
+
+func TestTokenCredentials_FailedRefresh(t *testing.T) {
+	var calls atomic.Int32
+	var fail atomic.Bool
+	tc := newTokenCredentials(func(ctx context.Context) (string, time.Time, error) {
+		calls.Add(1)
+		if _, ok := ctx.Deadline(); !ok {
+			return "", time.Time{}, errors.New("fetch without timeout")
+		}
+		if fail.Load() {
+			return "", time.Time{}, errors.New("token endpoint is down")
+		}
+		return fmt.Sprintf("token-%d", calls.Load()), time.Now().Add(10 * time.Minute), nil
+	})
+	now := time.Now()
+	tc.now = func() time.Time { return now }
+
+	// Fetch is not canceled by expired context of call.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	md, err := tc.GetRequestMetadata(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer token-1", md[authorizationHeader])
+
+	// Cached token is used until its expiry, fetch is retried after backoff.
+	fail.Store(true)
+	now = now.Add(9*time.Minute + time.Second)
+	for range 3 {
+		md, err = tc.GetRequestMetadata(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "Bearer token-1", md[authorizationHeader])
+		waitFetch(tc)
+	}
+	assert.Equal(t, int32(2), calls.Load())
+
+	now = now.Add(time.Minute)
+	_, err = tc.GetRequestMetadata(context.Background())
+	assert.ErrorContains(t, err, "token endpoint is down")
+	_, err = tc.GetRequestMetadata(context.Background())
+	assert.ErrorContains(t, err, "token endpoint is down")
+	assert.Equal(t, int32(3), calls.Load())
+
+	fail.Store(false)
+	now = now.Add(tokenRetryBackoff)
+	md, err = tc.GetRequestMetadata(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer token-4", md[authorizationHeader])
+}
+
+func TestTokenCredentials_BackgroundRefresh(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	start := time.Now()
+	tc := newTokenCredentials(func(context.Context) (string, time.Time, error) {
+		n := calls.Add(1)
+		if n > 1 {
+			<-release
+		}
+		return fmt.Sprintf("token-%d", n), start.Add(time.Duration(n) * 10 * time.Minute), nil
+	})
+	now := start
+	tc.now = func() time.Time { return now }
+
+	md, err := tc.GetRequestMetadata(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer token-1", md[authorizationHeader])
+
+	// Calls don't wait for refresh while cached token is valid, only one refresh is started.
+	now = now.Add(9*time.Minute + time.Second)
+	for range 3 {
+		md, err = tc.GetRequestMetadata(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "Bearer token-1", md[authorizationHeader])
+	}
+
+	// Calls wait for refresh in progress when cached token is expired.
+	now = now.Add(time.Minute)
+	got := make(chan string)
+	go func() {
+		md, err := tc.GetRequestMetadata(context.Background())
+		assert.NoError(t, err)
+		got <- md[authorizationHeader]
+	}()
+	close(release)
+	assert.Equal(t, "Bearer token-2", <-got)
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+// waitFetch wait for fetch of token in progress.
+func waitFetch(tc *tokenCredentials) {
+	tc.mu.Lock()
+	f := tc.fetching
+	tc.mu.Unlock()
+	if f != nil {
+		<-f.done
+	}
+}
+
+func TestTokenCredentials_Exec(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("commands are written for sh")
+	}
+
+	tests := []struct {
+		name       string
+		command    string
+		wantToken  string
+		wantExpiry time.Duration
+		wantErr    bool
+	}{
+		{
+			name:       "raw token",
+			command:    "echo raw-token",
+			wantToken:  "raw-token",
+			wantExpiry: execTokenTTL,
+		},
+		{
+			name:       "JSON with expiry",
+			command:    `echo '{"token": "json-token", "expires_in": 3600}'`,
+			wantToken:  "json-token",
+			wantExpiry: time.Hour,
+		},
+		{
+			name:    "failed command",
+			command: "echo failed >&2; exit 1",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Now()
+			token, expiry, err := execSource(tt.command)(context.Background())
+			if tt.wantErr {
+				assert.ErrorContains(t, err, "failed")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantToken, token)
+			assert.WithinDuration(t, start.Add(tt.wantExpiry), expiry, 5*time.Second)
+		})
+	}
+}
+
+func TestRequester_BearerAuth(t *testing.T) {
+	host := newTestServer(t, func(_ context.Context, md metadata.MD) error {
+		if v := md.Get(authorizationHeader); len(v) == 0 || v[0] != "Bearer secret" {
+			return status.Error(codes.Unauthenticated, "invalid token")
+		}
+		return nil
+	})
+
+	for token, wantErr := range map[string]bool{"secret": false, "expired": true} {
+		req := newTestRequest(t, host)
+		req.Auth = &entity.AuthParams{Type: entity.AuthTypeBearer, Token: token}
+		r, err := NewRequester(req, metrics.InitMetrics())
+		require.NoError(t, err)
+
+		err = r.SendUnaryRPCRequest(context.Background())
+		r.Close()
+		if wantErr {
+			assert.Equal(t, codes.Unauthenticated, status.Code(err))
+			continue
+		}
+		assert.NoError(t, err)
+	}
+}
@@ -0,0 +1,67 @@
+package proto
+
+import (
+	"context"
+	"encoding/base64"
+	"strings"
+	"time"
+
+	"github.com/jhump/protoreflect/desc"
+	"github.com/jhump/protoreflect/dynamic"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/AndreyNiki/grpc-highloader/internal/capture"
+	"github.com/AndreyNiki/grpc-highloader/internal/logger"
+	"github.com/AndreyNiki/grpc-highloader/internal/metrics"
+)
+
+// captureAttempt write attempt with its request and response to sink if it is sampled,
+// attempts with any error including failed assertions are failed.
+func captureAttempt(ctx context.Context, sink *capture.Sink, methodDesc *desc.MethodDescriptor, req *dynamic.Message,
+	resp *response, err error) {
+	if !sink.Sample(err != nil) {
+		return
+	}
+
+	outcome, st := outcomeOf(ctx, err)
+	record := &capture.Record{
+		Time:      time.Now(),
+		Method:    methodDesc.GetFullyQualifiedName(),
+		Outcome:   outcome.String(),
+		Code:      st.Code().String(),
+		Message:   st.Message(),
+		LatencyMs: float64(resp.latency) / float64(time.Millisecond),
+		Header:    capturedMetadata(resp.header),
+		Trailer:   capturedMetadata(resp.trailer),
+	}
+	// Message which failed to build is incomplete.
+	if outcome != metrics.OutcomeMarshal {
+		record.Request, _ = req.MarshalJSON()
+	}
+	if resp.message != nil {
+		record.Response, _ = resp.message.MarshalJSON()
+	}
+	if err := sink.Write(record); err != nil {
+		logger.LoggerFromContext(ctx).Error("Error capture request", "Error", err)
+	}
+}
+
+// capturedMetadata return metadata for record, values of binary keys are base64 encoded.
+func capturedMetadata(md metadata.MD) map[string][]string {
+	if len(md) == 0 {
+		return nil
+	}
+
+	captured := make(map[string][]string, len(md))
+	for k, values := range md {
+		if !strings.HasSuffix(k, binaryHeaderSuffix) {
+			captured[k] = values
+			continue
+		}
+		for _, v := range values {
+			captured[k] = append(captured[k], base64.StdEncoding.EncodeToString([]byte(v)))
+		}
+	}
+
+	return captured
+}
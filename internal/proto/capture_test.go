@@ -0,0 +1,62 @@
+package proto
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/AndreyNiki/grpc-highloader/internal/capture"
+	"github.com/AndreyNiki/grpc-highloader/internal/entity"
+	"github.com/AndreyNiki/grpc-highloader/internal/metrics"
+)
+
+func TestRequester_Capture(t *testing.T) {
+	host := newTestServer(t, func(ctx context.Context, md metadata.MD) error {
+		if err := grpc.SetHeader(ctx, metadata.Pairs("x-request-id", "1", "x-trace-bin", "\x01\x02")); err != nil {
+			return err
+		}
+		if len(md.Get("x-not-found")) != 0 {
+			return status.Error(codes.NotFound, "user not found")
+		}
+		return nil
+	})
+	path := filepath.Join(t.TempDir(), "capture.jsonl")
+	req := newTestRequest(t, host)
+	req.Capture = &entity.CaptureParams{Path: path, FirstErrors: 1}
+	r, err := NewRequester(req, metrics.InitMetrics())
+	require.NoError(t, err)
+
+	// Successful responses are not sampled, only the first error is captured.
+	require.NoError(t, r.SendUnaryRPCRequest(context.Background()))
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-not-found", "1")
+	for range 2 {
+		assert.Equal(t, codes.NotFound, status.Code(r.SendUnaryRPCRequest(ctx)))
+	}
+	r.Close()
+
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	require.Len(t, lines, 1)
+	var record capture.Record
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &record))
+	assert.Equal(t, "example.v1.UserService.Get", record.Method)
+	assert.Equal(t, metrics.OutcomeStatus.String(), record.Outcome)
+	assert.Equal(t, "NotFound", record.Code)
+	assert.Equal(t, "user not found", record.Message)
+	assert.Positive(t, record.LatencyMs)
+	assert.JSONEq(t, `{"id": "1"}`, string(record.Request))
+	assert.Nil(t, record.Response)
+	assert.Equal(t, []string{"1"}, record.Header["x-request-id"])
+	assert.Equal(t, []string{"AQI="}, record.Header["x-trace-bin"])
+}
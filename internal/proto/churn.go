@@ -0,0 +1,170 @@
+package proto
+
+import (
+	"context"
+	"time"
+
+	"github.com/jhump/protoreflect/dynamic/grpcdynamic"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/status"
+)
+
+// churnConn short-lived connection of requester in churn mode.
+// It is closed when it is retired and all its requests are done.
+type churnConn struct {
+	conn      *grpc.ClientConn
+	connStats *connStatsHandler
+	stub      grpcdynamic.Stub
+	created   time.Time
+	// ready is closed when connection is ready or err is set.
+	ready    chan struct{}
+	err      error
+	requests int
+	inflight int
+	retired  bool
+}
+
+// churnMode return true if requester opens new connections during load.
+func (r *Requester) churnMode() bool {
+	return r.req.Dial.ChurnRequests > 0 || r.req.Dial.ChurnInterval > 0
+}
+
+// newChurnConn create connection and start connecting in background, time of connecting is recorded to metrics.
+// Connection is never blocking, requests wait until it is ready.
+func (r *Requester) newChurnConn() (*churnConn, error) {
+	req := *r.req
+	req.Dial.Block = false
+	conn, connStats, err := r.newConn(&req)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &churnConn{
+		conn:      conn,
+		connStats: connStats,
+		stub:      grpcdynamic.NewStub(conn),
+		created:   time.Now(),
+		ready:     make(chan struct{}),
+	}
+	go r.connect(c)
+	return c, nil
+}
+
+// connect connection and wait until it is ready, connection which failed to connect is retired at once.
+func (r *Requester) connect(c *churnConn) {
+	defer close(c.ready)
+	defer func() {
+		if c.err != nil {
+			r.mu.Lock()
+			r.retireConn(c)
+			r.mu.Unlock()
+		}
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), connectTimeout(r.req.Dial))
+	defer cancel()
+
+	start := time.Now()
+	c.conn.Connect()
+	for {
+		state := c.conn.GetState()
+		switch state {
+		case connectivity.Ready:
+			r.metrics.ObserveConnect(time.Since(start))
+			return
+		case connectivity.TransientFailure:
+			r.metrics.IncrementTransientFailure()
+			c.err = status.Errorf(codes.Unavailable, "could not connect to %q", c.conn.Target())
+			return
+		case connectivity.Shutdown:
+			c.err = status.Error(codes.Canceled, "connection is closed")
+			return
+		}
+
+		if !c.conn.WaitForStateChange(ctx, state) {
+			c.err = status.Errorf(codes.Unavailable, "could not connect to %q: %v", c.conn.Target(), ctx.Err())
+			return
+		}
+	}
+}
+
+// acquireConn return connection for request, new connection is opened if current one is expired or broken.
+// Connection must be released by releaseConn.
+func (r *Requester) acquireConn() (*churnConn, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c := r.churn
+	if c != nil && (c.broken() || r.req.Dial.ChurnInterval > 0 && time.Since(c.created) >= r.req.Dial.ChurnInterval) {
+		r.retireConn(c)
+		c = nil
+	}
+	if c == nil {
+		var err error
+		c, err = r.newChurnConn()
+		if err != nil {
+			return nil, err
+		}
+		r.churn = c
+	}
+
+	c.requests++
+	c.inflight++
+	if r.req.Dial.ChurnRequests > 0 && c.requests >= r.req.Dial.ChurnRequests {
+		r.retireConn(c)
+	}
+	return c, nil
+}
+
+// releaseConn release connection after request, retired connection is closed after the last request.
+func (r *Requester) releaseConn(c *churnConn) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c.inflight--
+	if c.retired && c.inflight == 0 {
+		c.close()
+	}
+}
+
+// retireConn stop using connection for new requests. Must be called with locked mutex.
+func (r *Requester) retireConn(c *churnConn) {
+	if c.retired {
+		return
+	}
+	c.retired = true
+	if r.churn == c {
+		r.churn = nil
+	}
+	if c.inflight == 0 {
+		c.close()
+	}
+}
+
+// broken return true if connection is not ready after connecting, e.g. transport was lost and it reconnects
+// or is in transient failure. Connection which is still connecting is not broken.
+func (c *churnConn) broken() bool {
+	select {
+	case <-c.ready:
+		return c.conn.GetState() != connectivity.Ready
+	default:
+		return false
+	}
+}
+
+// waitReady wait until connection is ready, error of connecting is returned.
+func (c *churnConn) waitReady(ctx context.Context) error {
+	select {
+	case <-c.ready:
+		return c.err
+	case <-ctx.Done():
+		return status.FromContextError(ctx.Err()).Err()
+	}
+}
+
+// close connection, closed transports are not counted as disconnects.
+func (c *churnConn) close() {
+	c.connStats.close()
+	c.conn.Close()
+}
@@ -0,0 +1,152 @@
+package proto
+
+import (
+	"context"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/stats"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/AndreyNiki/grpc-highloader/internal/metrics"
+)
+
+// openConns count open connections of test server.
+type openConns struct {
+	open atomic.Int64
+}
+
+func (c *openConns) TagRPC(ctx context.Context, _ *stats.RPCTagInfo) context.Context   { return ctx }
+func (c *openConns) HandleRPC(context.Context, stats.RPCStats)                         {}
+func (c *openConns) TagConn(ctx context.Context, _ *stats.ConnTagInfo) context.Context { return ctx }
+func (c *openConns) HandleConn(_ context.Context, s stats.ConnStats) {
+	switch s.(type) {
+	case *stats.ConnBegin:
+		c.open.Add(1)
+	case *stats.ConnEnd:
+		c.open.Add(-1)
+	}
+}
+
+func TestRequester_Churn(t *testing.T) {
+	conns := &openConns{}
+	host := newTestServer(t, nil, grpc.StatsHandler(conns))
+
+	tests := []struct {
+		name           string
+		churnRequests  int
+		churnInterval  time.Duration
+		requests       int
+		pause          time.Duration
+		wantTransports int64
+	}{
+		{name: "every request", churnRequests: 1, requests: 5, wantTransports: 5},
+		{name: "every 3 requests", churnRequests: 3, requests: 6, wantTransports: 2},
+		{name: "every interval", churnInterval: 100 * time.Millisecond, requests: 2, pause: 150 * time.Millisecond,
+			wantTransports: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := metrics.InitMetrics()
+			req := newTestRequest(t, host)
+			req.Dial.ChurnRequests = tt.churnRequests
+			req.Dial.ChurnInterval = tt.churnInterval
+			r, err := NewRequester(req, m)
+			require.NoError(t, err)
+
+			for range tt.requests {
+				require.NoError(t, r.SendUnaryRPCRequest(context.Background()))
+				time.Sleep(tt.pause)
+			}
+			r.Close()
+
+			stat := m.Connection.Snapshot()
+			assert.Equal(t, tt.wantTransports, stat.Transports)
+			assert.Equal(t, tt.wantTransports, m.Connection.Connects.Value.Load())
+			assert.Zero(t, stat.Disconnects)
+			assert.Eventually(t, func() bool { return conns.open.Load() == 0 }, 5*time.Second, 10*time.Millisecond)
+		})
+	}
+
+	t.Run("concurrent requests", func(t *testing.T) {
+		m := metrics.InitMetrics()
+		req := newTestRequest(t, host)
+		req.Dial.ChurnRequests = 1
+		r, err := NewRequester(req, m)
+		require.NoError(t, err)
+
+		var wg sync.WaitGroup
+		for range 20 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				assert.NoError(t, r.SendUnaryRPCRequest(context.Background()))
+			}()
+		}
+		wg.Wait()
+		r.Close()
+
+		assert.Equal(t, int64(20), m.Connection.Transports.Value.Load())
+		assert.Eventually(t, func() bool { return conns.open.Load() == 0 }, 5*time.Second, 10*time.Millisecond)
+	})
+}
+
+func TestRequester_ChurnBrokenConn(t *testing.T) {
+	t.Run("failed to connect", func(t *testing.T) {
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		host := lis.Addr().String()
+		require.NoError(t, lis.Close())
+
+		req := newTestRequest(t, host)
+		req.Dial.ChurnRequests = 100
+		req.Dial.ConnectTimeout = time.Second
+		r, err := NewRequester(req, metrics.InitMetrics())
+		require.NoError(t, err)
+		defer r.Close()
+
+		require.Error(t, r.SendUnaryRPCRequest(context.Background()))
+		r.mu.Lock()
+		assert.Nil(t, r.churn)
+		r.mu.Unlock()
+	})
+
+	t.Run("lost transport after ready", func(t *testing.T) {
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		srv := grpc.NewServer(grpc.UnknownServiceHandler(func(_ any, stream grpc.ServerStream) error {
+			if err := stream.RecvMsg(&emptypb.Empty{}); err != nil {
+				return err
+			}
+			return stream.SendMsg(&emptypb.Empty{})
+		}))
+		go srv.Serve(lis)
+
+		req := newTestRequest(t, lis.Addr().String())
+		req.Dial.ChurnRequests = 100
+		r, err := NewRequester(req, metrics.InitMetrics())
+		require.NoError(t, err)
+		defer r.Close()
+
+		require.NoError(t, r.SendUnaryRPCRequest(context.Background()))
+		r.mu.Lock()
+		c := r.churn
+		r.mu.Unlock()
+		srv.Stop()
+		assert.Eventually(t, c.broken, 5*time.Second, 10*time.Millisecond)
+
+		acquired, err := r.acquireConn()
+		require.NoError(t, err)
+		r.releaseConn(acquired)
+		assert.NotSame(t, c, acquired)
+		r.mu.Lock()
+		assert.True(t, c.retired)
+		r.mu.Unlock()
+	})
+}
@@ -0,0 +1,197 @@
+package proto
+
+import (
+	"context"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/stats"
+
+	"github.com/AndreyNiki/grpc-highloader/internal/metrics"
+)
+
+const (
+	// http2FrameHeaderLen length of HTTP/2 frame header: length of payload, type, flags and stream.
+	http2FrameHeaderLen = 9
+	http2FrameGoAway    = 0x7
+	insecureProtocol    = "insecure"
+)
+
+// remoteAddrKey key of context with remote address of transport.
+type remoteAddrKey struct{}
+
+// connStatsHandler record lifecycle of transports of connection and sizes of messages to metrics.
+type connStatsHandler struct {
+	metrics *metrics.Metrics
+	// closing is set when connection is closed by requester, so closed transports are not counted.
+	closing atomic.Bool
+	mu      sync.Mutex
+	closed  map[string]struct{}
+}
+
+// newConnStatsHandler create a new connStatsHandler.
+func newConnStatsHandler(metrics *metrics.Metrics) *connStatsHandler {
+	return &connStatsHandler{
+		metrics: metrics,
+		closed:  map[string]struct{}{},
+	}
+}
+
+// TagRPC implements stats.Handler.
+func (h *connStatsHandler) TagRPC(ctx context.Context, _ *stats.RPCTagInfo) context.Context {
+	return ctx
+}
+
+// HandleRPC implements stats.Handler, sizes of messages are recorded.
+func (h *connStatsHandler) HandleRPC(_ context.Context, s stats.RPCStats) {
+	switch s := s.(type) {
+	case *stats.OutPayload:
+		h.metrics.ObserveSent(s.Length, s.WireLength)
+	case *stats.InPayload:
+		h.metrics.ObserveReceived(s.Length, s.WireLength)
+	}
+}
+
+// TagConn implements stats.Handler, remote address is added to context.
+func (h *connStatsHandler) TagConn(ctx context.Context, info *stats.ConnTagInfo) context.Context {
+	if info.RemoteAddr == nil {
+		return ctx
+	}
+
+	return context.WithValue(ctx, remoteAddrKey{}, info.RemoteAddr.String())
+}
+
+// HandleConn implements stats.Handler.
+func (h *connStatsHandler) HandleConn(ctx context.Context, s stats.ConnStats) {
+	addr, _ := ctx.Value(remoteAddrKey{}).(string)
+	switch s.(type) {
+	case *stats.ConnBegin:
+		h.mu.Lock()
+		_, reconnect := h.closed[addr]
+		h.mu.Unlock()
+		h.metrics.ObserveTransportOpen(reconnect)
+	case *stats.ConnEnd:
+		if h.closing.Load() {
+			return
+		}
+		h.mu.Lock()
+		h.closed[addr] = struct{}{}
+		h.mu.Unlock()
+		h.metrics.ObserveTransportClose()
+	}
+}
+
+// close stop counting of closed transports.
+func (h *connStatsHandler) close() {
+	h.closing.Store(true)
+}
+
+// statsCredentials transport credentials which record time of handshakes and GOAWAY frames.
+type statsCredentials struct {
+	credentials.TransportCredentials
+	metrics *metrics.Metrics
+}
+
+// newStatsCredentials create a new statsCredentials.
+func newStatsCredentials(creds credentials.TransportCredentials, metrics *metrics.Metrics) *statsCredentials {
+	return &statsCredentials{
+		TransportCredentials: creds,
+		metrics:              metrics,
+	}
+}
+
+// ClientHandshake do handshake of wrapped credentials, insecure handshakes are not recorded.
+func (c *statsCredentials) ClientHandshake(
+	ctx context.Context,
+	authority string,
+	rawConn net.Conn,
+) (net.Conn, credentials.AuthInfo, error) {
+	start := time.Now()
+	conn, info, err := c.TransportCredentials.ClientHandshake(ctx, authority, rawConn)
+	if c.Info().SecurityProtocol != insecureProtocol {
+		c.metrics.ObserveHandshake(time.Since(start), err != nil)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &goAwayConn{Conn: conn, metrics: c.metrics}, info, nil
+}
+
+// Clone implements credentials.TransportCredentials.
+func (c *statsCredentials) Clone() credentials.TransportCredentials {
+	return newStatsCredentials(c.TransportCredentials.Clone(), c.metrics)
+}
+
+// goAwayConn count GOAWAY frames received by HTTP/2 client, frames are tracked by their headers.
+type goAwayConn struct {
+	net.Conn
+	metrics   *metrics.Metrics
+	header    [http2FrameHeaderLen]byte
+	headerLen int
+	// skip length of the rest of payload of current frame.
+	skip int
+}
+
+// Read implements net.Conn.
+func (c *goAwayConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	c.scan(b[:n])
+	return n, err
+}
+
+// scan frames in read data. Server doesn't send connection preface, so data starts with frame.
+func (c *goAwayConn) scan(data []byte) {
+	for len(data) > 0 {
+		if c.skip > 0 {
+			n := min(c.skip, len(data))
+			c.skip -= n
+			data = data[n:]
+			continue
+		}
+
+		n := copy(c.header[c.headerLen:], data)
+		c.headerLen += n
+		data = data[n:]
+		if c.headerLen < http2FrameHeaderLen {
+			return
+		}
+		c.headerLen = 0
+		c.skip = int(c.header[0])<<16 | int(c.header[1])<<8 | int(c.header[2])
+		if c.header[3] == http2FrameGoAway {
+			c.metrics.IncrementGoAway()
+		}
+	}
+}
+
+// watchConnState record transient failures and time of connecting to READY state until ctx is done.
+func watchConnState(ctx context.Context, conn *grpc.ClientConn, metrics *metrics.Metrics) {
+	var connecting time.Time
+	state := conn.GetState()
+	for {
+		switch state {
+		case connectivity.Connecting:
+			if connecting.IsZero() {
+				connecting = time.Now()
+			}
+		case connectivity.Ready:
+			if !connecting.IsZero() {
+				metrics.ObserveConnect(time.Since(connecting))
+			}
+			connecting = time.Time{}
+		case connectivity.TransientFailure:
+			metrics.IncrementTransientFailure()
+			connecting = time.Time{}
+		}
+
+		if !conn.WaitForStateChange(ctx, state) {
+			return
+		}
+		state = conn.GetState()
+	}
+}
@@ -0,0 +1,58 @@
+package proto
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/keepalive"
+
+	"github.com/AndreyNiki/grpc-highloader/internal/metrics"
+)
+
+func TestGoAwayConn_Scan(t *testing.T) {
+	settings := []byte{0, 0, 6, 0x4, 0, 0, 0, 0, 0, 0, 3, 0, 0, 0, 100}
+	goAway := []byte{0, 0, 8, http2FrameGoAway, 0, 0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 0}
+	data := append(append(append([]byte{}, settings...), goAway...), goAway...)
+
+	for _, chunk := range []int{1, 4, 9, len(data)} {
+		m := metrics.InitMetrics()
+		c := &goAwayConn{metrics: m}
+		for i := 0; i < len(data); i += chunk {
+			c.scan(data[i:min(i+chunk, len(data))])
+		}
+		assert.Equal(t, int64(2), m.Connection.GoAways.Value.Load(), "chunk %d", chunk)
+	}
+}
+
+func TestRequester_ConnectionStats(t *testing.T) {
+	// Server closes connections with GOAWAY like on rolling restart.
+	host := newTestServer(t, nil, grpc.KeepaliveParams(keepalive.ServerParameters{
+		MaxConnectionAge:      100 * time.Millisecond,
+		MaxConnectionAgeGrace: time.Second,
+	}))
+
+	m := metrics.InitMetrics()
+	r, err := NewRequester(newTestRequest(t, host), m)
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		_ = r.SendUnaryRPCRequest(context.Background())
+		return m.Connection.Reconnects.Value.Load() > 0
+	}, 5*time.Second, 10*time.Millisecond)
+	r.Close()
+
+	stat := m.Connection.Snapshot()
+	assert.GreaterOrEqual(t, stat.Transports, int64(2))
+	assert.Positive(t, stat.GoAways)
+	assert.Positive(t, stat.Disconnects)
+	assert.Positive(t, stat.AvgConnectLatency)
+	assert.Zero(t, stat.HandshakeErrors)
+	assert.Zero(t, stat.AvgHandshakeLatency, "insecure handshakes are not recorded")
+
+	// Closing of requester is not a disconnect.
+	assert.Equal(t, stat, m.Connection.Snapshot())
+}
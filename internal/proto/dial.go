@@ -0,0 +1,87 @@
+package proto
+
+import (
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/backoff"
+	"google.golang.org/grpc/encoding"
+	// Register gzip compressor.
+	_ "google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/keepalive"
+
+	"github.com/AndreyNiki/grpc-highloader/internal/entity"
+)
+
+// defaultConnectTimeout max time of connecting of connection if connect timeout is not set.
+const defaultConnectTimeout = 20 * time.Second
+
+// connectTimeout return max time of connecting of connection.
+func connectTimeout(params entity.DialParams) time.Duration {
+	if params.ConnectTimeout > 0 {
+		return params.ConnectTimeout
+	}
+
+	return defaultConnectTimeout
+}
+
+// newDialOptions make dial options from params, zero values are skipped.
+func newDialOptions(params entity.DialParams) ([]grpc.DialOption, error) {
+	var opts []grpc.DialOption
+	if params.KeepaliveTime > 0 {
+		opts = append(opts, grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                params.KeepaliveTime,
+			Timeout:             params.KeepaliveTimeout,
+			PermitWithoutStream: params.KeepalivePermitWithoutStream,
+		}))
+	}
+
+	var callOpts []grpc.CallOption
+	if params.MaxSendMsgSize > 0 {
+		callOpts = append(callOpts, grpc.MaxCallSendMsgSize(params.MaxSendMsgSize))
+	}
+	if params.MaxRecvMsgSize > 0 {
+		callOpts = append(callOpts, grpc.MaxCallRecvMsgSize(params.MaxRecvMsgSize))
+	}
+	if params.Compression != "" {
+		if encoding.GetCompressor(params.Compression) == nil {
+			return nil, fmt.Errorf("compressor %q is not registered", params.Compression)
+		}
+		callOpts = append(callOpts, grpc.UseCompressor(params.Compression))
+	}
+	if len(callOpts) != 0 {
+		opts = append(opts, grpc.WithDefaultCallOptions(callOpts...))
+	}
+
+	if params.InitialWindowSize > 0 {
+		opts = append(opts, grpc.WithInitialWindowSize(params.InitialWindowSize))
+	}
+	if params.InitialConnWindowSize > 0 {
+		opts = append(opts, grpc.WithInitialConnWindowSize(params.InitialConnWindowSize))
+	}
+	if params.UserAgent != "" {
+		opts = append(opts, grpc.WithUserAgent(params.UserAgent))
+	}
+	if params.ConnectTimeout > 0 {
+		opts = append(opts, grpc.WithConnectParams(grpc.ConnectParams{
+			Backoff:           backoff.DefaultConfig,
+			MinConnectTimeout: params.ConnectTimeout,
+		}))
+	}
+	switch {
+	case params.Proxy != "":
+		proxy, err := parseProxyURL(params.Proxy)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, grpc.WithContextDialer(newProxyDialer(proxy, params.Dialer, params.UserAgent)))
+	case params.Dialer != nil:
+		opts = append(opts, grpc.WithContextDialer(params.Dialer))
+	}
+	if params.Block {
+		opts = append(opts, grpc.WithBlock())
+	}
+
+	return opts, nil
+}
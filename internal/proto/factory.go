@@ -1,23 +1,49 @@
 package proto
 
 import (
+	"context"
+	"net"
+
 	"github.com/AndreyNiki/grpc-highloader/internal/entity"
 	"github.com/AndreyNiki/grpc-highloader/internal/loader/interfaces"
 	"github.com/AndreyNiki/grpc-highloader/internal/metrics"
 )
 
 // RequesterFactory implements requester factory for Loader.
-type RequesterFactory struct{}
+type RequesterFactory struct {
+	dialer func(ctx context.Context, addr string) (net.Conn, error)
+}
 
 // NewRequesterFactory create a new RequesterFactory.
 func NewRequesterFactory() *RequesterFactory {
 	return &RequesterFactory{}
 }
 
+// WithDialer set dialer for connections of requesters, e.g. to in-memory listener in tests or embedded usage.
+func (f *RequesterFactory) WithDialer(dialer func(ctx context.Context, addr string) (net.Conn, error)) *RequesterFactory {
+	f.dialer = dialer
+	return f
+}
+
 // NewRequester create a new interfaces.Requester.
 func (f *RequesterFactory) NewRequester(
 	req *entity.RequestParams,
 	metrics *metrics.Metrics,
 ) (interfaces.Requester, error) {
+	if f.dialer != nil && req.Dial.Dialer == nil {
+		r := *req
+		r.Dial.Dialer = f.dialer
+		req = &r
+	}
+
+	return newRequester(req, metrics)
+}
+
+// newRequester create requester for transport of request.
+func newRequester(req *entity.RequestParams, metrics *metrics.Metrics) (interfaces.Requester, error) {
+	if req.Transport != entity.TransportGRPC {
+		return NewHTTPRequester(req, metrics)
+	}
+
 	return NewRequester(req, metrics)
 }
@@ -0,0 +1,69 @@
+package proto
+
+import (
+	"net/http"
+
+	"github.com/jhump/protoreflect/desc"
+	"github.com/jhump/protoreflect/dynamic"
+
+	"github.com/AndreyNiki/grpc-highloader/internal/entity"
+)
+
+// httpRuleExtension extension of method options with HTTP binding.
+const httpRuleExtension = "google.api.http"
+
+// httpRulePatterns fields of HttpRule with path template for HTTP method.
+var httpRulePatterns = map[string]string{
+	"get":    http.MethodGet,
+	"put":    http.MethodPut,
+	"post":   http.MethodPost,
+	"delete": http.MethodDelete,
+	"patch":  http.MethodPatch,
+}
+
+// makeHTTPRule make entity.HTTPRule from google.api.http option of method. Additional bindings are ignored.
+//
+// The annotations proto is not linked into the binary, so extension is taken from imports of the file.
+func (p *ProtoParser) makeHTTPRule(method *desc.MethodDescriptor) *entity.HTTPRule {
+	ext := p.findHTTPRuleExtension(method.GetFile())
+	if ext == nil || method.GetMethodOptions() == nil {
+		return nil
+	}
+
+	er := &dynamic.ExtensionRegistry{}
+	err := er.AddExtension(ext)
+	if err != nil {
+		return nil
+	}
+	opts, err := dynamic.AsDynamicMessageWithExtensionRegistry(method.GetMethodOptions(), er)
+	if err != nil || !opts.HasField(ext) {
+		return nil
+	}
+
+	rule, ok := opts.GetField(ext).(*dynamic.Message)
+	if !ok {
+		return nil
+	}
+	httpRule := &entity.HTTPRule{}
+	httpRule.Body, _ = p.getSetField(rule, "body").(string)
+	httpRule.ResponseBody, _ = p.getSetField(rule, "response_body").(string)
+	for field, httpMethod := range httpRulePatterns {
+		if path, ok := p.getSetField(rule, field).(string); ok {
+			httpRule.Method, httpRule.Path = httpMethod, path
+		}
+	}
+	if custom, ok := p.getSetField(rule, "custom").(*dynamic.Message); ok {
+		httpRule.Method, _ = p.getSetField(custom, "kind").(string)
+		httpRule.Path, _ = p.getSetField(custom, "path").(string)
+	}
+	if httpRule.Method == "" || httpRule.Path == "" {
+		return nil
+	}
+
+	return httpRule
+}
+
+// findHTTPRuleExtension return extension with HTTP binding from imports of file.
+func (p *ProtoParser) findHTTPRuleExtension(fd *desc.FileDescriptor) *desc.FieldDescriptor {
+	return p.findImportedExtension(fd, httpRuleExtension)
+}
@@ -0,0 +1,56 @@
+package proto
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/AndreyNiki/grpc-highloader/internal/metrics"
+)
+
+// outcomeError error of attempt which failed before response was received.
+type outcomeError struct {
+	outcome metrics.Outcome
+	err     error
+}
+
+// newOutcomeError wrap error with outcome, nil is returned for nil error.
+func newOutcomeError(outcome metrics.Outcome, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &outcomeError{outcome: outcome, err: err}
+}
+
+// Error implements error.
+func (e *outcomeError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap return wrapped error.
+func (e *outcomeError) Unwrap() error {
+	return e.err
+}
+
+// GRPCStatus return status of wrapped error, errors without status are Unknown.
+func (e *outcomeError) GRPCStatus() *status.Status {
+	return status.Convert(e.err)
+}
+
+// outcomeOf return outcome of attempt with its status. Expired deadline of context is a separate outcome,
+// because its status is made on client.
+func outcomeOf(ctx context.Context, err error) (metrics.Outcome, *status.Status) {
+	st := status.Convert(err)
+	var outcomeErr *outcomeError
+	if errors.As(err, &outcomeErr) {
+		return outcomeErr.outcome, st
+	}
+	if st.Code() == codes.DeadlineExceeded && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return metrics.OutcomeDeadline, st
+	}
+
+	return metrics.OutcomeStatus, st
+}
@@ -3,6 +3,9 @@ package proto
 import (
 	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
 
 	"github.com/jhump/protoreflect/desc"
 	"github.com/jhump/protoreflect/desc/protoparse"
@@ -11,21 +14,14 @@ import (
 )
 
 // ProtoParser struct for parse proto.
-type ProtoParser struct {
-	parser *protoparse.Parser
-	fp     string
-}
+type ProtoParser struct{}
 
 // NewProtoParser create a new ProtoParser.
 func NewProtoParser() *ProtoParser {
-	parser := &protoparse.Parser{}
-
-	return &ProtoParser{
-		parser: parser,
-	}
+	return &ProtoParser{}
 }
 
-// GetMethodDescriptor return desc.MethodDescriptor.
+// GetMethodDescriptor return desc.MethodDescriptor. The serviceName is fully-qualified name of service.
 func (p *ProtoParser) GetMethodDescriptor(fp, methodName, serviceName string) (*desc.MethodDescriptor, error) {
 	fd, err := p.parseFile(fp)
 	if err != nil {
@@ -33,7 +29,6 @@ func (p *ProtoParser) GetMethodDescriptor(fp, methodName, serviceName string) (*
 	}
 
 	svc := fd.FindSymbol(serviceName)
-	fmt.Println(serviceName)
 	if svc == nil {
 		return nil, errors.New("service not found")
 	}
@@ -63,11 +58,14 @@ func (p *ProtoParser) ParseProto(fp string) (*entity.ParsedProto, error) {
 	return parsedEntity, nil
 }
 
-// parseFile parse file.
+// parseFile parse file. Imports are resolved relative to the directory of the file.
 func (p *ProtoParser) parseFile(fp string) (*desc.FileDescriptor, error) {
-	fds, err := p.parser.ParseFiles(fp)
+	parser := &protoparse.Parser{
+		ImportPaths: []string{filepath.Dir(fp)},
+	}
+	fds, err := parser.ParseFiles(filepath.Base(fp))
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse %q: %w", p.fp, err)
+		return nil, fmt.Errorf("failed to parse %q: %w", fp, err)
 	}
 
 	fd := fds[0]
@@ -76,6 +74,14 @@ func (p *ProtoParser) parseFile(fp string) (*desc.FileDescriptor, error) {
 
 // toEntity convert internal struct to entity.ParsedProto.
 func (p *ProtoParser) toEntity(fd *desc.FileDescriptor, fp string) *entity.ParsedProto {
+	parsed := &entity.ParsedProto{
+		Enums:    make(map[string]entity.Enum),
+		Messages: make(map[string]*entity.Message),
+		Package:  fd.GetPackage(),
+		FilePath: fp,
+	}
+	p.indexFile(parsed, fd, filepath.Dir(fp), make(map[string]struct{}))
+
 	services := fd.GetServices()
 	servicesEntity := make([]entity.Service, 0, len(services))
 	for _, service := range services {
@@ -84,40 +90,98 @@ func (p *ProtoParser) toEntity(fd *desc.FileDescriptor, fp string) *entity.Parse
 		for _, method := range methods {
 			m := entity.Method{
 				Name:           method.GetName(),
-				RequestMessage: p.makeMessage(method.GetInputType()),
-				Type: p.getMethodType(method),
+				FullName:       method.GetFullyQualifiedName(),
+				RequestMessage: p.makeMessage(parsed, method.GetInputType()),
+				Type:           p.getMethodType(method),
+				HTTP:           p.makeHTTPRule(method),
 			}
 			methodsEntity = append(methodsEntity, m)
 		}
 
 		s := entity.Service{
-			Name:    service.GetName(),
-			Methods: methodsEntity,
+			Name:     service.GetName(),
+			FullName: service.GetFullyQualifiedName(),
+			Methods:  methodsEntity,
 		}
 		servicesEntity = append(servicesEntity, s)
 	}
+	parsed.Services = servicesEntity
+
+	return parsed
+}
+
+// indexFile add enums and messages of file and all its imports to the index.
+func (p *ProtoParser) indexFile(
+	parsed *entity.ParsedProto,
+	fd *desc.FileDescriptor,
+	importPath string,
+	visited map[string]struct{},
+) {
+	if _, ok := visited[fd.GetName()]; ok {
+		return
+	}
+	visited[fd.GetName()] = struct{}{}
 
-	enums := fd.GetEnumTypes()
-	enumsEntity := make([]entity.Enum, 0, len(enums))
-	for _, enum := range enums {
-		values := enum.GetValues()
-		valuesEnum := make([]string, 0, len(values))
-		for _, enumValue := range values {
-			valuesEnum = append(valuesEnum, enumValue.GetName())
+	// Well-known imports are built into parser and don't exist on disk.
+	fp, err := filepath.Abs(filepath.Join(importPath, fd.GetName()))
+	if err == nil {
+		if _, err = os.Stat(fp); err == nil {
+			parsed.Files = append(parsed.Files, fp)
 		}
+	}
+
+	for _, dep := range fd.GetDependencies() {
+		p.indexFile(parsed, dep, importPath, visited)
+	}
+	for _, enum := range fd.GetEnumTypes() {
+		p.addEnum(parsed, enum)
+	}
+	for _, msg := range fd.GetMessageTypes() {
+		p.makeMessage(parsed, msg)
+	}
+}
 
-		e := entity.Enum{
-			Name:   enum.GetName(),
-			Values: valuesEnum,
+// findImportedExtension return extension with one of names from imports of file.
+func (p *ProtoParser) findImportedExtension(fd *desc.FileDescriptor, names ...string) *desc.FieldDescriptor {
+	for _, dep := range p.importedFiles(fd.GetDependencies(), nil) {
+		for _, ext := range dep.GetExtensions() {
+			if slices.Contains(names, ext.GetFullyQualifiedName()) {
+				return ext
+			}
 		}
-		enumsEntity = append(enumsEntity, e)
 	}
 
-	return &entity.ParsedProto{
-		Services: servicesEntity,
-		Enums:    enumsEntity,
-		Package:  fd.GetPackage(),
-		FilePath: fp,
+	return nil
+}
+
+// importedFiles append files and files which are publicly imported by them to imported.
+//
+// Recursion.
+func (p *ProtoParser) importedFiles(files, imported []*desc.FileDescriptor) []*desc.FileDescriptor {
+	for _, fd := range files {
+		imported = append(imported, fd)
+		// Calls itself.
+		imported = p.importedFiles(fd.GetPublicDependencies(), imported)
+	}
+
+	return imported
+}
+
+// addEnum add enum to the index.
+func (p *ProtoParser) addEnum(parsed *entity.ParsedProto, enum *desc.EnumDescriptor) {
+	values := enum.GetValues()
+	valuesEnum := make([]string, 0, len(values))
+	numbersEnum := make([]int32, 0, len(values))
+	for _, enumValue := range values {
+		valuesEnum = append(valuesEnum, enumValue.GetName())
+		numbersEnum = append(numbersEnum, enumValue.GetNumber())
+	}
+
+	parsed.Enums[enum.GetFullyQualifiedName()] = entity.Enum{
+		Name:     enum.GetName(),
+		FullName: enum.GetFullyQualifiedName(),
+		Values:   valuesEnum,
+		Numbers:  numbersEnum,
 	}
 }
 
@@ -134,35 +198,52 @@ func (p *ProtoParser) getMethodType(method *desc.MethodDescriptor) entity.Method
 	}
 }
 
-// makeMessage make entity.Message.
+// makeMessage make entity.Message and add it with nested enums and messages to the index.
 //
-// Recursion.
-func (p *ProtoParser) makeMessage(message *desc.MessageDescriptor) *entity.Message {
+// Recursion. Each message is made only once, so recursive messages reference the same *entity.Message.
+func (p *ProtoParser) makeMessage(parsed *entity.ParsedProto, message *desc.MessageDescriptor) *entity.Message {
+	if m, ok := parsed.Messages[message.GetFullyQualifiedName()]; ok {
+		return m
+	}
+	m := &entity.Message{
+		Name:     message.GetName(),
+		FullName: message.GetFullyQualifiedName(),
+	}
+	parsed.Messages[m.FullName] = m
+
+	for _, enum := range message.GetNestedEnumTypes() {
+		p.addEnum(parsed, enum)
+	}
+	for _, nested := range message.GetNestedMessageTypes() {
+		p.makeMessage(parsed, nested)
+	}
+
 	fields := message.GetFields()
 	fieldsEntity := make([]entity.Field, 0, len(fields))
 	for _, field := range fields {
 		f := entity.Field{
-			Name:  field.GetJSONName(),
-			Type:  field.GetType().String(),
-			IsMap: field.IsMap(),
+			Name:       field.GetJSONName(),
+			ProtoName:  field.GetName(),
+			Type:       field.GetType().String(),
+			IsMap:      field.IsMap(),
+			IsRepeated: field.IsRepeated() && !field.IsMap(),
+			Rules:      p.makeFieldRules(field),
 		}
 
 		msg := field.GetMessageType()
 		if msg != nil {
 			// Calls itself.
-			f.Message = p.makeMessage(msg)
+			f.Message = p.makeMessage(parsed, msg)
 		}
 
 		enumType := field.GetEnumType()
 		if enumType != nil {
-			f.EnumName = enumType.GetName()
+			p.addEnum(parsed, enumType)
+			f.EnumName = enumType.GetFullyQualifiedName()
 		}
 		fieldsEntity = append(fieldsEntity, f)
 	}
-	m := entity.Message{
-		Name:   message.GetName(),
-		Fields: fieldsEntity,
-	}
+	m.Fields = fieldsEntity
 
-	return &m
+	return m
 }
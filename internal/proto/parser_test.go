@@ -0,0 +1,92 @@
+package proto
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/AndreyNiki/grpc-highloader/internal/entity"
+	"github.com/AndreyNiki/grpc-highloader/internal/utils/ptr"
+)
+
+func TestProtoParser_ParseProto(t *testing.T) {
+	p := NewProtoParser()
+	parsed, err := p.ParseProto("testdata/example.proto")
+	require.NoError(t, err)
+
+	t.Run("Test nested and imported enums", func(t *testing.T) {
+		role, ok := parsed.FindEnumByName("example.v1.User.Role")
+		require.True(t, ok)
+		assert.Equal(t, []string{"ROLE_UNSPECIFIED", "ROLE_ADMIN"}, role.Values)
+
+		status, ok := parsed.FindEnumByName("example.common.Status")
+		require.True(t, ok)
+		assert.Equal(t, "Status", status.Name)
+
+		user, ok := parsed.FindMessageByName("example.v1.User")
+		require.True(t, ok)
+		assert.Equal(t, "example.v1.User.Role", user.Fields[1].EnumName)
+		assert.Equal(t, "example.common.Status", user.Fields[2].EnumName)
+		assert.Same(t, user, user.Fields[3].Message)
+	})
+
+	t.Run("Test same method names in different services", func(t *testing.T) {
+		get, ok := parsed.FindMethodByName("UserService", "Get")
+		require.True(t, ok)
+		assert.Equal(t, "example.v1.UserService.Get", get.FullName)
+		assert.Equal(t, "example.v1.GetRequest", get.RequestMessage.FullName)
+
+		list, ok := parsed.FindMethodByName("example.v1.AdminService", "Get")
+		require.True(t, ok)
+		assert.Equal(t, "example.v1.AdminService.Get", list.FullName)
+		assert.Equal(t, "example.v1.ListRequest", list.RequestMessage.FullName)
+	})
+
+	t.Run("Test method descriptor", func(t *testing.T) {
+		md, err := p.GetMethodDescriptor("testdata/example.proto", "Get", "example.v1.AdminService")
+		require.NoError(t, err)
+		assert.Equal(t, "example.v1.ListRequest", md.GetInputType().GetFullyQualifiedName())
+	})
+}
+
+func TestProtoParser_ParseProtoRules(t *testing.T) {
+	p := NewProtoParser()
+	parsed, err := p.ParseProto("testdata/validate.proto")
+	require.NoError(t, err)
+
+	msg, ok := parsed.FindMessageByName("example.validate.CreateRequest")
+	require.True(t, ok)
+	rules := make(map[string]*entity.FieldRules)
+	for _, f := range msg.Fields {
+		rules[f.Name] = f.Rules
+	}
+
+	assert.Equal(t, "^[A-Z]{3}-[0-9]{4}$", rules["code"].Pattern)
+	assert.Equal(t, ptr.ToPtr(uint64(8)), rules["name"].MinLen)
+	assert.Equal(t, ptr.ToPtr(uint64(12)), rules["name"].MaxLen)
+	assert.Equal(t, ptr.ToPtr(10.0), rules["count"].Min)
+	assert.True(t, rules["count"].MinExclusive)
+	assert.Equal(t, ptr.ToPtr(20.0), rules["count"].Max)
+	assert.False(t, rules["count"].MaxExclusive)
+	assert.Equal(t, []any{int32(2)}, rules["kind"].In)
+	assert.Equal(t, ptr.ToPtr(uint64(2)), rules["tags"].MinItems)
+	assert.Equal(t, []any{"x", "y"}, rules["tags"].Items.In)
+	assert.Equal(t, "uuid", rules["id"].Format)
+	assert.True(t, rules["inner"].Required)
+}
+
+func TestProtoParser_ParsePublicImports(t *testing.T) {
+	parsed, err := NewProtoParser().ParseProto("testdata/public.proto")
+	require.NoError(t, err)
+
+	msg, ok := parsed.FindMessageByName("example.public.GetRequest")
+	require.True(t, ok)
+	require.Len(t, msg.Fields, 1)
+	require.NotNil(t, msg.Fields[0].Rules)
+	assert.Equal(t, ptr.ToPtr(uint64(3)), msg.Fields[0].Rules.MinLen)
+
+	require.Len(t, parsed.Services, 1)
+	assert.Equal(t, &entity.HTTPRule{Method: http.MethodGet, Path: "/v1/{name}"}, parsed.Services[0].Methods[0].HTTP)
+}
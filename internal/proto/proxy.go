@@ -0,0 +1,111 @@
+package proto
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// proxyScheme the only supported scheme of proxy.
+const proxyScheme = "http"
+
+// parseProxyURL parse URL of HTTP CONNECT proxy, scheme "http" is added if it is not set.
+func parseProxyURL(proxy string) (*url.URL, error) {
+	if !strings.Contains(proxy, "://") {
+		proxy = proxyScheme + "://" + proxy
+	}
+
+	u, err := url.Parse(proxy)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy %q: %w", proxy, err)
+	}
+	if u.Scheme != proxyScheme || u.Host == "" {
+		return nil, fmt.Errorf("invalid proxy %q: only %s://host:port is supported", proxy, proxyScheme)
+	}
+
+	return u, nil
+}
+
+// newProxyDialer create dialer which connects to address through HTTP CONNECT proxy.
+// User info of proxy URL is sent as basic authorization. Nil dial means the default dialer.
+func newProxyDialer(
+	proxy *url.URL,
+	dial func(ctx context.Context, addr string) (net.Conn, error),
+	userAgent string,
+) func(ctx context.Context, addr string) (net.Conn, error) {
+	if dial == nil {
+		dialer := &net.Dialer{}
+		dial = func(ctx context.Context, addr string) (net.Conn, error) {
+			return dialer.DialContext(ctx, "tcp", addr)
+		}
+	}
+
+	return func(ctx context.Context, addr string) (net.Conn, error) {
+		conn, err := dial(ctx, proxy.Host)
+		if err != nil {
+			return nil, fmt.Errorf("could not connect to proxy %q: %w", proxy.Host, err)
+		}
+		conn, err = doConnect(ctx, conn, proxy, addr, userAgent)
+		if err != nil {
+			conn.Close()
+			return nil, err
+		}
+
+		return conn, nil
+	}
+}
+
+// doConnect send CONNECT request to proxy and return connection tunneled to address.
+func doConnect(ctx context.Context, conn net.Conn, proxy *url.URL, addr, userAgent string) (net.Conn, error) {
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+		defer conn.SetDeadline(time.Time{})
+	}
+
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Host: addr},
+		Host:   addr,
+		Header: http.Header{},
+	}
+	if userAgent != "" {
+		req.Header.Set("User-Agent", userAgent)
+	}
+	if proxy.User != nil {
+		password, _ := proxy.User.Password()
+		credentials := base64.StdEncoding.EncodeToString([]byte(proxy.User.Username() + ":" + password))
+		req.Header.Set("Proxy-Authorization", "Basic "+credentials)
+	}
+	if err := req.Write(conn); err != nil {
+		return conn, fmt.Errorf("could not send CONNECT to proxy %q: %w", proxy.Host, err)
+	}
+
+	r := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(r, req)
+	if err != nil {
+		return conn, fmt.Errorf("could not read response of proxy %q: %w", proxy.Host, err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return conn, fmt.Errorf("proxy %q refused CONNECT to %q: %s", proxy.Host, addr, resp.Status)
+	}
+
+	return &bufferedConn{Conn: conn, r: r}, nil
+}
+
+// bufferedConn connection which reads data buffered while reading response of proxy first.
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+// Read implements net.Conn.
+func (c *bufferedConn) Read(b []byte) (int, error) {
+	return c.r.Read(b)
+}
@@ -0,0 +1,135 @@
+package proto
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/AndreyNiki/grpc-highloader/internal/entity"
+	"github.com/AndreyNiki/grpc-highloader/internal/metrics"
+)
+
+// newTestProxy start HTTP CONNECT proxy with basic authorization and return its address and counter of tunnels.
+// Hosts are names which are resolved only by proxy.
+func newTestProxy(t *testing.T, user, password string, hosts map[string]string) (string, *atomic.Int32) {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { lis.Close() })
+
+	wantAuth := "Basic " + base64.StdEncoding.EncodeToString([]byte(user+":"+password))
+	var tunnels atomic.Int32
+	go func() {
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				br := bufio.NewReader(conn)
+				req, err := http.ReadRequest(br)
+				if err != nil {
+					return
+				}
+				if req.Method != http.MethodConnect || req.Header.Get("Proxy-Authorization") != wantAuth {
+					fmt.Fprint(conn, "HTTP/1.1 407 Proxy Authentication Required\r\n\r\n")
+					return
+				}
+				addr := req.Host
+				if resolved, ok := hosts[addr]; ok {
+					addr = resolved
+				}
+				target, err := net.Dial("tcp", addr)
+				if err != nil {
+					fmt.Fprint(conn, "HTTP/1.1 502 Bad Gateway\r\n\r\n")
+					return
+				}
+				defer target.Close()
+				tunnels.Add(1)
+				fmt.Fprint(conn, "HTTP/1.1 200 Connection established\r\n\r\n")
+
+				go io.Copy(target, br)
+				io.Copy(conn, target)
+			}()
+		}
+	}()
+
+	return lis.Addr().String(), &tunnels
+}
+
+func TestRequester_Proxy(t *testing.T) {
+	host := newTestServer(t, func(_ context.Context, md metadata.MD) error {
+		if authority := md.Get(":authority"); len(authority) == 0 || authority[0] != "api.internal" {
+			return status.Errorf(codes.NotFound, "unknown virtual host %v", authority)
+		}
+		return nil
+	})
+	_, port, err := net.SplitHostPort(host)
+	require.NoError(t, err)
+	// Name is unknown for client, so request succeeds only if it is resolved by proxy.
+	proxyOnlyHost := net.JoinHostPort("backend.invalid", port)
+	proxy, tunnels := newTestProxy(t, "user", "secret", map[string]string{proxyOnlyHost: host})
+
+	tests := []struct {
+		name     string
+		host     string
+		proxy    string
+		wantCode codes.Code
+	}{
+		{name: "proxy with authorization", host: host, proxy: "http://user:secret@" + proxy},
+		{name: "invalid password", host: host, proxy: "user:invalid@" + proxy, wantCode: codes.Unavailable},
+		{name: "host resolved by proxy", host: proxyOnlyHost, proxy: "http://user:secret@" + proxy},
+		{name: "dns host resolved by proxy", host: "dns:///" + proxyOnlyHost, proxy: "http://user:secret@" + proxy},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tunnels.Store(0)
+			req := newTestRequest(t, tt.host)
+			req.Dial.Proxy = tt.proxy
+			req.Dial.Authority = "api.internal"
+			r, err := NewRequester(req, metrics.InitMetrics())
+			require.NoError(t, err)
+			defer r.Close()
+
+			err = r.SendUnaryRPCRequest(context.Background())
+			assert.Equal(t, tt.wantCode, status.Code(err), err)
+			if tt.wantCode == codes.OK {
+				assert.Equal(t, int32(1), tunnels.Load())
+			}
+		})
+	}
+
+	t.Run("HTTP transport", func(t *testing.T) {
+		tunnels.Store(0)
+		req := newTestRequest(t, newTestWebServer(t))
+		req.Transport = entity.TransportConnectJSON
+		req.Dial.Proxy = "http://user:secret@" + proxy
+		r, err := newRequester(req, metrics.InitMetrics())
+		require.NoError(t, err)
+		defer r.Close()
+
+		ctx := metadata.NewOutgoingContext(context.Background(), metadata.Pairs("x-test", "value"))
+		assert.NoError(t, r.SendUnaryRPCRequest(ctx))
+		assert.Equal(t, int32(1), tunnels.Load())
+	})
+
+	t.Run("unix socket", func(t *testing.T) {
+		req := newTestRequest(t, "/run/app.sock")
+		req.Dial.Proxy = proxy
+		_, err := NewRequester(req, metrics.InitMetrics())
+		assert.Error(t, err)
+	})
+}
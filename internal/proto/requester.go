@@ -2,19 +2,22 @@ package proto
 
 import (
 	"context"
-	"errors"
 	"fmt"
+	"sync"
+	"time"
 
 	"github.com/golang/protobuf/jsonpb"
 	"github.com/jhump/protoreflect/desc"
 	"github.com/jhump/protoreflect/dynamic"
 	"github.com/jhump/protoreflect/dynamic/grpcdynamic"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
 
+	"github.com/AndreyNiki/grpc-highloader/internal/capture"
 	"github.com/AndreyNiki/grpc-highloader/internal/entity"
-	"github.com/AndreyNiki/grpc-highloader/internal/logger"
 	"github.com/AndreyNiki/grpc-highloader/internal/metrics"
 	"github.com/AndreyNiki/grpc-highloader/internal/templates"
 )
@@ -23,11 +26,20 @@ import (
 type Requester struct {
 	methodDesc *desc.MethodDescriptor
 	conn       *grpc.ClientConn
+	connStats  *connStatsHandler
+	creds      credentials.TransportCredentials
+	auth       credentials.PerRPCCredentials
+	stopWatch  context.CancelFunc
 	stub       grpcdynamic.Stub
 	metrics    *metrics.Metrics
+	assertions *assertions
+	capture    *capture.Sink
 	req        *entity.RequestParams
 	parser     *ProtoParser
 	tb         *templates.TemplateBuilder
+	// mu guards churn, the current connection in churn mode.
+	mu    sync.Mutex
+	churn *churnConn
 }
 
 // NewRequester create a new Requester.
@@ -45,54 +57,132 @@ func NewRequester(req *entity.RequestParams, metrics *metrics.Metrics) (*Request
 		return nil, err
 	}
 	r.methodDesc = methodDesc
+	r.assertions, err = newAssertions(req.Assertions, methodDesc.GetOutputType())
+	if err != nil {
+		return nil, err
+	}
 
-	conn, err := r.newConn(req.Host)
+	// Credentials are shared by all connections, so tokens are cached in churn mode.
+	r.creds, err = newTransportCredentials(req.TLS)
+	if err != nil {
+		return nil, err
+	}
+	r.auth, err = newPerRPCCredentials(req.Auth)
+	if err != nil {
+		return nil, err
+	}
+	r.capture, err = capture.NewSink(req.Capture)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			r.capture.Close()
+		}
+	}()
+
+	if r.churnMode() {
+		r.churn, err = r.newChurnConn()
+		if err != nil {
+			return nil, err
+		}
+		return r, nil
+	}
+
+	conn, connStats, err := r.newConn(req)
 	if err != nil {
 		return nil, err
 	}
 	stub := grpcdynamic.NewStub(conn)
 	r.conn = conn
+	r.connStats = connStats
 	r.stub = stub
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r.stopWatch = cancel
+	go watchConnState(ctx, conn, metrics)
 	return r, nil
 }
 
-// SendUnaryRPCRequest send one unary rpc request.
-func (r *Requester) SendUnaryRPCRequest(ctx context.Context) error {
-	log := logger.LoggerFromContext(ctx)
-	md := r.methodDesc.GetInputType()
-	msg := dynamic.NewMessage(md)
-
-	err := r.makeMessage(msg, r.req.Message)
+// SendUnaryRPCRequest send one unary rpc request, every request is counted with its outcome.
+func (r *Requester) SendUnaryRPCRequest(ctx context.Context) (err error) {
+	r.metrics.IncrementRequestCount()
+	msg := dynamic.NewMessage(r.methodDesc.GetInputType())
+	resp := &response{}
+	defer func() {
+		r.metrics.ObserveOutcome(outcomeOf(ctx, err))
+		captureAttempt(ctx, r.capture, r.methodDesc, msg, resp, err)
+	}()
+
+	err = r.makeMessage(msg, r.req.Message)
 	if err != nil {
-		return err
+		return newOutcomeError(metrics.OutcomeMarshal, err)
 	}
 
-	r.metrics.IncrementRequestCount()
-	resp, err := r.stub.InvokeRpc(ctx, r.methodDesc, msg)
-	if err != nil {
-		return err
+	stub := r.stub
+	if r.churnMode() {
+		// Time of connecting is recorded separately, so latency of request doesn't include it.
+		c, err := r.acquireConn()
+		if err != nil {
+			return newOutcomeError(metrics.OutcomeTransport, err)
+		}
+		defer r.releaseConn(c)
+		if err := c.waitReady(ctx); err != nil {
+			if ctx.Err() != nil {
+				return err
+			}
+			return newOutcomeError(metrics.OutcomeTransport, err)
+		}
+		stub = c.stub
 	}
-	log.Info("Response", resp.String())
 
-	statusErr, ok := status.FromError(err)
-	if !ok {
-		return errors.New("failed to get status from response")
+	var p peer.Peer
+	start := time.Now()
+	out, err := stub.InvokeRpc(ctx, r.methodDesc, msg, grpc.Peer(&p), grpc.Header(&resp.header),
+		grpc.Trailer(&resp.trailer))
+	resp.latency = time.Since(start)
+	resp.message, _ = asDynamicMessage(out)
+	// Request without peer has not reached transport, the status is made by client.
+	if err != nil && p.Addr == nil && status.Code(err) == codes.Unavailable {
+		err = newOutcomeError(metrics.OutcomeTransport, err)
+	} else {
+		// Latency and backend are recorded with outcome of assertions, so failed assertions are counted as errors.
+		err = r.assertions.verify(ctx, r.metrics, resp, err)
+	}
+	r.metrics.ObserveLatency(status.Code(err), resp.latency)
+	if p.Addr != nil {
+		r.metrics.ObserveBackend(p.Addr.String(), resp.latency, err != nil)
 	}
-	r.metrics.IncrementResponseStatus(statusErr.Code())
 
-	return nil
+	return err
 }
 
 // Close requester.
 func (r *Requester) Close() {
+	if r.stopWatch != nil {
+		r.stopWatch()
+	}
 	if r.conn != nil {
+		r.connStats.close()
 		r.conn.Close()
 	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.churn != nil {
+		r.retireConn(r.churn)
+	}
+	r.capture.Close()
 }
 
 // makeMessage make dynamic message for request.
 func (r *Requester) makeMessage(message *dynamic.Message, data string) error {
-	msg, err := r.tb.Process(data)
+	return buildMessage(r.tb, message, data)
+}
+
+// buildMessage process template of message and unmarshal result to dynamic message.
+func buildMessage(tb *templates.TemplateBuilder, message *dynamic.Message, data string) error {
+	msg, err := tb.Process(data)
 	if err != nil {
 		return fmt.Errorf("processing template message failed: %w", err)
 	}
@@ -104,11 +194,47 @@ func (r *Requester) makeMessage(message *dynamic.Message, data string) error {
 	return nil
 }
 
-// newConn create a new connection for grpc.
-func (r *Requester) newConn(host string) (*grpc.ClientConn, error) {
+// clientConn return connection of requester, in churn mode it is the current connection.
+func (r *Requester) clientConn() *grpc.ClientConn {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.churn != nil {
+		return r.churn.conn
+	}
+
+	return r.conn
+}
+
+// newConn create a new connection for grpc with handler of its stats.
+func (r *Requester) newConn(req *entity.RequestParams) (*grpc.ClientConn, *connStatsHandler, error) {
 	ctx := context.Background()
-	var opts []grpc.DialOption
-	opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	opts, err := newDialOptions(req.Dial)
+	if err != nil {
+		return nil, nil, err
+	}
+	target, targetOpts, err := newTarget(req.Host, req.Dial)
+	if err != nil {
+		return nil, nil, err
+	}
+	opts = append(opts, targetOpts...)
+	connStats := newConnStatsHandler(r.metrics)
+	opts = append(opts,
+		grpc.WithTransportCredentials(newStatsCredentials(r.creds, r.metrics)),
+		grpc.WithStatsHandler(connStats))
+	if r.auth != nil {
+		opts = append(opts, grpc.WithPerRPCCredentials(r.auth))
+	}
+
+	if req.Dial.Block {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, connectTimeout(req.Dial))
+		defer cancel()
+	}
+
+	conn, err := grpc.DialContext(ctx, target, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	return grpc.DialContext(ctx, host, opts...)
+	return conn, connStats, nil
 }
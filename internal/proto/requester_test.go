@@ -0,0 +1,398 @@
+package proto
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/AndreyNiki/grpc-highloader/internal/entity"
+	"github.com/AndreyNiki/grpc-highloader/internal/metrics"
+)
+
+// testHandler handler of test server, it receives any request and responds with empty message.
+type testHandler func(ctx context.Context, md metadata.MD) error
+
+// newTestServer start grpc server which handles all methods by handler and return its address.
+func newTestServer(t *testing.T, handler testHandler, opts ...grpc.ServerOption) string {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	opts = append(opts, grpc.UnknownServiceHandler(func(_ any, stream grpc.ServerStream) error {
+		if err := stream.RecvMsg(&emptypb.Empty{}); err != nil {
+			return err
+		}
+		md, _ := metadata.FromIncomingContext(stream.Context())
+		if handler != nil {
+			if err := handler(stream.Context(), md); err != nil {
+				return err
+			}
+		}
+		return stream.SendMsg(&emptypb.Empty{})
+	}))
+	srv := grpc.NewServer(opts...)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	return lis.Addr().String()
+}
+
+// newTestRequest create request params for example.v1.UserService/Get.
+func newTestRequest(t *testing.T, host string) *entity.RequestParams {
+	t.Helper()
+	parsed, err := NewProtoParser().ParseProto("testdata/example.proto")
+	require.NoError(t, err)
+
+	return &entity.RequestParams{
+		Host:    host,
+		Service: "example.v1.UserService",
+		Method:  "Get",
+		Message: `{"id": "1"}`,
+		Proto:   parsed,
+	}
+}
+
+// testCert certificate with key in PEM files.
+type testCert struct {
+	cert     *x509.Certificate
+	key      *ecdsa.PrivateKey
+	certFile string
+	keyFile  string
+}
+
+// newTestCert create certificate signed by parent, nil parent means self-signed CA.
+func newTestCert(t *testing.T, name string, parent *testCert) *testCert {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		DNSNames:     []string{name},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	signer, signerKey := tmpl, key
+	if parent == nil {
+		tmpl.IsCA = true
+		tmpl.BasicConstraintsValid = true
+		tmpl.KeyUsage = x509.KeyUsageCertSign
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, signer, &key.PublicKey, signerKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	c := &testCert{
+		cert:     cert,
+		key:      key,
+		certFile: filepath.Join(dir, name+".crt"),
+		keyFile:  filepath.Join(dir, name+".key"),
+	}
+	require.NoError(t, os.WriteFile(c.certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	require.NoError(t, os.WriteFile(c.keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600))
+	return c
+}
+
+func TestRequester_TLS(t *testing.T) {
+	ca := newTestCert(t, "ca", nil)
+	server := newTestCert(t, "server.test", ca)
+	client := newTestCert(t, "client", ca)
+
+	serverCert, err := tls.LoadX509KeyPair(server.certFile, server.keyFile)
+	require.NoError(t, err)
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+	host := newTestServer(t, nil, grpc.Creds(credentials.NewTLS(&tls.Config{
+		Certificates: []tls.Certificate{serverCert},
+		ClientCAs:    pool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+	})))
+
+	tests := []struct {
+		name    string
+		tls     *entity.TLSParams
+		wantErr bool
+	}{
+		{
+			name: "mutual TLS with custom CA",
+			tls: &entity.TLSParams{
+				CAFile: ca.certFile, CertFile: client.certFile, KeyFile: client.keyFile, ServerName: "server.test",
+			},
+		},
+		{
+			name:    "without client certificate",
+			tls:     &entity.TLSParams{CAFile: ca.certFile, ServerName: "server.test"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown CA",
+			tls:     &entity.TLSParams{CertFile: client.certFile, KeyFile: client.keyFile, ServerName: "server.test"},
+			wantErr: true,
+		},
+		{
+			name: "insecure skip verify",
+			tls:  &entity.TLSParams{CertFile: client.certFile, KeyFile: client.keyFile, InsecureSkipVerify: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := newTestRequest(t, host)
+			req.TLS = tt.tls
+			m := metrics.InitMetrics()
+			r, err := NewRequester(req, m)
+			require.NoError(t, err)
+			defer r.Close()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			err = r.SendUnaryRPCRequest(ctx)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Positive(t, m.Connection.Snapshot().AvgHandshakeLatency)
+		})
+	}
+}
+
+func TestRequester_DialOptions(t *testing.T) {
+	var userAgent string
+	host := newTestServer(t, func(_ context.Context, md metadata.MD) error {
+		userAgent = strings.Join(md.Get("user-agent"), " ")
+		return nil
+	})
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	closedHost := lis.Addr().String()
+	require.NoError(t, lis.Close())
+
+	tests := []struct {
+		name        string
+		host        string
+		dial        entity.DialParams
+		wantDialErr bool
+		wantCode    codes.Code
+	}{
+		{
+			name: "all options",
+			host: host,
+			dial: entity.DialParams{
+				KeepaliveTime:         time.Minute,
+				KeepaliveTimeout:      time.Second,
+				MaxSendMsgSize:        1 << 20,
+				MaxRecvMsgSize:        16 << 20,
+				Compression:           "gzip",
+				InitialWindowSize:     1 << 20,
+				InitialConnWindowSize: 1 << 20,
+				UserAgent:             "highloader-test",
+				Block:                 true,
+				ConnectTimeout:        5 * time.Second,
+			},
+		},
+		{
+			name:     "message is larger than send limit",
+			host:     host,
+			dial:     entity.DialParams{MaxSendMsgSize: 1},
+			wantCode: codes.ResourceExhausted,
+		},
+		{
+			name:        "unknown compressor",
+			host:        host,
+			dial:        entity.DialParams{Compression: "unknown"},
+			wantDialErr: true,
+		},
+		{
+			name:        "connect timeout",
+			host:        closedHost,
+			dial:        entity.DialParams{Block: true, ConnectTimeout: 100 * time.Millisecond},
+			wantDialErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := newTestRequest(t, tt.host)
+			req.Dial = tt.dial
+			r, err := NewRequester(req, metrics.InitMetrics())
+			if tt.wantDialErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			defer r.Close()
+
+			err = r.SendUnaryRPCRequest(context.Background())
+			assert.Equal(t, tt.wantCode, status.Code(err))
+		})
+	}
+	assert.Contains(t, userAgent, "highloader-test")
+}
+
+func TestRequester_UnixSocket(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("unix sockets are not supported")
+	}
+	path := filepath.Join(t.TempDir(), "grpc.sock")
+	lis, err := net.Listen("unix", path)
+	require.NoError(t, err)
+	srv := grpc.NewServer(grpc.UnknownServiceHandler(func(_ any, stream grpc.ServerStream) error {
+		if err := stream.RecvMsg(&emptypb.Empty{}); err != nil {
+			return err
+		}
+		return stream.SendMsg(&emptypb.Empty{})
+	}))
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	hosts := []string{path, "unix://" + path}
+	if runtime.GOOS == "linux" {
+		name := fmt.Sprintf("highloader-test-%d", time.Now().UnixNano())
+		abstractLis, err := net.Listen("unix", "@"+name)
+		require.NoError(t, err)
+		go srv.Serve(abstractLis)
+		hosts = append(hosts, "@"+name, "unix-abstract:"+name)
+	}
+
+	for _, host := range hosts {
+		r, err := NewRequester(newTestRequest(t, host), metrics.InitMetrics())
+		require.NoError(t, err)
+		assert.NoError(t, r.SendUnaryRPCRequest(context.Background()), host)
+		r.Close()
+	}
+}
+
+func TestRequester_Outcomes(t *testing.T) {
+	host := newTestServer(t, func(ctx context.Context, md metadata.MD) error {
+		switch strings.Join(md.Get("x-case"), "") {
+		case "not found":
+			return status.Error(codes.NotFound, "user not found")
+		case "slow":
+			<-ctx.Done()
+		}
+		return nil
+	})
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	closedHost := lis.Addr().String()
+	require.NoError(t, lis.Close())
+
+	tests := []struct {
+		name        string
+		host        string
+		message     string
+		deadline    time.Duration
+		wantOutcome metrics.Outcome
+		wantCode    codes.Code
+	}{
+		{name: "ok", host: host, wantOutcome: metrics.OutcomeStatus},
+		{name: "not found", host: host, wantOutcome: metrics.OutcomeStatus, wantCode: codes.NotFound},
+		{name: "slow", host: host, deadline: 50 * time.Millisecond, wantOutcome: metrics.OutcomeDeadline,
+			wantCode: codes.DeadlineExceeded},
+		{name: "invalid message", host: host, message: `{"id":`, wantOutcome: metrics.OutcomeMarshal,
+			wantCode: codes.Unknown},
+		{name: "closed host", host: closedHost, wantOutcome: metrics.OutcomeTransport, wantCode: codes.Unavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := newTestRequest(t, tt.host)
+			if tt.message != "" {
+				req.Message = tt.message
+			}
+			m := metrics.InitMetrics()
+			r, err := NewRequester(req, m)
+			require.NoError(t, err)
+			defer r.Close()
+
+			ctx := metadata.AppendToOutgoingContext(context.Background(), "x-case", tt.name)
+			if tt.deadline != 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.deadline)
+				defer cancel()
+			}
+			err = r.SendUnaryRPCRequest(ctx)
+			assert.Equal(t, tt.wantCode, status.Code(err))
+
+			outcomes := m.Outcomes.Snapshot()
+			assert.Equal(t, int64(1), m.RequestCounter.Value.Load())
+			assert.Equal(t, int64(1), outcomes[tt.wantOutcome])
+			var total int64
+			for _, count := range outcomes {
+				total += count
+			}
+			assert.Equal(t, m.RequestCounter.Value.Load(), total)
+			if tt.wantOutcome == metrics.OutcomeStatus {
+				assert.Equal(t, int64(1), m.StatusCounts()[tt.wantCode])
+			}
+		})
+	}
+}
+
+func TestRequester_Payload(t *testing.T) {
+	host := newTestServer(t, nil)
+	tests := []struct {
+		name        string
+		compression string
+	}{
+		{name: "uncompressed"},
+		{name: "gzip", compression: "gzip"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := newTestRequest(t, host)
+			req.Dial.Compression = tt.compression
+			m := metrics.InitMetrics()
+			r, err := NewRequester(req, m)
+			require.NoError(t, err)
+			defer r.Close()
+
+			require.NoError(t, r.SendUnaryRPCRequest(context.Background()))
+			require.NoError(t, r.SendUnaryRPCRequest(context.Background()))
+
+			p := m.Payload.Snapshot()
+			// Message {"id": "1"} is encoded as tag, length and one byte of value.
+			assert.Equal(t, int64(6), p.Sent)
+			assert.Equal(t, metrics.SizeStat{Count: 2, Min: 3, Mean: 3, P50: 3, P90: 3, P99: 3, Max: 3}, p.SentSizes)
+			if tt.compression == "" {
+				assert.Equal(t, p.Sent+2*frameHeaderLen, p.WireSent)
+			} else {
+				// Gzip header is larger than such small message.
+				assert.Greater(t, p.WireSent, p.Sent+2*frameHeaderLen)
+			}
+			assert.Zero(t, p.Received)
+			assert.Equal(t, int64(2), p.ReceivedSizes.Count)
+			assert.Equal(t, int64(2*frameHeaderLen), p.WireReceived)
+		})
+	}
+}
@@ -0,0 +1,345 @@
+package proto
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/jhump/protoreflect/desc"
+	"github.com/jhump/protoreflect/dynamic"
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"github.com/AndreyNiki/grpc-highloader/internal/metrics"
+)
+
+const (
+	restContentType = "application/json"
+	// restBodyAll body of HTTP rule for the whole message.
+	restBodyAll = "*"
+	// restStatusClientClosed non-standard HTTP status of canceled requests used by grpc-gateway.
+	restStatusClientClosed = 499
+	// restMetadataPrefix and restTrailerPrefix prefixes of headers with metadata and trailers used by grpc-gateway.
+	restMetadataPrefix = "Grpc-Metadata-"
+	restTrailerPrefix  = "Grpc-Trailer-"
+)
+
+// restPermanentHeaders permanent HTTP headers which are forwarded by grpc-gateway to metadata without prefix.
+var restPermanentHeaders = map[string]struct{}{
+	"Accept": {}, "Accept-Charset": {}, "Accept-Language": {}, "Accept-Ranges": {}, "Authorization": {},
+	"Cache-Control": {}, "Content-Type": {}, "Cookie": {}, "Date": {}, "Expect": {}, "From": {}, "Host": {},
+	"If-Match": {}, "If-Modified-Since": {}, "If-None-Match": {}, "If-Schedule-Tag-Match": {},
+	"If-Unmodified-Since": {}, "Max-Forwards": {}, "Origin": {}, "Pragma": {}, "Referer": {}, "User-Agent": {},
+	"Via": {}, "Warning": {},
+}
+
+// restMetadataHeader return header of metadata key for REST request. The default header matcher of grpc-gateway
+// forwards only permanent HTTP headers and headers with metadata prefix.
+func restMetadataHeader(key string) string {
+	key = http.CanonicalHeaderKey(key)
+	if _, ok := restPermanentHeaders[key]; ok {
+		return key
+	}
+
+	return restMetadataPrefix + key
+}
+
+// invokeREST send message as REST request by HTTP rule of method and fill response, like it is done by grpc-gateway
+// clients. Fields from path template are not sent in body or query params.
+func (r *HTTPRequester) invokeREST(ctx context.Context, msg *dynamic.Message, out *response) error {
+	path, err := expandPathTemplate(r.rule.Path, msg)
+	if err != nil {
+		return newOutcomeError(metrics.OutcomeMarshal, err)
+	}
+
+	var body []byte
+	query := url.Values{}
+	if r.rule.Body == restBodyAll {
+		body, err = msg.MarshalJSONPB(&jsonpb.Marshaler{})
+	} else {
+		body, query, err = splitRESTBody(msg, r.rule.Body)
+	}
+	if err != nil {
+		return newOutcomeError(metrics.OutcomeMarshal, err)
+	}
+	if err := r.checkSendSize(body); err != nil {
+		return err
+	}
+
+	header := http.Header{}
+	header.Set("Accept", restContentType)
+	if body != nil {
+		header.Set("Content-Type", restContentType)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		header.Set("Grpc-Timeout", encodeGRPCTimeout(time.Until(deadline)))
+	}
+
+	u := strings.TrimSuffix(r.url.String(), "/") + path
+	if len(query) != 0 {
+		u += "?" + query.Encode()
+	}
+	resp, err := r.do(ctx, r.rule.Method, u, header, body)
+	if err != nil {
+		return err
+	}
+	r.metrics.ObserveSent(len(body), len(body))
+	respBody := resp.body
+	out.header, out.trailer = newResponseMetadata(resp.Header, restMetadataPrefix, restTrailerPrefix)
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return restError(resp.StatusCode, respBody)
+	}
+	if err := r.checkRecvSize(respBody); err != nil {
+		return err
+	}
+	r.metrics.ObserveReceived(len(respBody), resp.wireLen)
+
+	m := dynamic.NewMessage(r.methodDesc.GetOutputType())
+	if r.rule.ResponseBody != "" {
+		fd := m.FindFieldDescriptorByName(r.rule.ResponseBody)
+		if fd == nil {
+			return fmt.Errorf("response body field %q not found", r.rule.ResponseBody)
+		}
+		respBody = fmt.Appendf(nil, `{%q: %s}`, fd.GetJSONName(), respBody)
+	}
+	err = m.UnmarshalJSONPB(&jsonpb.Unmarshaler{AllowUnknownFields: true}, respBody)
+	if err != nil {
+		return status.Errorf(codes.Internal, "could not unmarshal response: %v", err)
+	}
+	out.message = m
+
+	return nil
+}
+
+// expandPathTemplate substitute values of fields to path template, substituted fields are cleared in message.
+// Values of single segment variables are escaped with slashes, multi-segment variables keep slashes.
+func expandPathTemplate(template string, msg *dynamic.Message) (string, error) {
+	var b strings.Builder
+	rest := template
+	for {
+		start := strings.IndexByte(rest, '{')
+		if start == -1 {
+			b.WriteString(rest)
+			return b.String(), nil
+		}
+		end := strings.IndexByte(rest[start:], '}')
+		if end == -1 {
+			return "", fmt.Errorf("invalid path template %q", template)
+		}
+		end += start
+
+		b.WriteString(rest[:start])
+		fieldPath, pattern, _ := strings.Cut(rest[start+1:end], "=")
+		value, err := takePathField(msg, fieldPath)
+		if err != nil {
+			return "", err
+		}
+		if strings.Contains(pattern, "/") || strings.Contains(pattern, "**") {
+			segments := strings.Split(value, "/")
+			for i, s := range segments {
+				segments[i] = url.PathEscape(s)
+			}
+			b.WriteString(strings.Join(segments, "/"))
+		} else {
+			b.WriteString(url.PathEscape(value))
+		}
+		rest = rest[end+1:]
+	}
+}
+
+// takePathField return value of scalar field by dot separated path and clear the field.
+func takePathField(msg *dynamic.Message, fieldPath string) (string, error) {
+	names := strings.Split(fieldPath, ".")
+	for _, name := range names[:len(names)-1] {
+		fd := msg.FindFieldDescriptorByName(name)
+		if fd == nil || fd.GetMessageType() == nil || fd.IsRepeated() {
+			return "", fmt.Errorf("invalid field path %q in path template", fieldPath)
+		}
+		next, ok := msg.GetField(fd).(*dynamic.Message)
+		if !ok {
+			return "", fmt.Errorf("field %q from path template is not set", fieldPath)
+		}
+		msg = next
+	}
+
+	fd := msg.FindFieldDescriptorByName(names[len(names)-1])
+	if fd == nil || fd.GetMessageType() != nil || fd.IsRepeated() {
+		return "", fmt.Errorf("field %q from path template must be scalar", fieldPath)
+	}
+	value := formatScalar(fd, msg.GetField(fd))
+	if value == "" {
+		return "", fmt.Errorf("field %q from path template is empty", fieldPath)
+	}
+	msg.ClearField(fd)
+
+	return value, nil
+}
+
+// formatScalar format value of scalar field like it is done in JSON, enums are formatted by names.
+func formatScalar(fd *desc.FieldDescriptor, value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case []byte:
+		return base64.URLEncoding.EncodeToString(v)
+	case bool:
+		return strconv.FormatBool(v)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'g', -1, 32)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case int32:
+		if enum := fd.GetEnumType(); enum != nil {
+			if ev := enum.FindValueByNumber(v); ev != nil {
+				return ev.GetName()
+			}
+		}
+		return strconv.FormatInt(int64(v), 10)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// splitRESTBody return JSON of body field and the other fields as query params.
+// Whole message is sent in query params if bodyField is empty.
+func splitRESTBody(msg *dynamic.Message, bodyField string) ([]byte, url.Values, error) {
+	data, err := msg.MarshalJSONPB(&jsonpb.Marshaler{})
+	if err != nil {
+		return nil, nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var fields map[string]any
+	if err := dec.Decode(&fields); err != nil {
+		return nil, nil, err
+	}
+
+	var body []byte
+	if bodyField != "" {
+		fd := msg.FindFieldDescriptorByName(bodyField)
+		if fd == nil {
+			return nil, nil, fmt.Errorf("body field %q not found", bodyField)
+		}
+		value, ok := fields[fd.GetJSONName()]
+		switch {
+		case ok:
+			body, err = json.Marshal(value)
+		case fd.GetMessageType() != nil && !fd.IsRepeated():
+			body = []byte("{}")
+		default:
+			body, err = json.Marshal(fd.GetDefaultValue())
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		delete(fields, fd.GetJSONName())
+	}
+
+	query := url.Values{}
+	err = addQueryParams(query, "", msg.GetMessageDescriptor(), fields)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return body, query, nil
+}
+
+// addQueryParams add fields of message to query params, nested messages are added with dot separated names.
+//
+// Recursion.
+func addQueryParams(query url.Values, prefix string, md *desc.MessageDescriptor, fields map[string]any) error {
+	for name, value := range fields {
+		key := prefix + name
+		fd := md.FindFieldByJSONName(name)
+		if fd == nil {
+			return fmt.Errorf("field %q not found", key)
+		}
+
+		switch v := value.(type) {
+		case map[string]any:
+			if fd.IsMap() || fd.GetMessageType() == nil {
+				return fmt.Errorf("map field %q could not be sent in query params", key)
+			}
+			// Calls itself.
+			if err := addQueryParams(query, key+".", fd.GetMessageType(), v); err != nil {
+				return err
+			}
+		case []any:
+			for _, item := range v {
+				if _, ok := item.(map[string]any); ok {
+					return fmt.Errorf("repeated message field %q could not be sent in query params", key)
+				}
+				query.Add(key, fmt.Sprint(item))
+			}
+		default:
+			query.Add(key, fmt.Sprint(v))
+		}
+	}
+
+	return nil
+}
+
+// restError make status from error of grpc-gateway, HTTP status is mapped if body has no code.
+// Body is google.rpc.Status in JSON, details are decoded if their types are known.
+func restError(httpStatus int, body []byte) error {
+	st := &spb.Status{}
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(body, st); err == nil &&
+		st.GetCode() != 0 && st.GetCode() <= int32(codes.Unauthenticated) {
+		return status.ErrorProto(st)
+	}
+
+	var e struct {
+		Code    uint32 `json:"code"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(body, &e); err == nil && e.Code != 0 && e.Code <= uint32(codes.Unauthenticated) {
+		return status.Error(codes.Code(e.Code), e.Message)
+	}
+
+	return status.Errorf(restStatusToCode(httpStatus), "unexpected HTTP status %d %s",
+		httpStatus, http.StatusText(httpStatus))
+}
+
+// restStatusToCode map HTTP status of REST API to code, it is reverse of mapping in grpc-gateway.
+func restStatusToCode(httpStatus int) codes.Code {
+	switch httpStatus {
+	case http.StatusBadRequest:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.AlreadyExists
+	case http.StatusPreconditionFailed:
+		return codes.FailedPrecondition
+	case http.StatusRequestedRangeNotSatisfiable:
+		return codes.OutOfRange
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case restStatusClientClosed:
+		return codes.Canceled
+	case http.StatusInternalServerError:
+		return codes.Internal
+	case http.StatusNotImplemented:
+		return codes.Unimplemented
+	case http.StatusBadGateway, http.StatusServiceUnavailable:
+		return codes.Unavailable
+	case http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	default:
+		return codes.Unknown
+	}
+}
@@ -0,0 +1,210 @@
+package proto
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/AndreyNiki/grpc-highloader/internal/entity"
+	"github.com/AndreyNiki/grpc-highloader/internal/metrics"
+)
+
+func TestProtoParser_ParseProtoHTTPRules(t *testing.T) {
+	parsed, err := NewProtoParser().ParseProto("testdata/rest.proto")
+	require.NoError(t, err)
+
+	rules := map[string]*entity.HTTPRule{}
+	for _, method := range parsed.Services[0].Methods {
+		rules[method.Name] = method.HTTP
+	}
+	assert.Equal(t, map[string]*entity.HTTPRule{
+		"GetBook":    {Method: http.MethodGet, Path: "/v1/{name=shelves/*/books/*}"},
+		"CreateBook": {Method: http.MethodPost, Path: "/v1/{parent=shelves/*}/books", Body: "book"},
+		"UpdateBook": {
+			Method: http.MethodPut, Path: "/v1/{book.name=shelves/*/books/*}:update", Body: "*", ResponseBody: "book",
+		},
+		"DeleteBook": nil,
+	}, rules)
+}
+
+func TestHTTPRequester_REST(t *testing.T) {
+	type gatewayRequest struct {
+		method, path, query, body string
+	}
+	var got gatewayRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		got = gatewayRequest{method: r.Method, path: r.URL.EscapedPath(), query: r.URL.RawQuery, body: string(body)}
+
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/missing"):
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"code": 5, "message": "book not found", "details": []}`)
+		case strings.HasSuffix(r.URL.Path, "/limited"):
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			fmt.Fprint(w, `{"name": "shelves/1/books/2", "title": "Title", "unknownField": 1}`)
+		}
+	}))
+	defer srv.Close()
+
+	parsed, err := NewProtoParser().ParseProto("testdata/rest.proto")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		method   string
+		message  string
+		want     gatewayRequest
+		wantCode codes.Code
+		wantErr  string
+	}{
+		{
+			name:    "path and query params",
+			method:  "GetBook",
+			message: `{"name": "shelves/1/books/a b", "format": "FORMAT_PAPER", "tags": ["x", "y"], "page": {"size": 10}}`,
+			want: gatewayRequest{
+				method: http.MethodGet,
+				path:   "/v1/shelves/1/books/a%20b",
+				query:  "format=FORMAT_PAPER&page.size=10&tags=x&tags=y",
+			},
+		},
+		{
+			name:    "body field",
+			method:  "CreateBook",
+			message: `{"parent": "shelves/1", "book": {"title": "Title"}, "validateOnly": true}`,
+			want: gatewayRequest{
+				method: http.MethodPost,
+				path:   "/v1/shelves/1/books",
+				query:  "validateOnly=true",
+				body:   `{"title":"Title"}`,
+			},
+		},
+		{
+			name:    "whole message in body and response body field",
+			method:  "UpdateBook",
+			message: `{"book": {"name": "shelves/1/books/2", "title": "Title"}}`,
+			want: gatewayRequest{
+				method: http.MethodPut,
+				path:   "/v1/shelves/1/books/2:update",
+				body:   `{"book":{"title":"Title"}}`,
+			},
+		},
+		{
+			name:     "status from error body",
+			method:   "GetBook",
+			message:  `{"name": "shelves/1/books/missing"}`,
+			wantCode: codes.NotFound,
+		},
+		{
+			name:     "status from HTTP status",
+			method:   "GetBook",
+			message:  `{"name": "shelves/1/books/limited"}`,
+			wantCode: codes.ResourceExhausted,
+		},
+		{
+			name:    "empty path field",
+			method:  "GetBook",
+			message: `{}`,
+			wantErr: `field "name" from path template is empty`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got = gatewayRequest{}
+			r, err := newRequester(&entity.RequestParams{
+				Host:      strings.TrimPrefix(srv.URL, "http://"),
+				Service:   "example.rest.v1.BookService",
+				Method:    tt.method,
+				Message:   tt.message,
+				Proto:     parsed,
+				Transport: entity.TransportREST,
+			}, metrics.InitMetrics())
+			require.NoError(t, err)
+			defer r.Close()
+
+			err = r.SendUnaryRPCRequest(context.Background())
+			switch {
+			case tt.wantErr != "":
+				assert.EqualError(t, err, tt.wantErr)
+			case tt.wantCode != codes.OK:
+				assert.Equal(t, tt.wantCode, status.Code(err), err)
+			default:
+				require.NoError(t, err)
+				if tt.want.body != "" {
+					assert.JSONEq(t, tt.want.body, got.body)
+					got.body = tt.want.body
+				}
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+
+	t.Run("metadata with grpc-gateway prefix", func(t *testing.T) {
+		var header http.Header
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header = r.Header.Clone()
+			fmt.Fprint(w, `{}`)
+		}))
+		defer srv.Close()
+
+		r, err := newRequester(&entity.RequestParams{
+			Host:      strings.TrimPrefix(srv.URL, "http://"),
+			Service:   "example.rest.v1.BookService",
+			Method:    "GetBook",
+			Message:   `{"name": "shelves/1/books/2"}`,
+			Proto:     parsed,
+			Transport: entity.TransportREST,
+		}, metrics.InitMetrics())
+		require.NoError(t, err)
+		defer r.Close()
+
+		ctx := metadata.AppendToOutgoingContext(context.Background(), "x-request-id", "1", "authorization", "Bearer t")
+		require.NoError(t, r.SendUnaryRPCRequest(ctx))
+		assert.Equal(t, "1", header.Get("Grpc-Metadata-X-Request-Id"))
+		assert.Empty(t, header.Get("X-Request-Id"))
+		assert.Equal(t, "Bearer t", header.Get("Authorization"))
+	})
+
+	t.Run("method without HTTP rule", func(t *testing.T) {
+		_, err := newRequester(&entity.RequestParams{
+			Host:      strings.TrimPrefix(srv.URL, "http://"),
+			Service:   "example.rest.v1.BookService",
+			Method:    "DeleteBook",
+			Proto:     parsed,
+			Transport: entity.TransportREST,
+		}, metrics.InitMetrics())
+		assert.Error(t, err)
+	})
+}
+
+func TestRestError(t *testing.T) {
+	body, err := json.Marshal(map[string]any{"code": 3, "message": "field name: must be set"})
+	require.NoError(t, err)
+
+	st := status.Convert(restError(http.StatusBadRequest, body))
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+	assert.Equal(t, "field name: must be set", st.Message())
+	assert.Equal(t, codes.Unavailable, status.Code(restError(http.StatusServiceUnavailable, []byte("<html>"))))
+
+	body = []byte(`{"code": 3, "message": "invalid user", "details": [{"@type": "type.googleapis.com/google.rpc.BadRequest",
+		"fieldViolations": [{"field": "user_id", "description": "must be UUID"}]}]}`)
+	st = status.Convert(restError(http.StatusBadRequest, body))
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+	require.Len(t, st.Details(), 1)
+	badRequest, ok := st.Details()[0].(*errdetails.BadRequest)
+	require.True(t, ok)
+	assert.Equal(t, "user_id", badRequest.GetFieldViolations()[0].GetField())
+}
@@ -0,0 +1,197 @@
+package proto
+
+import (
+	"github.com/jhump/protoreflect/desc"
+	"github.com/jhump/protoreflect/dynamic"
+
+	"github.com/AndreyNiki/grpc-highloader/internal/entity"
+)
+
+// Extensions of field options with validation rules.
+const (
+	protovalidateExtension = "buf.validate.field"
+	pgvExtension           = "validate.rules"
+)
+
+// Names of type-specific rules which are not scalar rules.
+const (
+	rulesMessage  = "message"
+	rulesRepeated = "repeated"
+	rulesMap      = "map"
+	rulesEnum     = "enum"
+)
+
+// stringFormats well-known formats of strings, they are bool rules.
+var stringFormats = map[string]struct{}{
+	"email": {}, "hostname": {}, "ip": {}, "ipv4": {}, "ipv6": {}, "uri": {}, "uri_ref": {}, "address": {},
+	"uuid": {}, "tuuid": {},
+}
+
+// makeFieldRules make entity.FieldRules from buf.validate or protoc-gen-validate options of field.
+//
+// The validate proto is not linked into the binary, so extension is taken from imports of the file.
+func (p *ProtoParser) makeFieldRules(field *desc.FieldDescriptor) *entity.FieldRules {
+	ext := p.findRulesExtension(field.GetFile())
+	if ext == nil || field.GetFieldOptions() == nil {
+		return nil
+	}
+
+	er := &dynamic.ExtensionRegistry{}
+	err := er.AddExtension(ext)
+	if err != nil {
+		return nil
+	}
+	opts, err := dynamic.AsDynamicMessageWithExtensionRegistry(field.GetFieldOptions(), er)
+	if err != nil || !opts.HasField(ext) {
+		return nil
+	}
+
+	rules, ok := opts.GetField(ext).(*dynamic.Message)
+	if !ok {
+		return nil
+	}
+	return p.toFieldRules(rules)
+}
+
+// findRulesExtension return extension with validation rules from imports of file.
+func (p *ProtoParser) findRulesExtension(fd *desc.FileDescriptor) *desc.FieldDescriptor {
+	return p.findImportedExtension(fd, protovalidateExtension, pgvExtension)
+}
+
+// toFieldRules convert FieldRules message to entity.FieldRules.
+//
+// Recursion.
+func (p *ProtoParser) toFieldRules(msg *dynamic.Message) *entity.FieldRules {
+	rules := &entity.FieldRules{}
+	if required, ok := p.getSetField(msg, "required").(bool); ok {
+		rules.Required = required
+	}
+
+	for _, fd := range msg.GetKnownFields() {
+		typed, ok := p.getSetField(msg, fd.GetName()).(*dynamic.Message)
+		if !ok {
+			continue
+		}
+
+		switch fd.GetName() {
+		case rulesMessage:
+			if required, ok := p.getSetField(typed, "required").(bool); ok && required {
+				rules.Required = true
+			}
+		case rulesRepeated:
+			rules.MinItems = p.getUint(typed, "min_items")
+			rules.MaxItems = p.getUint(typed, "max_items")
+			if items, ok := p.getSetField(typed, "items").(*dynamic.Message); ok {
+				// Calls itself.
+				rules.Items = p.toFieldRules(items)
+			}
+		case rulesMap:
+			rules.MinItems = p.getUint(typed, "min_pairs")
+			rules.MaxItems = p.getUint(typed, "max_pairs")
+		case rulesEnum:
+			rules.Const = p.getSetField(typed, "const")
+			rules.In = p.getList(typed, "in")
+			rules.NotIn = p.getList(typed, "not_in")
+			if definedOnly, ok := p.getSetField(typed, "defined_only").(bool); ok {
+				rules.DefinedOnly = definedOnly
+			}
+		default:
+			p.setScalarRules(rules, typed)
+		}
+	}
+
+	return rules
+}
+
+// setScalarRules set rules of strings, bytes, numbers and bools.
+func (p *ProtoParser) setScalarRules(rules *entity.FieldRules, msg *dynamic.Message) {
+	for _, fd := range msg.GetKnownFields() {
+		value := p.getSetField(msg, fd.GetName())
+		if value == nil {
+			continue
+		}
+
+		name := fd.GetName()
+		switch name {
+		case "const":
+			rules.Const = value
+		case "len", "len_bytes":
+			rules.MinLen = p.getUint(msg, name)
+			rules.MaxLen = rules.MinLen
+		case "min_len", "min_bytes":
+			rules.MinLen = p.getUint(msg, name)
+		case "max_len", "max_bytes":
+			rules.MaxLen = p.getUint(msg, name)
+		case "pattern":
+			rules.Pattern, _ = value.(string)
+		case "prefix":
+			rules.Prefix, _ = value.(string)
+		case "suffix":
+			rules.Suffix, _ = value.(string)
+		case "in":
+			rules.In = p.getList(msg, name)
+		case "not_in":
+			rules.NotIn = p.getList(msg, name)
+		case "gt", "gte":
+			if v, ok := toFloat(value); ok {
+				rules.Min = &v
+				rules.MinExclusive = name == "gt"
+			}
+		case "lt", "lte":
+			if v, ok := toFloat(value); ok {
+				rules.Max = &v
+				rules.MaxExclusive = name == "lt"
+			}
+		default:
+			if _, ok := stringFormats[name]; ok && value == true {
+				rules.Format = name
+			}
+		}
+	}
+}
+
+// getSetField return value of field by name or nil if field is not set.
+func (p *ProtoParser) getSetField(msg *dynamic.Message, name string) any {
+	if msg.FindFieldDescriptorByName(name) == nil || !msg.HasFieldName(name) {
+		return nil
+	}
+
+	return msg.GetFieldByName(name)
+}
+
+// getUint return unsigned number of field by name or nil if field is not set.
+func (p *ProtoParser) getUint(msg *dynamic.Message, name string) *uint64 {
+	v, ok := toFloat(p.getSetField(msg, name))
+	if !ok || v < 0 {
+		return nil
+	}
+
+	u := uint64(v)
+	return &u
+}
+
+// getList return values of repeated field by name.
+func (p *ProtoParser) getList(msg *dynamic.Message, name string) []any {
+	values, _ := p.getSetField(msg, name).([]any)
+	return values
+}
+
+// toFloat convert number of any type to float64.
+func toFloat(value any) (float64, bool) {
+	switch v := value.(type) {
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	default:
+		return 0, false
+	}
+}
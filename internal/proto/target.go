@@ -0,0 +1,311 @@
+package proto
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/attributes"
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/resolver/manual"
+
+	"github.com/AndreyNiki/grpc-highloader/internal/entity"
+)
+
+const (
+	// staticScheme scheme of resolver for list of static backends.
+	staticScheme = "highloader-static"
+	// dnsScheme scheme of dns targets.
+	dnsScheme = "dns"
+	// passthroughScheme scheme of targets which are dialed without resolving.
+	passthroughScheme = "passthrough"
+	// unixScheme and unixAbstractScheme schemes of unix domain socket targets.
+	unixScheme         = "unix"
+	unixAbstractScheme = "unix-abstract"
+	// abstractPrefix prefix of abstract unix socket names, e.g. "@highloader".
+	abstractPrefix = "@"
+	// defaultPort port of dns targets without port.
+	defaultPort = "443"
+	// targetSeparator separator of backends in list of static backends.
+	targetSeparator = ","
+	// weightSeparator separator of address and weight of backend, e.g. "10.0.0.1:50051=3".
+	weightSeparator = "="
+	// maxWeight max weight of backend.
+	maxWeight = 100
+)
+
+func init() {
+	balancer.Register(base.NewBalancerBuilder(entity.BalancingPolicyWeightedRoundRobin, &weightedPickerBuilder{},
+		base.Config{HealthCheck: true}))
+}
+
+// newTarget make target for dial and options for its resolving and balancing.
+//
+// Host is either a target of grpc, e.g. "localhost:50051", "dns:///service:50051" or "unix:///run/app.sock",
+// or a list of static backends separated by comma with optional weights, e.g. "10.0.0.1:50051=3,10.0.0.2:50051".
+// Absolute paths and names with "@" prefix are dialed as unix domain sockets and abstract unix sockets.
+func newTarget(host string, params entity.DialParams) (string, []grpc.DialOption, error) {
+	var opts []grpc.DialOption
+	if params.BalancingPolicy != "" {
+		if balancer.Get(params.BalancingPolicy) == nil {
+			return "", nil, fmt.Errorf("balancing policy %q is not registered", params.BalancingPolicy)
+		}
+		opts = append(opts, grpc.WithDefaultServiceConfig(
+			fmt.Sprintf(`{"loadBalancingConfig": [{%q: {}}]}`, params.BalancingPolicy)))
+	}
+
+	isUnix := strings.HasPrefix(host, "/") || strings.HasPrefix(host, abstractPrefix) ||
+		strings.HasPrefix(host, unixScheme+":") || strings.HasPrefix(host, unixAbstractScheme+":")
+	if isUnix && params.Proxy != "" {
+		return "", nil, errors.New("proxy could not be used for unix domain sockets")
+	}
+	if params.Authority != "" {
+		opts = append(opts, grpc.WithAuthority(params.Authority))
+	}
+
+	switch {
+	case strings.HasPrefix(host, "/"):
+		return unixScheme + "://" + host, opts, nil
+	case strings.HasPrefix(host, abstractPrefix):
+		return unixAbstractScheme + ":" + strings.TrimPrefix(host, abstractPrefix), opts, nil
+	case strings.HasPrefix(host, unixScheme+":"), strings.HasPrefix(host, unixAbstractScheme+":"):
+		return host, opts, nil
+	}
+
+	if strings.ContainsAny(host, targetSeparator+weightSeparator) {
+		addrs, err := parseBackends(host)
+		if err != nil {
+			return "", nil, err
+		}
+		r := manual.NewBuilderWithScheme(staticScheme)
+		r.InitialState(resolver.State{Addresses: addrs})
+		opts = append(opts, grpc.WithResolvers(r))
+		if params.Authority == "" {
+			// Authority is used as server name of TLS, so the first backend is used instead of endpoint of target.
+			opts = append(opts, grpc.WithAuthority(addrs[0].Addr))
+		}
+		return staticScheme + ":///backends", opts, nil
+	}
+
+	if params.Proxy != "" {
+		// Name is resolved by proxy, so it is sent in CONNECT as is instead of resolved addresses.
+		return passthroughScheme + ":///" + proxyEndpoint(host), opts, nil
+	}
+	if params.ResolveInterval > 0 && strings.HasPrefix(host, dnsScheme+":") {
+		opts = append(opts, grpc.WithResolvers(&dnsBuilder{interval: params.ResolveInterval}))
+	}
+	return host, opts, nil
+}
+
+// proxyEndpoint return host:port of target for CONNECT, scheme of dns target is removed and default port is added.
+func proxyEndpoint(host string) string {
+	if strings.HasPrefix(host, dnsScheme+":") {
+		host = strings.TrimPrefix(host, dnsScheme+":")
+		if rest, ok := strings.CutPrefix(host, "//"); ok {
+			// Authority of dns server is skipped, e.g. "dns://8.8.8.8/service:50051".
+			_, host, _ = strings.Cut(rest, "/")
+		}
+		if _, _, err := net.SplitHostPort(host); err != nil {
+			host = net.JoinHostPort(host, defaultPort)
+		}
+	}
+	return host
+}
+
+// parseBackends parse list of static backends with weights.
+func parseBackends(host string) ([]resolver.Address, error) {
+	var addrs []resolver.Address
+	for _, backend := range strings.Split(host, targetSeparator) {
+		backend = strings.TrimSpace(backend)
+		if backend == "" {
+			continue
+		}
+
+		weight := 1
+		if addr, w, ok := strings.Cut(backend, weightSeparator); ok {
+			var err error
+			weight, err = strconv.Atoi(strings.TrimSpace(w))
+			if err != nil || weight < 1 || weight > maxWeight {
+				return nil, fmt.Errorf("weight of backend %q must be a number from 1 to %d", backend, maxWeight)
+			}
+			backend = strings.TrimSpace(addr)
+		}
+		addrs = append(addrs, resolver.Address{
+			Addr:               backend,
+			BalancerAttributes: attributes.New(weightKey{}, weight),
+		})
+	}
+	if len(addrs) == 0 {
+		return nil, errors.New("list of backends is empty")
+	}
+
+	return addrs, nil
+}
+
+// weightKey key of backend weight in attributes of address.
+type weightKey struct{}
+
+// weightedPickerBuilder build picker which distributes calls between backends proportionally to their weights.
+type weightedPickerBuilder struct{}
+
+// Build picker for ready backends.
+func (b *weightedPickerBuilder) Build(info base.PickerBuildInfo) balancer.Picker {
+	if len(info.ReadySCs) == 0 {
+		return base.NewErrPicker(balancer.ErrNoSubConnAvailable)
+	}
+
+	type backend struct {
+		sc      balancer.SubConn
+		addr    string
+		weight  int
+		current int
+	}
+	backends := make([]*backend, 0, len(info.ReadySCs))
+	total := 0
+	for sc, sci := range info.ReadySCs {
+		weight, ok := sci.Address.BalancerAttributes.Value(weightKey{}).(int)
+		if !ok || weight < 1 {
+			weight = 1
+		}
+		backends = append(backends, &backend{sc: sc, addr: sci.Address.Addr, weight: weight})
+		total += weight
+	}
+	slices.SortFunc(backends, func(a, b *backend) int {
+		return strings.Compare(a.addr, b.addr)
+	})
+
+	// Smooth weighted round-robin, so backends with big weights do not get calls in bursts.
+	order := make([]balancer.SubConn, 0, total)
+	for range total {
+		best := backends[0]
+		for _, be := range backends {
+			be.current += be.weight
+			if be.current > best.current {
+				best = be
+			}
+		}
+		best.current -= total
+		order = append(order, best.sc)
+	}
+
+	return &weightedPicker{order: order}
+}
+
+// weightedPicker pick backends in precomputed order.
+type weightedPicker struct {
+	order []balancer.SubConn
+	next  atomic.Uint64
+}
+
+// Pick backend for call.
+func (p *weightedPicker) Pick(balancer.PickInfo) (balancer.PickResult, error) {
+	n := p.next.Add(1) - 1
+	return balancer.PickResult{SubConn: p.order[n%uint64(len(p.order))]}, nil
+}
+
+// dnsBuilder build resolver of "dns:///" targets which resolves host periodically.
+type dnsBuilder struct {
+	interval time.Duration
+}
+
+// Build resolver for target.
+func (b *dnsBuilder) Build(target resolver.Target, cc resolver.ClientConn, _ resolver.BuildOptions) (
+	resolver.Resolver, error,
+) {
+	host, port, err := net.SplitHostPort(target.Endpoint())
+	if err != nil {
+		host, port = target.Endpoint(), defaultPort
+	}
+	if host == "" {
+		return nil, fmt.Errorf("dns target %q has no host", target.String())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &dnsResolver{
+		host:     host,
+		port:     port,
+		interval: b.interval,
+		cc:       cc,
+		cancel:   cancel,
+		resolve:  make(chan struct{}, 1),
+	}
+	r.wg.Add(1)
+	go r.watch(ctx)
+	return r, nil
+}
+
+// Scheme of resolver.
+func (b *dnsBuilder) Scheme() string {
+	return dnsScheme
+}
+
+// dnsResolver resolve host periodically and on demand of grpc.
+type dnsResolver struct {
+	host     string
+	port     string
+	interval time.Duration
+	cc       resolver.ClientConn
+	cancel   context.CancelFunc
+	resolve  chan struct{}
+	wg       sync.WaitGroup
+}
+
+// ResolveNow resolve host out of schedule.
+func (r *dnsResolver) ResolveNow(resolver.ResolveNowOptions) {
+	select {
+	case r.resolve <- struct{}{}:
+	default:
+	}
+}
+
+// Close resolver.
+func (r *dnsResolver) Close() {
+	r.cancel()
+	r.wg.Wait()
+}
+
+// watch resolve host until resolver is closed.
+func (r *dnsResolver) watch(ctx context.Context) {
+	defer r.wg.Done()
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		r.lookup(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-r.resolve:
+		}
+	}
+}
+
+// lookup resolve host and update addresses of connection.
+func (r *dnsResolver) lookup(ctx context.Context) {
+	hosts, err := net.DefaultResolver.LookupHost(ctx, r.host)
+	if err != nil {
+		if ctx.Err() == nil {
+			r.cc.ReportError(fmt.Errorf("could not resolve %q: %w", r.host, err))
+		}
+		return
+	}
+
+	slices.Sort(hosts)
+	addrs := make([]resolver.Address, 0, len(hosts))
+	for _, h := range hosts {
+		addrs = append(addrs, resolver.Address{Addr: net.JoinHostPort(h, r.port)})
+	}
+	// Error means that state is rejected by balancer, the host is resolved again by schedule.
+	_ = r.cc.UpdateState(resolver.State{Addresses: addrs})
+}
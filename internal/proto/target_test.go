@@ -0,0 +1,131 @@
+package proto
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/AndreyNiki/grpc-highloader/internal/entity"
+	"github.com/AndreyNiki/grpc-highloader/internal/metrics"
+)
+
+func TestParseBackends(t *testing.T) {
+	tests := []struct {
+		name        string
+		host        string
+		wantAddrs   []string
+		wantWeights []int
+		wantErr     bool
+	}{
+		{
+			name:        "backends with weights",
+			host:        "10.0.0.1:50051=3, 10.0.0.2:50051,",
+			wantAddrs:   []string{"10.0.0.1:50051", "10.0.0.2:50051"},
+			wantWeights: []int{3, 1},
+		},
+		{name: "invalid weight", host: "10.0.0.1:50051=0", wantErr: true},
+		{name: "empty list", host: " , ", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addrs, err := parseBackends(tt.host)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, addrs, len(tt.wantAddrs))
+			for i, addr := range addrs {
+				assert.Equal(t, tt.wantAddrs[i], addr.Addr)
+				assert.Equal(t, tt.wantWeights[i], addr.BalancerAttributes.Value(weightKey{}))
+			}
+		})
+	}
+}
+
+func TestRequester_Balancing(t *testing.T) {
+	first, second := newTestServer(t, nil), newTestServer(t, nil)
+
+	tests := []struct {
+		name         string
+		host         string
+		policy       string
+		wantBackends int
+		wantRequests map[string]int64
+	}{
+		{
+			name:         "pick first",
+			host:         first + "," + second,
+			policy:       entity.BalancingPolicyPickFirst,
+			wantBackends: 1,
+			wantRequests: map[string]int64{first: 8},
+		},
+		{
+			name:         "round robin",
+			host:         first + "," + second,
+			policy:       entity.BalancingPolicyRoundRobin,
+			wantBackends: 2,
+			wantRequests: map[string]int64{first: 4, second: 4},
+		},
+		{
+			name:         "weighted round robin",
+			host:         first + "=3," + second,
+			policy:       entity.BalancingPolicyWeightedRoundRobin,
+			wantBackends: 2,
+			wantRequests: map[string]int64{first: 6, second: 2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := metrics.InitMetrics()
+			req := newTestRequest(t, tt.host)
+			req.Dial.BalancingPolicy = tt.policy
+			r, err := NewRequester(req, m)
+			require.NoError(t, err)
+			defer r.Close()
+
+			// Wait until all backends are ready.
+			require.Eventually(t, func() bool {
+				err := r.SendUnaryRPCRequest(context.Background())
+				return err == nil && len(m.Backends.Snapshot()) == tt.wantBackends
+			}, 5*time.Second, 10*time.Millisecond)
+			m.Reset()
+
+			for range 8 {
+				require.NoError(t, r.SendUnaryRPCRequest(context.Background()))
+			}
+			requests := map[string]int64{}
+			for _, b := range m.Backends.Snapshot() {
+				requests[b.Address] = b.Requests
+				assert.Zero(t, b.Errors)
+			}
+			assert.Equal(t, tt.wantRequests, requests)
+		})
+	}
+
+	t.Run("unknown policy", func(t *testing.T) {
+		req := newTestRequest(t, first)
+		req.Dial.BalancingPolicy = "unknown"
+		_, err := NewRequester(req, metrics.InitMetrics())
+		assert.Error(t, err)
+	})
+}
+
+func TestRequester_DNSResolveInterval(t *testing.T) {
+	_, port, err := net.SplitHostPort(newTestServer(t, nil))
+	require.NoError(t, err)
+
+	req := newTestRequest(t, "dns:///127.0.0.1:"+port)
+	req.Dial.ResolveInterval = 50 * time.Millisecond
+	r, err := NewRequester(req, metrics.InitMetrics())
+	require.NoError(t, err)
+	defer r.Close()
+
+	require.NoError(t, r.SendUnaryRPCRequest(context.Background()))
+	time.Sleep(200 * time.Millisecond)
+	require.NoError(t, r.SendUnaryRPCRequest(context.Background()))
+}
@@ -0,0 +1,65 @@
+package proto
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/AndreyNiki/grpc-highloader/internal/entity"
+)
+
+// newTransportCredentials create credentials for connection, nil params means insecure connection.
+func newTransportCredentials(params *entity.TLSParams) (credentials.TransportCredentials, error) {
+	if params == nil {
+		return insecure.NewCredentials(), nil
+	}
+
+	cfg, err := newTLSConfig(params)
+	if err != nil {
+		return nil, err
+	}
+	return credentials.NewTLS(cfg), nil
+}
+
+// newTLSConfig create TLS config from params, nil params means no TLS.
+func newTLSConfig(params *entity.TLSParams) (*tls.Config, error) {
+	if params == nil {
+		return nil, nil
+	}
+
+	cfg := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         params.ServerName,
+		InsecureSkipVerify: params.InsecureSkipVerify,
+	}
+
+	if params.CAFile != "" {
+		pem, err := os.ReadFile(params.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not read CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA file %q", params.CAFile)
+		}
+		cfg.RootCAs = pool
+	}
+
+	if params.CertFile != "" || params.KeyFile != "" {
+		if params.CertFile == "" || params.KeyFile == "" {
+			return nil, errors.New("both client certificate and key are required for mutual TLS")
+		}
+		cert, err := tls.LoadX509KeyPair(params.CertFile, params.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not load client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,383 @@
+package proto
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/jhump/protoreflect/desc"
+	"github.com/jhump/protoreflect/dynamic"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	"github.com/AndreyNiki/grpc-highloader/internal/entity"
+	"github.com/AndreyNiki/grpc-highloader/internal/metrics"
+	"github.com/AndreyNiki/grpc-highloader/internal/templates"
+)
+
+const (
+	// validationTimeout timeout for dial and dry-run request if request deadline is not set.
+	validationTimeout = 5 * time.Second
+	// wellKnownPackage package of well-known types, they have special json mapping and are checked by jsonpb.
+	wellKnownPackage = "google.protobuf."
+	reservedMDPrefix = "grpc-"
+	binaryMDSuffix   = "-bin"
+)
+
+const (
+	pathMethod   = "method"
+	pathMessage  = "message"
+	pathMetadata = "metadata"
+	pathHost     = "host"
+	pathResponse = "response"
+)
+
+// RequestValidator makes pre-flight validation of request.
+type RequestValidator struct {
+	parser *ProtoParser
+	tb     *templates.TemplateBuilder
+}
+
+// NewRequestValidator create a new RequestValidator.
+func NewRequestValidator(parser *ProtoParser) *RequestValidator {
+	return &RequestValidator{
+		parser: parser,
+		tb:     templates.NewTemplateBuilder(),
+	}
+}
+
+// Validate check method, message and metadata of request and dial the host. If send is true, exactly one request
+// is sent. All found problems are returned, empty result means that request is valid.
+func (v *RequestValidator) Validate(
+	ctx context.Context,
+	req *entity.RequestParams,
+	send bool,
+) []entity.ValidationProblem {
+	problems := v.validateMetadata(req.Metadata)
+
+	methodDesc, err := v.parser.GetMethodDescriptor(req.Proto.FilePath, req.Method, req.Service)
+	if err != nil {
+		return append(problems, entity.ValidationProblem{Path: pathMethod, Message: err.Error()})
+	}
+	if req.Transport == entity.TransportREST && v.parser.makeHTTPRule(methodDesc) == nil {
+		problems = append(problems, entity.ValidationProblem{
+			Path: pathMethod, Message: "method has no google.api.http option for REST transport",
+		})
+	}
+	problems = append(problems, v.validateMessage(methodDesc.GetInputType(), req.Message)...)
+	if len(problems) != 0 {
+		return problems
+	}
+
+	timeout := validationTimeout
+	if req.RequestDeadline != nil {
+		timeout = *req.RequestDeadline
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	// Validation attempts are not captured.
+	params := *req
+	params.Capture = nil
+	r, err := newRequester(&params, metrics.InitMetrics())
+	if err != nil {
+		return []entity.ValidationProblem{{Path: pathHost, Message: err.Error()}}
+	}
+	defer r.Close()
+
+	// HTTP transports have no persistent connection, they are checked by sending only.
+	if grpcRequester, ok := r.(*Requester); ok {
+		err = v.waitForReady(ctx, grpcRequester.clientConn())
+		if err != nil {
+			return []entity.ValidationProblem{{Path: pathHost, Message: err.Error()}}
+		}
+	}
+
+	if send {
+		ctx = metadata.NewOutgoingContext(ctx, metadata.New(req.Metadata))
+		err = r.SendUnaryRPCRequest(ctx)
+		if err != nil {
+			return []entity.ValidationProblem{{Path: pathResponse, Message: err.Error()}}
+		}
+	}
+
+	return nil
+}
+
+// ValidateMessage check that method exists and its message template is valid, it is also used for request cards
+// after proto file is changed.
+func (v *RequestValidator) ValidateMessage(req *entity.RequestParams) []entity.ValidationProblem {
+	methodDesc, err := v.parser.GetMethodDescriptor(req.Proto.FilePath, req.Method, req.Service)
+	if err != nil {
+		return []entity.ValidationProblem{{Path: pathMethod, Message: err.Error()}}
+	}
+
+	return v.validateMessage(methodDesc.GetInputType(), req.Message)
+}
+
+// waitForReady connect and wait until connection is ready.
+func (v *RequestValidator) waitForReady(ctx context.Context, conn *grpc.ClientConn) error {
+	conn.Connect()
+	for {
+		state := conn.GetState()
+		switch state {
+		case connectivity.Ready:
+			return nil
+		case connectivity.TransientFailure, connectivity.Shutdown:
+			return fmt.Errorf("could not connect to %q: connection state %s", conn.Target(), state)
+		}
+
+		if !conn.WaitForStateChange(ctx, state) {
+			return fmt.Errorf("could not connect to %q: %w", conn.Target(), ctx.Err())
+		}
+	}
+}
+
+// validateMetadata check that metadata keys and values could be sent.
+func (v *RequestValidator) validateMetadata(md map[string]string) []entity.ValidationProblem {
+	var problems []entity.ValidationProblem
+	for _, key := range slices.Sorted(maps.Keys(md)) {
+		path := fmt.Sprintf("%s[%s]", pathMetadata, key)
+		k := strings.ToLower(key)
+		switch {
+		case k == "":
+			problems = append(problems, entity.ValidationProblem{Path: path, Message: "key is empty"})
+			continue
+		case strings.HasPrefix(k, reservedMDPrefix):
+			problems = append(problems, entity.ValidationProblem{Path: path, Message: "key prefix is reserved"})
+			continue
+		}
+
+		if i := strings.IndexFunc(k, func(r rune) bool {
+			return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.')
+		}); i != -1 {
+			problems = append(problems, entity.ValidationProblem{
+				Path:    path,
+				Message: fmt.Sprintf("key contains invalid character %q", k[i]),
+			})
+			continue
+		}
+
+		if !strings.HasSuffix(k, binaryMDSuffix) {
+			if i := strings.IndexFunc(md[key], func(r rune) bool {
+				return r < 0x20 || r > 0x7E
+			}); i != -1 {
+				problems = append(problems, entity.ValidationProblem{
+					Path:    path,
+					Message: fmt.Sprintf("value contains non-printable character %q", md[key][i]),
+				})
+			}
+		}
+	}
+
+	return problems
+}
+
+// validateMessage render message template and check it for message descriptor.
+func (v *RequestValidator) validateMessage(md *desc.MessageDescriptor, data string) []entity.ValidationProblem {
+	msg, err := v.tb.Process(data)
+	if err != nil {
+		return []entity.ValidationProblem{{
+			Path:    pathMessage,
+			Message: fmt.Sprintf("processing template message failed: %s", err),
+		}}
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(msg))
+	decoder.UseNumber()
+	var values map[string]any
+	err = decoder.Decode(&values)
+	if err != nil {
+		return []entity.ValidationProblem{{Path: pathMessage, Message: fmt.Sprintf("invalid json: %s", err)}}
+	}
+
+	problems := v.validateFields(md, values, pathMessage)
+	if len(problems) != 0 {
+		return problems
+	}
+
+	// Final check, jsonpb knows special mapping of well-known types.
+	err = jsonpb.UnmarshalString(msg, dynamic.NewMessage(md))
+	if err != nil {
+		return []entity.ValidationProblem{{Path: pathMessage, Message: err.Error()}}
+	}
+
+	return nil
+}
+
+// validateFields check values of message fields.
+//
+// Recursion.
+func (v *RequestValidator) validateFields(
+	md *desc.MessageDescriptor,
+	values map[string]any,
+	path string,
+) []entity.ValidationProblem {
+	var problems []entity.ValidationProblem
+	for _, name := range slices.Sorted(maps.Keys(values)) {
+		fieldPath := path + "." + name
+		fd := v.findField(md, name)
+		if fd == nil {
+			problems = append(problems, entity.ValidationProblem{
+				Path:    fieldPath,
+				Message: fmt.Sprintf("unknown field of message %s", md.GetFullyQualifiedName()),
+			})
+			continue
+		}
+
+		value := values[name]
+		switch {
+		case value == nil:
+			continue
+		case fd.IsMap():
+			obj, ok := value.(map[string]any)
+			if !ok {
+				problems = append(problems, entity.ValidationProblem{Path: fieldPath, Message: "expected object"})
+				continue
+			}
+			for _, key := range slices.Sorted(maps.Keys(obj)) {
+				// Calls itself.
+				problems = append(problems,
+					v.validateValue(fd.GetMapValueType(), obj[key], fmt.Sprintf("%s[%s]", fieldPath, key))...)
+			}
+		case fd.IsRepeated():
+			items, ok := value.([]any)
+			if !ok {
+				problems = append(problems, entity.ValidationProblem{Path: fieldPath, Message: "expected array"})
+				continue
+			}
+			for i, item := range items {
+				// Calls itself.
+				problems = append(problems, v.validateValue(fd, item, fmt.Sprintf("%s[%d]", fieldPath, i))...)
+			}
+		default:
+			// Calls itself.
+			problems = append(problems, v.validateValue(fd, value, fieldPath)...)
+		}
+	}
+
+	return problems
+}
+
+// findField return field by json or proto name.
+func (v *RequestValidator) findField(md *desc.MessageDescriptor, name string) *desc.FieldDescriptor {
+	for _, fd := range md.GetFields() {
+		if fd.GetJSONName() == name || fd.GetName() == name {
+			return fd
+		}
+	}
+
+	return nil
+}
+
+// validateValue check single value of field.
+//
+// Recursion.
+func (v *RequestValidator) validateValue(
+	fd *desc.FieldDescriptor,
+	value any,
+	path string,
+) []entity.ValidationProblem {
+	problem := func(format string, args ...any) []entity.ValidationProblem {
+		return []entity.ValidationProblem{{Path: path, Message: fmt.Sprintf(format, args...)}}
+	}
+
+	switch fd.GetType() {
+	case descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, descriptorpb.FieldDescriptorProto_TYPE_GROUP:
+		msg := fd.GetMessageType()
+		if strings.HasPrefix(msg.GetFullyQualifiedName(), wellKnownPackage) {
+			return nil
+		}
+		obj, ok := value.(map[string]any)
+		if !ok {
+			return problem("expected object of message %s", msg.GetFullyQualifiedName())
+		}
+		// Calls itself.
+		return v.validateFields(msg, obj, path)
+	case descriptorpb.FieldDescriptorProto_TYPE_ENUM:
+		switch val := value.(type) {
+		case string:
+			if fd.GetEnumType().FindValueByName(val) == nil {
+				return problem("unknown value %q of enum %s", val, fd.GetEnumType().GetFullyQualifiedName())
+			}
+		case json.Number:
+			if _, err := strconv.ParseInt(val.String(), 10, 32); err != nil {
+				return problem("invalid enum number %s", val)
+			}
+		default:
+			return problem("expected enum name or number")
+		}
+	case descriptorpb.FieldDescriptorProto_TYPE_BOOL:
+		if _, ok := value.(bool); !ok {
+			return problem("expected bool")
+		}
+	case descriptorpb.FieldDescriptorProto_TYPE_STRING, descriptorpb.FieldDescriptorProto_TYPE_BYTES:
+		if _, ok := value.(string); !ok {
+			return problem("expected string")
+		}
+	case descriptorpb.FieldDescriptorProto_TYPE_FLOAT, descriptorpb.FieldDescriptorProto_TYPE_DOUBLE:
+		num, ok := v.numberString(value)
+		if !ok {
+			return problem("expected number")
+		}
+		f, err := strconv.ParseFloat(num, 64)
+		if err != nil && num != "NaN" && num != "Infinity" && num != "-Infinity" {
+			return problem("invalid number %s", num)
+		}
+		if fd.GetType() == descriptorpb.FieldDescriptorProto_TYPE_FLOAT && math.Abs(f) > math.MaxFloat32 {
+			return problem("number %s is out of range for float", num)
+		}
+	default:
+		num, ok := v.numberString(value)
+		if !ok {
+			return problem("expected integer")
+		}
+		if err := v.checkInteger(fd.GetType(), num); err != nil {
+			return problem("%s", err)
+		}
+	}
+
+	return nil
+}
+
+// numberString return number as string, json mapping allows numbers in strings.
+func (v *RequestValidator) numberString(value any) (string, bool) {
+	switch val := value.(type) {
+	case json.Number:
+		return val.String(), true
+	case string:
+		return val, true
+	default:
+		return "", false
+	}
+}
+
+// checkInteger check that num is integer in range of type.
+func (v *RequestValidator) checkInteger(typ descriptorpb.FieldDescriptorProto_Type, num string) error {
+	var err error
+	switch typ {
+	case descriptorpb.FieldDescriptorProto_TYPE_INT32, descriptorpb.FieldDescriptorProto_TYPE_SINT32,
+		descriptorpb.FieldDescriptorProto_TYPE_SFIXED32:
+		_, err = strconv.ParseInt(num, 10, 32)
+	case descriptorpb.FieldDescriptorProto_TYPE_UINT32, descriptorpb.FieldDescriptorProto_TYPE_FIXED32:
+		_, err = strconv.ParseUint(num, 10, 32)
+	case descriptorpb.FieldDescriptorProto_TYPE_UINT64, descriptorpb.FieldDescriptorProto_TYPE_FIXED64:
+		_, err = strconv.ParseUint(num, 10, 64)
+	default:
+		_, err = strconv.ParseInt(num, 10, 64)
+	}
+	if err != nil {
+		return fmt.Errorf("invalid integer %s for %s", num, strings.ToLower(strings.TrimPrefix(typ.String(), "TYPE_")))
+	}
+
+	return nil
+}
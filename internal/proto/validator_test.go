@@ -0,0 +1,96 @@
+package proto
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/AndreyNiki/grpc-highloader/internal/entity"
+)
+
+func TestRequestValidator_Validate(t *testing.T) {
+	p := NewProtoParser()
+	parsed, err := p.ParseProto("testdata/example.proto")
+	require.NoError(t, err)
+	v := NewRequestValidator(p)
+
+	t.Run("Test problems with field paths", func(t *testing.T) {
+		req := &entity.RequestParams{
+			Service: "example.v1.UserService",
+			Method:  "Get",
+			Message: `{"id": 1, "page": {"size": "big", "unknown": true}}`,
+			Metadata: map[string]string{
+				"grpc-timeout": "1s",
+				"bad key":      "value",
+				"x-request-id": "{{randNum 1 5}}",
+			},
+			Proto: parsed,
+		}
+		problems := v.Validate(context.Background(), req, false)
+		assert.Equal(t, []entity.ValidationProblem{
+			{Path: "metadata[bad key]", Message: "key contains invalid character ' '"},
+			{Path: "metadata[grpc-timeout]", Message: "key prefix is reserved"},
+			{Path: "message.id", Message: "expected string"},
+			{Path: "message.page.size", Message: "invalid integer big for int32"},
+			{Path: "message.page.unknown", Message: "unknown field of message example.common.Page"},
+		}, problems)
+	})
+
+	t.Run("Test unknown method", func(t *testing.T) {
+		req := &entity.RequestParams{
+			Service: "example.v1.UserService",
+			Method:  "Unknown",
+			Message: `{}`,
+			Proto:   parsed,
+		}
+		problems := v.Validate(context.Background(), req, false)
+		require.Len(t, problems, 1)
+		assert.Equal(t, "method", problems[0].Path)
+	})
+
+	t.Run("Test message only", func(t *testing.T) {
+		req := &entity.RequestParams{
+			Service:  "example.v1.UserService",
+			Method:   "Get",
+			Message:  `{"id": "1", "unknown": true}`,
+			Metadata: map[string]string{"grpc-timeout": "1s"},
+			Proto:    parsed,
+		}
+		assert.Equal(t, []entity.ValidationProblem{
+			{Path: "message.unknown", Message: "unknown field of message example.v1.GetRequest"},
+		}, v.ValidateMessage(req))
+	})
+
+	t.Run("Test dry-run request", func(t *testing.T) {
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		srv := grpc.NewServer(grpc.UnknownServiceHandler(func(_ any, stream grpc.ServerStream) error {
+			if err := stream.RecvMsg(&emptypb.Empty{}); err != nil {
+				return err
+			}
+			return stream.SendMsg(&emptypb.Empty{})
+		}))
+		go srv.Serve(lis)
+		defer srv.Stop()
+
+		capturePath := filepath.Join(t.TempDir(), "capture.jsonl")
+		req := &entity.RequestParams{
+			Host:    lis.Addr().String(),
+			Service: "example.v1.UserService",
+			Method:  "Get",
+			Message: `{"id": "{{randNum 1 5}}", "page": {"size": 10}}`,
+			Proto:   parsed,
+			Capture: &entity.CaptureParams{Path: capturePath, SampleRate: 1},
+		}
+		problems := v.Validate(context.Background(), req, true)
+		assert.Empty(t, problems)
+		assert.NoFileExists(t, capturePath, "validation attempts are not captured")
+		assert.NotNil(t, req.Capture)
+	})
+}
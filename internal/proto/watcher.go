@@ -0,0 +1,118 @@
+package proto
+
+import (
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/AndreyNiki/grpc-highloader/internal/entity"
+)
+
+// reloadDelay delay before parsing after last change, editors usually make several writes on save.
+const reloadDelay = 200 * time.Millisecond
+
+// ProtoWatcher watches proto files and parses them again after changes.
+type ProtoWatcher struct {
+	parser *ProtoParser
+}
+
+// NewProtoWatcher create a new ProtoWatcher.
+func NewProtoWatcher(parser *ProtoParser) *ProtoWatcher {
+	return &ProtoWatcher{
+		parser: parser,
+	}
+}
+
+// Watch start watching proto file and all its imports. The fn is called with parsed proto after every change
+// or with error if proto could not be parsed. Returned func stops watching.
+func (w *ProtoWatcher) Watch(
+	parsedProto *entity.ParsedProto,
+	fn func(parsedProto *entity.ParsedProto, err error),
+) (func(), error) {
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := w.watchFiles(fsw, parsedProto.Files)
+	if err != nil {
+		fsw.Close()
+		return nil, err
+	}
+
+	done := make(chan struct{})
+	go w.run(fsw, files, parsedProto.FilePath, fn, done)
+
+	var once sync.Once
+	stop := func() {
+		once.Do(func() {
+			close(done)
+			fsw.Close()
+		})
+	}
+	return stop, nil
+}
+
+// run handle events from fsnotify.Watcher until done is closed.
+func (w *ProtoWatcher) run(
+	fsw *fsnotify.Watcher,
+	files map[string]struct{},
+	fp string,
+	fn func(parsedProto *entity.ParsedProto, err error),
+	done chan struct{},
+) {
+	var reload <-chan time.Time
+	for {
+		select {
+		case <-done:
+			return
+		case event, ok := <-fsw.Events:
+			if !ok {
+				return
+			}
+			if _, ok := files[filepath.Clean(event.Name)]; !ok || event.Op == fsnotify.Chmod {
+				continue
+			}
+			reload = time.After(reloadDelay)
+		case err, ok := <-fsw.Errors:
+			if !ok {
+				return
+			}
+			fn(nil, err)
+		case <-reload:
+			reload = nil
+			parsedProto, err := w.parser.ParseProto(fp)
+			if err != nil {
+				fn(nil, err)
+				continue
+			}
+
+			// Imports could be changed.
+			newFiles, err := w.watchFiles(fsw, parsedProto.Files)
+			if err != nil {
+				fn(nil, err)
+				continue
+			}
+			files = newFiles
+			fn(parsedProto, nil)
+		}
+	}
+}
+
+// watchFiles add directories of files to watcher and return set of files.
+//
+// Directories are watched instead of files, because editors often save file by replacing it.
+func (w *ProtoWatcher) watchFiles(fsw *fsnotify.Watcher, fps []string) (map[string]struct{}, error) {
+	files := make(map[string]struct{}, len(fps))
+	for _, fp := range fps {
+		err := fsw.Add(filepath.Dir(fp))
+		if err != nil {
+			return nil, err
+		}
+		files[filepath.Clean(fp)] = struct{}{}
+	}
+
+	return files, nil
+}
@@ -0,0 +1,52 @@
+package proto
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/AndreyNiki/grpc-highloader/internal/entity"
+)
+
+func TestProtoWatcher_Watch(t *testing.T) {
+	dir := t.TempDir()
+	fp := filepath.Join(dir, "watch.proto")
+	writeProto := func(method string) {
+		content := `syntax = "proto3";
+package watch;
+message Req {}
+service Svc { rpc ` + method + `(Req) returns (Req); }
+`
+		require.NoError(t, os.WriteFile(fp, []byte(content), 0644))
+	}
+	writeProto("First")
+
+	p := NewProtoParser()
+	parsed, err := p.ParseProto(fp)
+	require.NoError(t, err)
+	require.Len(t, parsed.Files, 1)
+
+	ch := make(chan *entity.ParsedProto, 1)
+	w := NewProtoWatcher(p)
+	stop, err := w.Watch(parsed, func(parsedProto *entity.ParsedProto, err error) {
+		if err == nil {
+			ch <- parsedProto
+		}
+	})
+	require.NoError(t, err)
+	defer stop()
+
+	writeProto("Second")
+
+	select {
+	case reloaded := <-ch:
+		_, ok := reloaded.FindMethodByName("watch.Svc", "Second")
+		assert.True(t, ok)
+	case <-time.After(5 * time.Second):
+		t.Fatal("proto was not reloaded")
+	}
+}
@@ -0,0 +1,728 @@
+package proto
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/jhump/protoreflect/desc"
+	"github.com/jhump/protoreflect/dynamic"
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/AndreyNiki/grpc-highloader/internal/capture"
+	"github.com/AndreyNiki/grpc-highloader/internal/entity"
+	"github.com/AndreyNiki/grpc-highloader/internal/metrics"
+	"github.com/AndreyNiki/grpc-highloader/internal/templates"
+)
+
+const (
+	grpcWebContentType      = "application/grpc-web+proto"
+	grpcWebTextContentType  = "application/grpc-web-text+proto"
+	connectJSONContentType  = "application/json"
+	connectProtoContentType = "application/proto"
+	connectProtocolVersion  = "1"
+	// frameHeaderLen length of gRPC-Web frame header: flags and length of payload.
+	frameHeaderLen = 5
+	// compressedFrameFlag and trailerFrameFlag flags of gRPC-Web frames.
+	compressedFrameFlag = 0x01
+	trailerFrameFlag    = 0x80
+	// defaultMaxRecvMsgSize max size of response message if it is not set, the same as default of grpc.
+	defaultMaxRecvMsgSize = 4 << 20
+	// maxTrailersLen max length of response besides message.
+	maxTrailersLen = 64 << 10
+	// maxIdleConnsPerHost idle connections kept for reuse between requests.
+	maxIdleConnsPerHost = 256
+	binaryHeaderSuffix  = "-bin"
+	// gzipEncoding the only accepted content encoding of responses.
+	gzipEncoding = "gzip"
+	// statusDetailsHeader header with binary google.rpc.Status with details.
+	statusDetailsHeader = "Grpc-Status-Details-Bin"
+	// typeURLPrefix prefix of type URLs of details.
+	typeURLPrefix = "type.googleapis.com/"
+	// connectTrailerPrefix prefix of headers with trailers of unary Connect responses.
+	connectTrailerPrefix = "Trailer-"
+)
+
+// errUnsupportedMethodType error for methods which could not be sent over HTTP.
+var errUnsupportedMethodType = errors.New("only unary methods are supported by HTTP transports")
+
+// connectCodes codes of Connect protocol errors.
+var connectCodes = map[string]codes.Code{
+	"canceled":            codes.Canceled,
+	"unknown":             codes.Unknown,
+	"invalid_argument":    codes.InvalidArgument,
+	"deadline_exceeded":   codes.DeadlineExceeded,
+	"not_found":           codes.NotFound,
+	"already_exists":      codes.AlreadyExists,
+	"permission_denied":   codes.PermissionDenied,
+	"resource_exhausted":  codes.ResourceExhausted,
+	"failed_precondition": codes.FailedPrecondition,
+	"aborted":             codes.Aborted,
+	"out_of_range":        codes.OutOfRange,
+	"unimplemented":       codes.Unimplemented,
+	"internal":            codes.Internal,
+	"unavailable":         codes.Unavailable,
+	"data_loss":           codes.DataLoss,
+	"unauthenticated":     codes.Unauthenticated,
+}
+
+// HTTPRequester send dynamic requests by gRPC-Web or Connect protocols over HTTP/1.1,
+// or as REST requests transcoded by google.api.http option of method.
+//
+// Dial options of HTTP/2 and compression are not used by these transports.
+type HTTPRequester struct {
+	methodDesc *desc.MethodDescriptor
+	rule       *entity.HTTPRule
+	client     *http.Client
+	url        *url.URL
+	auth       credentials.PerRPCCredentials
+	metrics    *metrics.Metrics
+	assertions *assertions
+	capture    *capture.Sink
+	req        *entity.RequestParams
+	parser     *ProtoParser
+	tb         *templates.TemplateBuilder
+}
+
+// NewHTTPRequester create a new HTTPRequester.
+func NewHTTPRequester(req *entity.RequestParams, metrics *metrics.Metrics) (*HTTPRequester, error) {
+	r := &HTTPRequester{
+		metrics: metrics,
+		req:     req,
+		parser:  NewProtoParser(),
+		tb:      templates.NewTemplateBuilder(),
+	}
+
+	methodDesc, err := r.parser.GetMethodDescriptor(req.Proto.FilePath, req.Method, req.Service)
+	if err != nil {
+		return nil, err
+	}
+	if methodDesc.IsClientStreaming() || methodDesc.IsServerStreaming() {
+		return nil, errUnsupportedMethodType
+	}
+	r.methodDesc = methodDesc
+	r.assertions, err = newAssertions(req.Assertions, methodDesc.GetOutputType())
+	if err != nil {
+		return nil, err
+	}
+
+	r.url, err = newBaseURL(req.Host, req.TLS != nil)
+	if err != nil {
+		return nil, err
+	}
+	if req.Transport == entity.TransportREST {
+		r.rule = r.parser.makeHTTPRule(methodDesc)
+		if r.rule == nil {
+			return nil, fmt.Errorf("method %q has no google.api.http option", methodDesc.GetFullyQualifiedName())
+		}
+	} else {
+		r.url = r.url.JoinPath(req.Service, req.Method)
+	}
+
+	r.client, err = newHTTPClient(req)
+	if err != nil {
+		return nil, err
+	}
+	r.auth, err = newPerRPCCredentials(req.Auth)
+	if err != nil {
+		return nil, err
+	}
+	r.capture, err = capture.NewSink(req.Capture)
+	if err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// SendUnaryRPCRequest send one unary request, every request is counted with its outcome.
+func (r *HTTPRequester) SendUnaryRPCRequest(ctx context.Context) (err error) {
+	r.metrics.IncrementRequestCount()
+	msg := dynamic.NewMessage(r.methodDesc.GetInputType())
+	resp := &response{}
+	defer func() {
+		r.metrics.ObserveOutcome(outcomeOf(ctx, err))
+		captureAttempt(ctx, r.capture, r.methodDesc, msg, resp, err)
+	}()
+
+	err = buildMessage(r.tb, msg, r.req.Message)
+	if err != nil {
+		return newOutcomeError(metrics.OutcomeMarshal, err)
+	}
+
+	start := time.Now()
+	switch r.req.Transport {
+	case entity.TransportGRPCWeb, entity.TransportGRPCWebText:
+		err = r.invokeGRPCWeb(ctx, msg, r.req.Transport == entity.TransportGRPCWebText, resp)
+	case entity.TransportConnectJSON, entity.TransportConnectProto:
+		err = r.invokeConnect(ctx, msg, r.req.Transport == entity.TransportConnectJSON, resp)
+	case entity.TransportREST:
+		err = r.invokeREST(ctx, msg, resp)
+	default:
+		err = fmt.Errorf("transport %d is not supported over HTTP", r.req.Transport)
+	}
+	resp.latency = time.Since(start)
+	// Latency and backend are recorded with outcome of assertions, so failed assertions are counted as errors.
+	err = r.assertions.verify(ctx, r.metrics, resp, err)
+	r.metrics.ObserveLatency(status.Code(err), resp.latency)
+	r.metrics.ObserveBackend(r.url.Host, resp.latency, err != nil)
+
+	return err
+}
+
+// Close requester.
+func (r *HTTPRequester) Close() {
+	r.client.CloseIdleConnections()
+	r.capture.Close()
+}
+
+// invokeGRPCWeb send message in gRPC-Web format and fill response, text format is base64 encoded binary format.
+func (r *HTTPRequester) invokeGRPCWeb(ctx context.Context, msg *dynamic.Message, text bool, out *response) error {
+	payload, err := msg.Marshal()
+	if err != nil {
+		return newOutcomeError(metrics.OutcomeMarshal, err)
+	}
+	if err := r.checkSendSize(payload); err != nil {
+		return err
+	}
+
+	body := make([]byte, frameHeaderLen, frameHeaderLen+len(payload))
+	binary.BigEndian.PutUint32(body[1:], uint32(len(payload)))
+	body = append(body, payload...)
+	contentType := grpcWebContentType
+	if text {
+		body = []byte(base64.StdEncoding.EncodeToString(body))
+		contentType = grpcWebTextContentType
+	}
+
+	header := http.Header{}
+	header.Set("Content-Type", contentType)
+	header.Set("Accept", contentType)
+	header.Set("X-Grpc-Web", "1")
+	if deadline, ok := ctx.Deadline(); ok {
+		header.Set("Grpc-Timeout", encodeGRPCTimeout(time.Until(deadline)))
+	}
+
+	resp, err := r.do(ctx, http.MethodPost, r.url.String(), header, body)
+	if err != nil {
+		return err
+	}
+	r.metrics.ObserveSent(len(payload), len(body))
+	out.header, _ = newResponseMetadata(resp.Header, "", "")
+	if resp.StatusCode != http.StatusOK {
+		return status.Errorf(httpStatusToCode(resp.StatusCode), "unexpected HTTP status %s", resp.Status)
+	}
+	respBody := resp.body
+	if text {
+		respBody, err = decodeGRPCWebText(respBody)
+		if err != nil {
+			return status.Errorf(codes.Internal, "could not decode gRPC-Web text response: %v", err)
+		}
+	}
+
+	payload, err = r.parseGRPCWebFrames(resp.Header, respBody, out)
+	if err != nil {
+		return err
+	}
+	r.metrics.ObserveReceived(len(payload), resp.wireLen)
+	m := dynamic.NewMessage(r.methodDesc.GetOutputType())
+	if err := m.Unmarshal(payload); err != nil {
+		return status.Errorf(codes.Internal, "could not unmarshal response: %v", err)
+	}
+	out.message = m
+
+	return nil
+}
+
+// parseGRPCWebFrames return message from frames of response and set trailers of response, status from trailers
+// is returned as error. Trailers-only responses have status and trailers in headers.
+func (r *HTTPRequester) parseGRPCWebFrames(header http.Header, body []byte, out *response) ([]byte, error) {
+	var message []byte
+	hasMessage := false
+	trailer := http.Header{}
+	for len(body) > 0 {
+		if len(body) < frameHeaderLen {
+			return nil, status.Error(codes.Internal, "malformed gRPC-Web frame")
+		}
+		flags, n := body[0], binary.BigEndian.Uint32(body[1:frameHeaderLen])
+		if uint64(len(body)-frameHeaderLen) < uint64(n) {
+			return nil, status.Error(codes.Internal, "malformed gRPC-Web frame")
+		}
+		payload := body[frameHeaderLen : frameHeaderLen+int(n)]
+		body = body[frameHeaderLen+int(n):]
+
+		switch {
+		case flags&trailerFrameFlag != 0:
+			t, err := textproto.NewReader(bufio.NewReader(io.MultiReader(
+				bytes.NewReader(payload), strings.NewReader("\r\n\r\n")))).ReadMIMEHeader()
+			if err != nil {
+				return nil, status.Errorf(codes.Internal, "malformed gRPC-Web trailers: %v", err)
+			}
+			for k, v := range t {
+				trailer[k] = v
+			}
+		case flags&compressedFrameFlag != 0:
+			return nil, status.Error(codes.Internal, "compressed gRPC-Web messages are not supported")
+		default:
+			if err := r.checkRecvSize(payload); err != nil {
+				return nil, err
+			}
+			message, hasMessage = payload, true
+		}
+	}
+
+	code, msg, details := trailer.Get("Grpc-Status"), trailer.Get("Grpc-Message"), trailer.Get(statusDetailsHeader)
+	out.trailer, _ = newResponseMetadata(trailer, "", "")
+	if code == "" {
+		code, msg, details = header.Get("Grpc-Status"), header.Get("Grpc-Message"), header.Get(statusDetailsHeader)
+		out.trailer = out.header
+	}
+	if code == "" {
+		return nil, status.Error(codes.Internal, "response has no grpc-status")
+	}
+	c, err := strconv.ParseUint(code, 10, 32)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "invalid grpc-status %q", code)
+	}
+	if codes.Code(c) != codes.OK {
+		if decoded, err := url.PathUnescape(msg); err == nil {
+			msg = decoded
+		}
+		return nil, statusWithDetails(codes.Code(c), msg, details)
+	}
+	if !hasMessage {
+		return nil, status.Error(codes.Internal, "response has no message")
+	}
+
+	return message, nil
+}
+
+// invokeConnect send message by unary Connect protocol in JSON or binary format and fill response.
+func (r *HTTPRequester) invokeConnect(ctx context.Context, msg *dynamic.Message, asJSON bool, out *response) error {
+	var body []byte
+	var err error
+	contentType := connectProtoContentType
+	if asJSON {
+		body, err = msg.MarshalJSONPB(&jsonpb.Marshaler{})
+		contentType = connectJSONContentType
+	} else {
+		body, err = msg.Marshal()
+	}
+	if err != nil {
+		return newOutcomeError(metrics.OutcomeMarshal, err)
+	}
+	if err := r.checkSendSize(body); err != nil {
+		return err
+	}
+
+	header := http.Header{}
+	header.Set("Content-Type", contentType)
+	header.Set("Connect-Protocol-Version", connectProtocolVersion)
+	if deadline, ok := ctx.Deadline(); ok {
+		header.Set("Connect-Timeout-Ms", strconv.FormatInt(max(time.Until(deadline).Milliseconds(), 1), 10))
+	}
+
+	resp, err := r.do(ctx, http.MethodPost, r.url.String(), header, body)
+	if err != nil {
+		return err
+	}
+	r.metrics.ObserveSent(len(body), len(body))
+	respBody := resp.body
+	out.header, out.trailer = newResponseMetadata(resp.Header, "", connectTrailerPrefix)
+	if resp.StatusCode != http.StatusOK {
+		return connectError(resp.StatusCode, respBody)
+	}
+	if err := r.checkRecvSize(respBody); err != nil {
+		return err
+	}
+	r.metrics.ObserveReceived(len(respBody), resp.wireLen)
+
+	m := dynamic.NewMessage(r.methodDesc.GetOutputType())
+	if asJSON {
+		err = m.UnmarshalJSONPB(&jsonpb.Unmarshaler{AllowUnknownFields: true}, respBody)
+	} else {
+		err = m.Unmarshal(respBody)
+	}
+	if err != nil {
+		return status.Errorf(codes.Internal, "could not unmarshal response: %v", err)
+	}
+	out.message = m
+
+	return nil
+}
+
+// httpResponse response with read body.
+type httpResponse struct {
+	*http.Response
+	// body decompressed body of response.
+	body []byte
+	// wireLen length of body on wire before decompression.
+	wireLen int
+}
+
+// do send request with metadata from context and read response body. Errors of transport are returned as status.
+// Request has no body if body is nil. Gzip responses are decompressed here instead of transport,
+// so size of compressed body is known.
+func (r *HTTPRequester) do(
+	ctx context.Context,
+	method, u string,
+	header http.Header,
+	body []byte,
+) (*httpResponse, error) {
+	var bodyReader io.Reader
+	if body != nil {
+		bodyReader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, bodyReader)
+	if err != nil {
+		return nil, err
+	}
+
+	md, _ := metadata.FromOutgoingContext(ctx)
+	for k, values := range md {
+		if r.req.Transport == entity.TransportREST {
+			k = restMetadataHeader(k)
+		}
+		for _, v := range values {
+			if strings.HasSuffix(k, binaryHeaderSuffix) {
+				v = base64.RawStdEncoding.EncodeToString([]byte(v))
+			}
+			req.Header.Add(k, v)
+		}
+	}
+	if r.auth != nil {
+		authMD, err := r.auth.GetRequestMetadata(ctx, u)
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+		for k, v := range authMD {
+			req.Header.Set(k, v)
+		}
+	}
+	if r.req.Dial.UserAgent != "" {
+		req.Header.Set("User-Agent", r.req.Dial.UserAgent)
+	}
+	if r.req.Dial.Authority != "" {
+		req.Host = r.req.Dial.Authority
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Accept-Encoding", gzipEncoding)
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, transportError(ctx, err)
+	}
+	defer resp.Body.Close()
+
+	maxLen := int64(r.maxRecvMsgSize())*2 + maxTrailersLen
+	respBody, err := readBody(resp.Body, maxLen)
+	if _, ok := status.FromError(err); !ok {
+		return nil, transportError(ctx, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	wireLen := len(respBody)
+	if strings.EqualFold(resp.Header.Get("Content-Encoding"), gzipEncoding) {
+		zr, err := gzip.NewReader(bytes.NewReader(respBody))
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "could not decompress response: %v", err)
+		}
+		respBody, err = readBody(zr, maxLen)
+		if _, ok := status.FromError(err); !ok {
+			return nil, status.Errorf(codes.Internal, "could not decompress response: %v", err)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &httpResponse{Response: resp, body: respBody, wireLen: wireLen}, nil
+}
+
+// readBody read body which is not larger than max length.
+func readBody(r io.Reader, maxLen int64) ([]byte, error) {
+	body, err := io.ReadAll(io.LimitReader(r, maxLen+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(body)) > maxLen {
+		return nil, status.Errorf(codes.ResourceExhausted, "response is larger than %d bytes", maxLen)
+	}
+
+	return body, nil
+}
+
+// checkSendSize check size of request message.
+func (r *HTTPRequester) checkSendSize(payload []byte) error {
+	if r.req.Dial.MaxSendMsgSize > 0 && len(payload) > r.req.Dial.MaxSendMsgSize {
+		return status.Errorf(codes.ResourceExhausted, "message larger than max (%d vs. %d)",
+			len(payload), r.req.Dial.MaxSendMsgSize)
+	}
+
+	return nil
+}
+
+// checkRecvSize check size of response message.
+func (r *HTTPRequester) checkRecvSize(payload []byte) error {
+	if len(payload) > r.maxRecvMsgSize() {
+		return status.Errorf(codes.ResourceExhausted, "received message larger than max (%d vs. %d)",
+			len(payload), r.maxRecvMsgSize())
+	}
+
+	return nil
+}
+
+// maxRecvMsgSize return max size of response message.
+func (r *HTTPRequester) maxRecvMsgSize() int {
+	if r.req.Dial.MaxRecvMsgSize > 0 {
+		return r.req.Dial.MaxRecvMsgSize
+	}
+
+	return defaultMaxRecvMsgSize
+}
+
+// newBaseURL make URL from host, scheme is added if host has no scheme.
+func newBaseURL(host string, secure bool) (*url.URL, error) {
+	if !strings.Contains(host, "://") {
+		scheme := "http"
+		if secure {
+			scheme = "https"
+		}
+		host = scheme + "://" + host
+	}
+
+	u, err := url.Parse(host)
+	if err != nil {
+		return nil, fmt.Errorf("invalid host %q: %w", host, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("scheme %q is not supported over HTTP", u.Scheme)
+	}
+
+	return u, nil
+}
+
+// newHTTPClient create HTTP client with TLS and dial params of request.
+func newHTTPClient(req *entity.RequestParams) (*http.Client, error) {
+	tlsConfig, err := newTLSConfig(req.TLS)
+	if err != nil {
+		return nil, err
+	}
+
+	dialer := &net.Dialer{
+		Timeout:   req.Dial.ConnectTimeout,
+		KeepAlive: req.Dial.KeepaliveTime,
+	}
+	dial := func(ctx context.Context, addr string) (net.Conn, error) {
+		return dialer.DialContext(ctx, "tcp", addr)
+	}
+	if req.Dial.Dialer != nil {
+		dial = req.Dial.Dialer
+	}
+
+	// Explicit proxy tunnels all connections by CONNECT like for grpc, so plain HTTP requests are not forwarded.
+	proxy := http.ProxyFromEnvironment
+	if req.Dial.Proxy != "" {
+		u, err := parseProxyURL(req.Dial.Proxy)
+		if err != nil {
+			return nil, err
+		}
+		dial = newProxyDialer(u, dial, req.Dial.UserAgent)
+		proxy = nil
+	}
+
+	return &http.Client{
+		Transport: &http.Transport{
+			Proxy: proxy,
+			DialContext: func(ctx context.Context, _, addr string) (net.Conn, error) {
+				return dial(ctx, addr)
+			},
+			TLSClientConfig:     tlsConfig,
+			MaxIdleConnsPerHost: maxIdleConnsPerHost,
+		},
+	}, nil
+}
+
+// connectError make status from error of Connect protocol, HTTP status is used if body has no code.
+func connectError(httpStatus int, body []byte) error {
+	var e struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+		Details []struct {
+			Type  string `json:"type"`
+			Value string `json:"value"`
+		} `json:"details"`
+	}
+	if err := json.Unmarshal(body, &e); err == nil {
+		if code, ok := connectCodes[e.Code]; ok {
+			st := &spb.Status{Code: int32(code), Message: e.Message}
+			for _, d := range e.Details {
+				value, err := decodeBase64(d.Value)
+				if err != nil {
+					continue
+				}
+				st.Details = append(st.Details, &anypb.Any{TypeUrl: typeURLPrefix + d.Type, Value: value})
+			}
+			return status.ErrorProto(st)
+		}
+	}
+
+	return status.Errorf(httpStatusToCode(httpStatus), "unexpected HTTP status %d %s",
+		httpStatus, http.StatusText(httpStatus))
+}
+
+// statusWithDetails make status with details from binary google.rpc.Status of header,
+// details are skipped if header is invalid or has another code.
+func statusWithDetails(code codes.Code, msg, header string) error {
+	st := &spb.Status{Code: int32(code), Message: msg}
+	if header == "" {
+		return status.ErrorProto(st)
+	}
+	withDetails := &spb.Status{}
+	if b, err := decodeBase64(header); err == nil {
+		if err := proto.Unmarshal(b, withDetails); err == nil && withDetails.GetCode() == st.GetCode() {
+			st.Details = withDetails.GetDetails()
+		}
+	}
+
+	return status.ErrorProto(st)
+}
+
+// newResponseMetadata make metadata of response from HTTP headers, prefix of metadata is trimmed from keys and
+// headers with trailer prefix are returned as trailers. Empty prefixes are not used, values of binary keys are decoded.
+// Headers of HTTP and gRPC protocols are not metadata, so they are skipped.
+func newResponseMetadata(h http.Header, metadataPrefix, trailerPrefix string) (header, trailer metadata.MD) {
+	header, trailer = metadata.MD{}, metadata.MD{}
+	for k, values := range h {
+		if transportHeaders[k] {
+			continue
+		}
+		md := header
+		if trailerPrefix != "" && strings.HasPrefix(k, trailerPrefix) {
+			k, md = strings.TrimPrefix(k, trailerPrefix), trailer
+		} else if metadataPrefix != "" {
+			k = strings.TrimPrefix(k, metadataPrefix)
+		}
+		k = strings.ToLower(k)
+		for _, v := range values {
+			if strings.HasSuffix(k, binaryHeaderSuffix) {
+				if b, err := decodeBase64(v); err == nil {
+					v = string(b)
+				}
+			}
+			md.Append(k, v)
+		}
+	}
+
+	return header, trailer
+}
+
+// transportHeaders canonical keys of headers and trailers of HTTP and gRPC protocols.
+var transportHeaders = map[string]bool{
+	"Content-Type":      true,
+	"Content-Length":    true,
+	"Content-Encoding":  true,
+	"Transfer-Encoding": true,
+	"Connection":        true,
+	"Keep-Alive":        true,
+	"Date":              true,
+	"Trailer":           true,
+	"Grpc-Status":       true,
+	"Grpc-Message":      true,
+	"Grpc-Encoding":     true,
+	statusDetailsHeader: true,
+}
+
+// decodeBase64 decode base64 value with or without padding.
+func decodeBase64(s string) ([]byte, error) {
+	return base64.RawStdEncoding.DecodeString(strings.TrimRight(s, "="))
+}
+
+// httpStatusToCode map HTTP status to code for responses without status of gRPC.
+func httpStatusToCode(httpStatus int) codes.Code {
+	switch httpStatus {
+	case http.StatusBadRequest:
+		return codes.Internal
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.Unimplemented
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return codes.Unavailable
+	default:
+		return codes.Unknown
+	}
+}
+
+// encodeGRPCTimeout encode timeout for grpc-timeout header, value has at most 8 digits.
+func encodeGRPCTimeout(d time.Duration) string {
+	const maxValue = 99999999
+	if ms := d.Milliseconds(); ms <= maxValue {
+		return strconv.FormatInt(max(ms, 1), 10) + "m"
+	}
+
+	return strconv.FormatInt(min(int64(d.Seconds()), maxValue), 10) + "S"
+}
+
+// decodeGRPCWebText decode base64 body of gRPC-Web text response. Every chunk of response is padded separately.
+func decodeGRPCWebText(data []byte) ([]byte, error) {
+	data = bytes.TrimSpace(data)
+	var out []byte
+	for len(data) > 0 {
+		end := len(data)
+		if i := bytes.IndexByte(data, '='); i != -1 {
+			end = i
+			for end < len(data) && data[end] == '=' {
+				end++
+			}
+		}
+
+		enc := base64.StdEncoding
+		if end%4 != 0 {
+			enc = base64.RawStdEncoding
+		}
+		chunk, err := enc.DecodeString(string(data[:end]))
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, chunk...)
+		data = data[end:]
+	}
+
+	return out, nil
+}
+
+// transportError make status from error of HTTP transport, errors of context are returned as their status.
+func transportError(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return status.FromContextError(ctx.Err()).Err()
+	}
+
+	return newOutcomeError(metrics.OutcomeTransport, status.Error(codes.Unavailable, err.Error()))
+}
@@ -0,0 +1,242 @@
+package proto
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/jhump/protoreflect/dynamic"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/AndreyNiki/grpc-highloader/internal/entity"
+	"github.com/AndreyNiki/grpc-highloader/internal/metrics"
+)
+
+// newTestWebServer start HTTP server which handles example.v1.UserService/Get by gRPC-Web and Connect protocols.
+// Users with id "404" are not found.
+func newTestWebServer(t *testing.T) string {
+	t.Helper()
+	methodDesc, err := NewProtoParser().GetMethodDescriptor("testdata/example.proto", "Get", "example.v1.UserService")
+	require.NoError(t, err)
+
+	info := &errdetails.ErrorInfo{Reason: "USER_NOT_FOUND", Domain: "users.example.com"}
+	errorInfo, err := proto.Marshal(info)
+	require.NoError(t, err)
+	st, err := status.New(codes.NotFound, "user not found").WithDetails(info)
+	require.NoError(t, err)
+	notFoundDetails, err := proto.Marshal(st.Proto())
+	require.NoError(t, err)
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/example.v1.UserService/Get" || r.Header.Get("X-Test") != "value" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		req := dynamic.NewMessage(methodDesc.GetInputType())
+		resp := dynamic.NewMessage(methodDesc.GetOutputType())
+		contentType := r.Header.Get("Content-Type")
+
+		switch contentType {
+		case grpcWebContentType, grpcWebTextContentType:
+			text := contentType == grpcWebTextContentType
+			if text {
+				body, _ = base64.StdEncoding.DecodeString(string(body))
+			}
+			require.NoError(t, req.Unmarshal(body[frameHeaderLen:]))
+			w.Header().Set("Content-Type", contentType)
+			if req.GetFieldByName("id") == "404" {
+				w.Header().Set("Grpc-Status", "5")
+				w.Header().Set("Grpc-Message", "user%20not%20found")
+				w.Header().Set(statusDetailsHeader, base64.StdEncoding.EncodeToString(notFoundDetails))
+				return
+			}
+
+			resp.SetFieldByName("id", req.GetFieldByName("id"))
+			payload, _ := resp.Marshal()
+			frames := [][]byte{frame(0, payload), frame(trailerFrameFlag, []byte("grpc-status: 0\r\ngrpc-message: \r\n"))}
+			for _, f := range frames {
+				if text {
+					// Every frame is encoded separately, like in streaming responses.
+					f = []byte(base64.StdEncoding.EncodeToString(f))
+				}
+				w.Write(f)
+			}
+		case connectJSONContentType:
+			require.NoError(t, req.UnmarshalJSON(body))
+			w.Header().Set("Content-Type", contentType)
+			if req.GetFieldByName("id") == "404" {
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprintf(w, `{"code": "not_found", "message": "user not found", "details": [{"type": %q, "value": %q}]}`,
+					"google.rpc.ErrorInfo", base64.RawStdEncoding.EncodeToString(errorInfo))
+				return
+			}
+			resp.SetFieldByName("id", req.GetFieldByName("id"))
+			out, _ := resp.MarshalJSONPB(&jsonpb.Marshaler{})
+			w.Write(out)
+		case connectProtoContentType:
+			require.NoError(t, req.Unmarshal(body))
+			if req.GetFieldByName("id") == "404" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			resp.SetFieldByName("id", req.GetFieldByName("id"))
+			out, _ := resp.Marshal()
+			w.Write(out)
+		default:
+			w.WriteHeader(http.StatusUnsupportedMediaType)
+		}
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(handler))
+	t.Cleanup(srv.Close)
+	return strings.TrimPrefix(srv.URL, "http://")
+}
+
+// frame make gRPC-Web frame.
+func frame(flags byte, payload []byte) []byte {
+	f := make([]byte, frameHeaderLen, frameHeaderLen+len(payload))
+	f[0] = flags
+	binary.BigEndian.PutUint32(f[1:], uint32(len(payload)))
+	return append(f, payload...)
+}
+
+func TestHTTPRequester_SendUnaryRPCRequest(t *testing.T) {
+	host := newTestWebServer(t)
+	transports := map[string]entity.Transport{
+		"gRPC-Web":       entity.TransportGRPCWeb,
+		"gRPC-Web text":  entity.TransportGRPCWebText,
+		"Connect JSON":   entity.TransportConnectJSON,
+		"Connect binary": entity.TransportConnectProto,
+	}
+	wantNotFound := map[entity.Transport]codes.Code{
+		entity.TransportGRPCWeb:      codes.NotFound,
+		entity.TransportGRPCWebText:  codes.NotFound,
+		entity.TransportConnectJSON:  codes.NotFound,
+		entity.TransportConnectProto: codes.Unimplemented,
+	}
+
+	for name, transport := range transports {
+		t.Run(name, func(t *testing.T) {
+			ctx := metadata.NewOutgoingContext(context.Background(), metadata.Pairs("x-test", "value"))
+			m := metrics.InitMetrics()
+			req := newTestRequest(t, host)
+			req.Transport = transport
+			r, err := newRequester(req, m)
+			require.NoError(t, err)
+			defer r.Close()
+
+			assert.NoError(t, r.SendUnaryRPCRequest(ctx))
+			assert.Equal(t, int64(1), m.Statuses.Get(codes.OK).Value.Load())
+			p := m.Payload.Snapshot()
+			assert.Positive(t, p.Sent)
+			assert.Equal(t, p.Sent, p.Received, "response has the same id as request")
+			assert.GreaterOrEqual(t, p.WireSent, p.Sent)
+
+			req.Message = `{"id": "404"}`
+			err = r.SendUnaryRPCRequest(ctx)
+			assert.Equal(t, wantNotFound[transport], status.Code(err), err)
+			if transport != entity.TransportConnectProto {
+				assert.Equal(t, "user not found", status.Convert(err).Message())
+				details := status.Convert(err).Details()
+				require.Len(t, details, 1)
+				assert.Equal(t, "USER_NOT_FOUND", details[0].(*errdetails.ErrorInfo).GetReason())
+			}
+
+			backends := m.Backends.Snapshot()
+			require.Len(t, backends, 1)
+			assert.Equal(t, int64(2), backends[0].Requests)
+			assert.Equal(t, int64(1), backends[0].Errors)
+
+			latency := m.Latency.ByCode()
+			assert.Equal(t, int64(2), m.Latency.Snapshot().Count)
+			assert.Equal(t, int64(1), latency[codes.OK].Count)
+			assert.Equal(t, int64(1), latency[wantNotFound[transport]].Count)
+			assert.Equal(t, int64(1), m.StatusCounts()[wantNotFound[transport]])
+
+			// Nothing listens on port 1, so request fails in transport.
+			r.(*HTTPRequester).url.Host = "127.0.0.1:1"
+			err = r.SendUnaryRPCRequest(ctx)
+			assert.Equal(t, codes.Unavailable, status.Code(err))
+			assert.Equal(t, int64(2), m.Payload.Snapshot().SentSizes.Count, "failed send is not counted")
+			assert.Equal(t, map[metrics.Outcome]int64{metrics.OutcomeStatus: 2, metrics.OutcomeTransport: 1,
+				metrics.OutcomeMarshal: 0, metrics.OutcomeDeadline: 0, metrics.OutcomeAssertion: 0}, m.Outcomes.Snapshot())
+			assert.Equal(t, int64(3), m.RequestCounter.Value.Load())
+		})
+	}
+}
+
+func TestDecodeGRPCWebText(t *testing.T) {
+	data := base64.StdEncoding.EncodeToString([]byte("a")) + base64.StdEncoding.EncodeToString([]byte("bcd")) +
+		base64.StdEncoding.EncodeToString([]byte("ef"))
+	decoded, err := decodeGRPCWebText([]byte(data))
+	require.NoError(t, err)
+	assert.Equal(t, "abcdef", string(decoded))
+}
+
+func TestNewResponseMetadata(t *testing.T) {
+	h := http.Header{}
+	h.Set("Content-Type", "application/json")
+	h.Set("Grpc-Metadata-X-Request-Id", "1")
+	h.Set("Grpc-Trailer-X-Checksum-Bin", base64.StdEncoding.EncodeToString([]byte{1, 2}))
+	h.Set("Grpc-Status", "0")
+
+	header, trailer := newResponseMetadata(h, restMetadataPrefix, restTrailerPrefix)
+	assert.Equal(t, metadata.Pairs("x-request-id", "1"), header)
+	assert.Equal(t, metadata.Pairs("x-checksum-bin", "\x01\x02"), trailer)
+
+	header, trailer = newResponseMetadata(h, "", "")
+	assert.Len(t, header, 2, "transport headers are not metadata")
+	assert.Empty(t, trailer)
+}
+
+func TestHTTPRequester_CompressedResponse(t *testing.T) {
+	id := strings.Repeat("a", 1000)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, gzipEncoding, r.Header.Get("Accept-Encoding"))
+		w.Header().Set("Content-Type", connectJSONContentType)
+		w.Header().Set("Content-Encoding", gzipEncoding)
+		zw := gzip.NewWriter(w)
+		fmt.Fprintf(zw, `{"id": %q}`, id)
+		zw.Close()
+	}))
+	t.Cleanup(srv.Close)
+
+	m := metrics.InitMetrics()
+	req := newTestRequest(t, strings.TrimPrefix(srv.URL, "http://"))
+	req.Transport = entity.TransportConnectJSON
+	r, err := newRequester(req, m)
+	require.NoError(t, err)
+	defer r.Close()
+
+	require.NoError(t, r.SendUnaryRPCRequest(context.Background()))
+	p := m.Payload.Snapshot()
+	assert.Greater(t, p.Received, int64(len(id)))
+	assert.Positive(t, p.WireReceived)
+	assert.Less(t, p.WireReceived, p.Received, "wire size is size of compressed body")
+
+	t.Run("Test too large decompressed response", func(t *testing.T) {
+		id = strings.Repeat("a", maxTrailersLen+10)
+		req.Dial.MaxRecvMsgSize = 1
+		r, err := newRequester(req, metrics.InitMetrics())
+		require.NoError(t, err)
+		defer r.Close()
+
+		err = r.SendUnaryRPCRequest(context.Background())
+		assert.Equal(t, codes.ResourceExhausted, status.Code(err), err)
+	})
+}
@@ -0,0 +1,98 @@
+package templates
+
+import (
+	"math/rand"
+	"regexp/syntax"
+	"strings"
+	"time"
+)
+
+const (
+	// maxRepeat limit of repetitions for unbounded quantifiers like * and +.
+	maxRepeat = 10
+	// printableMin and printableMax range of printable ASCII characters, they are preferred for char classes.
+	printableMin = 0x20
+	printableMax = 0x7E
+)
+
+// GeneratePattern return random string matching regular expression pattern.
+func GeneratePattern(pattern string) (string, error) {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return "", err
+	}
+
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	var sb strings.Builder
+	generate(&sb, re.Simplify(), r)
+	return sb.String(), nil
+}
+
+// generate write random string matching re to sb.
+//
+// Recursion.
+func generate(sb *strings.Builder, re *syntax.Regexp, r *rand.Rand) {
+	switch re.Op {
+	case syntax.OpLiteral:
+		sb.WriteString(string(re.Rune))
+	case syntax.OpCharClass:
+		sb.WriteRune(randomRune(re.Rune, r))
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		sb.WriteRune(rune('a' + r.Intn(26)))
+	case syntax.OpCapture:
+		// Calls itself.
+		generate(sb, re.Sub[0], r)
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			// Calls itself.
+			generate(sb, sub, r)
+		}
+	case syntax.OpAlternate:
+		// Calls itself.
+		generate(sb, re.Sub[r.Intn(len(re.Sub))], r)
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		lo, hi := repeatRange(re)
+		n := lo + r.Intn(hi-lo+1)
+		for i := 0; i < n; i++ {
+			// Calls itself.
+			generate(sb, re.Sub[0], r)
+		}
+	}
+}
+
+// repeatRange return range of repetitions for quantifier.
+func repeatRange(re *syntax.Regexp) (int, int) {
+	switch re.Op {
+	case syntax.OpStar:
+		return 0, maxRepeat
+	case syntax.OpPlus:
+		return 1, maxRepeat
+	case syntax.OpQuest:
+		return 0, 1
+	default:
+		if re.Max == -1 {
+			return re.Min, re.Min + maxRepeat
+		}
+		return re.Min, re.Max
+	}
+}
+
+// randomRune return random rune from char class ranges, printable ASCII characters are preferred.
+func randomRune(ranges []rune, r *rand.Rand) rune {
+	var printable []rune
+	for i := 0; i < len(ranges); i += 2 {
+		lo, hi := max(ranges[i], printableMin), min(ranges[i+1], printableMax)
+		if lo <= hi {
+			printable = append(printable, lo, hi)
+		}
+	}
+	if len(printable) != 0 {
+		ranges = printable
+	}
+	if len(ranges) == 0 {
+		return 'a'
+	}
+
+	i := r.Intn(len(ranges)/2) * 2
+	return ranges[i] + rune(r.Intn(int(ranges[i+1]-ranges[i])+1))
+}
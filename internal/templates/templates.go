@@ -2,15 +2,26 @@ package templates
 
 import (
 	"bytes"
+	"encoding/json"
+	"fmt"
 	"math/rand"
 	"text/template"
 	"time"
 )
 
 const (
-	randNumFunc = "randNum"
+	randNumFunc     = "randNum"
+	randFloatFunc   = "randFloat"
+	randStringFunc  = "randString"
+	randPatternFunc = "randPattern"
+	randChoiceFunc  = "randChoice"
+	randUUIDFunc    = "randUUID"
+	toJSONFunc      = "toJSON"
 )
 
+// alphanumeric characters for random strings.
+const alphanumeric = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
 // TemplateBuilder builder go templates.
 type TemplateBuilder struct {
 	funcMap template.FuncMap
@@ -20,7 +31,13 @@ type TemplateBuilder struct {
 func NewTemplateBuilder() *TemplateBuilder {
 	tb := &TemplateBuilder{}
 	funcMap := template.FuncMap{
-		randNumFunc: tb.randNum,
+		randNumFunc:     tb.randNum,
+		randFloatFunc:   tb.randFloat,
+		randStringFunc:  tb.randString,
+		randPatternFunc: GeneratePattern,
+		randChoiceFunc:  tb.randChoice,
+		randUUIDFunc:    tb.randUUID,
+		toJSONFunc:      tb.toJSON,
 	}
 	tb.funcMap = funcMap
 	return tb
@@ -49,3 +66,50 @@ func (b *TemplateBuilder) randNum(min, max int) int {
 	r := rand.New(rand.NewSource(time.Now().UnixNano()))
 	return min + r.Intn(max-min+1)
 }
+
+// randFloat return random float with specified range.
+func (b *TemplateBuilder) randFloat(min, max float64) float64 {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	return min + r.Float64()*(max-min)
+}
+
+// randString return random alphanumeric string with length in specified range.
+func (b *TemplateBuilder) randString(minLen, maxLen int) string {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	str := make([]byte, minLen+r.Intn(maxLen-minLen+1))
+	for i := range str {
+		str[i] = alphanumeric[r.Intn(len(alphanumeric))]
+	}
+
+	return string(str)
+}
+
+// randChoice return random value from values.
+func (b *TemplateBuilder) randChoice(values ...any) (any, error) {
+	if len(values) == 0 {
+		return nil, fmt.Errorf("%s requires at least one value", randChoiceFunc)
+	}
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	return values[r.Intn(len(values))], nil
+}
+
+// randUUID return random UUID version 4.
+func (b *TemplateBuilder) randUUID() string {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	var u [16]byte
+	r.Read(u[:])
+	u[6] = (u[6] & 0x0f) | 0x40
+	u[8] = (u[8] & 0x3f) | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", u[0:4], u[4:6], u[6:8], u[8:10], u[10:])
+}
+
+// toJSON return value encoded as JSON, e.g. string is quoted and escaped.
+func (b *TemplateBuilder) toJSON(value any) (string, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
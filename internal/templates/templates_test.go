@@ -1,6 +1,7 @@
 package templates
 
 import (
+	"regexp"
 	"strconv"
 	"testing"
 
@@ -19,4 +20,46 @@ func TestTemplatesBuilder_Process(t *testing.T) {
 		assert.GreaterOrEqual(t, num, 50)
 		assert.LessOrEqual(t, num, 100)
 	})
+
+	t.Run("Test randString", func(t *testing.T) {
+		tb := NewTemplateBuilder()
+		str, err := tb.Process(`{{randString 3 5}}`)
+		require.NoError(t, err)
+
+		assert.GreaterOrEqual(t, len(str), 3)
+		assert.LessOrEqual(t, len(str), 5)
+	})
+
+	t.Run("Test randChoice", func(t *testing.T) {
+		tb := NewTemplateBuilder()
+		str, err := tb.Process("{{randChoice `A` `B`}}")
+		require.NoError(t, err)
+
+		assert.Contains(t, []string{"A", "B"}, str)
+	})
+
+	t.Run("Test randPattern", func(t *testing.T) {
+		tb := NewTemplateBuilder()
+		pattern := `^[A-Z]{3}-\d{2,4}(_x|_y)?$`
+		str, err := tb.Process("{{randPattern `" + pattern + "`}}")
+		require.NoError(t, err)
+
+		assert.Regexp(t, regexp.MustCompile(pattern), str)
+	})
+
+	t.Run("Test toJSON", func(t *testing.T) {
+		tb := NewTemplateBuilder()
+		str, err := tb.Process(`{{print "a\"b" "\\" | toJSON}}`)
+		require.NoError(t, err)
+
+		assert.Equal(t, `"a\"b\\"`, str)
+	})
+
+	t.Run("Test randUUID", func(t *testing.T) {
+		tb := NewTemplateBuilder()
+		str, err := tb.Process(`{{randUUID}}`)
+		require.NoError(t, err)
+
+		assert.Regexp(t, regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`), str)
+	})
 }